@@ -9,15 +9,17 @@ import (
 
 // AttendanceChecker handles attendance checking logic
 type AttendanceChecker struct {
-	db *db.DB
+	db     *db.DB
+	loc    *time.Location // guild timezone in which weeks start and end
+	policy AttendancePolicy
 }
 
-// NewAttendanceChecker creates a new attendance checker
-func NewAttendanceChecker(database *db.DB) *AttendanceChecker {
-	return &AttendanceChecker{db: database}
+// NewAttendanceChecker creates a new attendance checker using a guild's timezone and attendance policy
+func NewAttendanceChecker(database *db.DB, cfg *GuildConfig) *AttendanceChecker {
+	return &AttendanceChecker{db: database, loc: cfg.Location(), policy: cfg.AttendancePolicy()}
 }
 
-// WeekPeriod represents a week starting on Sunday
+// WeekPeriod represents a week, starting on the guild's configured week start day
 type WeekPeriod struct {
 	StartDate time.Time
 	EndDate   time.Time
@@ -30,58 +32,80 @@ func (w WeekPeriod) String() string {
 
 // GetWeekStart returns the start of the week (Sunday) for a given date
 func GetWeekStart(date time.Time) time.Time {
-	// Get the day of week (0 = Sunday, 1 = Monday, etc.)
-	weekday := int(date.Weekday())
-	
-	// Calculate days to subtract to get to Sunday
-	daysToSubtract := weekday
-	
-	// Subtract to get to the start of the week (Sunday)
+	return GetWeekStartOn(date, time.Sunday)
+}
+
+// GetWeekStartOn returns the start of the week for a given date, for weeks starting on startDay
+func GetWeekStartOn(date time.Time, startDay time.Weekday) time.Time {
+	// Calculate days to subtract to get to the start day
+	daysToSubtract := (int(date.Weekday()) - int(startDay) + 7) % 7
+
+	// Subtract to get to the start of the week
 	weekStart := date.AddDate(0, 0, -daysToSubtract)
-	
+
 	// Zero out the time component
 	return time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day(), 0, 0, 0, 0, weekStart.Location())
 }
 
-// GetWeekEnd returns the end of the week (Saturday) for a given date
+// GetWeekEnd returns the last second of the week that starts on weekStart
 func GetWeekEnd(weekStart time.Time) time.Time {
-	// Add 6 days to Sunday to get Saturday
+	// Add 6 days to the start day to get the last day
 	weekEnd := weekStart.AddDate(0, 0, 6)
 	return time.Date(weekEnd.Year(), weekEnd.Month(), weekEnd.Day(), 23, 59, 59, 0, weekEnd.Location())
 }
 
-// GetWeekPeriod returns the week period for a given date
+// GetWeekPeriod returns the Sunday to Saturday week period for a given date
 func GetWeekPeriod(date time.Time) WeekPeriod {
-	start := GetWeekStart(date)
+	return GetWeekPeriodOn(date, time.Sunday)
+}
+
+// GetWeekPeriodOn returns the week period for a given date, for weeks starting on startDay
+func GetWeekPeriodOn(date time.Time, startDay time.Weekday) WeekPeriod {
+	start := GetWeekStartOn(date, startDay)
 	end := GetWeekEnd(start)
 	return WeekPeriod{StartDate: start, EndDate: end}
 }
 
-// GetWeekPeriodsBack returns a list of week periods going back N weeks from today
-func GetWeekPeriodsBack(weeksBack int) []WeekPeriod {
+// GetWeekPeriodsBack returns a list of week periods going back N weeks from now.
+// Weeks are computed in the location of now, which should be the guild's timezone.
+func GetWeekPeriodsBack(now time.Time, weeksBack int, startDay time.Weekday) []WeekPeriod {
 	weeks := make([]WeekPeriod, 0, weeksBack)
-	// Use Eastern time zone for consistency with guild war schedules
-	est := GetEasternLocation()
-	now := time.Now().In(est)
-	
+
 	for i := 0; i < weeksBack; i++ {
 		// Calculate the date for this week
 		weekDate := now.AddDate(0, 0, -7*i)
-		week := GetWeekPeriod(weekDate)
+		week := GetWeekPeriodOn(weekDate, startDay)
 		weeks = append(weeks, week)
 	}
-	
+
 	return weeks
 }
 
+// Days returns the calendar days of the week, at midnight
+func (w WeekPeriod) Days() []time.Time {
+	days := make([]time.Time, 0, 7)
+	for date := w.StartDate; !date.After(w.EndDate); date = date.AddDate(0, 0, 1) {
+		days = append(days, date)
+	}
+	return days
+}
+
+// MissedWeek is a week in which a member attended fewer war nights than required
+type MissedWeek struct {
+	WeekPeriod
+	Attended int // war nights the member attended
+	Required int // war nights the policy required
+}
+
 // MemberAttendance represents attendance information for a member
 type MemberAttendance struct {
 	MemberID      int64
 	FamilyName    string
 	CreatedAt     time.Time
-	MissedWeeks   []WeekPeriod
+	MissedWeeks   []MissedWeek
 	TotalWeeks    int
 	AttendedWeeks int
+	ExcusedWeeks  int // weeks in which vacations covered every war night
 }
 
 // HasAttendanceIssue returns true if the member has missed any weeks
@@ -89,8 +113,103 @@ func (ma MemberAttendance) HasAttendanceIssue() bool {
 	return len(ma.MissedWeeks) > 0
 }
 
+// Policy returns the attendance policy members are checked against
+func (ac *AttendanceChecker) Policy() AttendancePolicy {
+	return ac.policy
+}
+
+// dateSet builds a set of calendar dates for quick lookup
+func dateSet(dates []time.Time) map[string]bool {
+	set := make(map[string]bool, len(dates))
+	for _, date := range dates {
+		set[date.Format("02-01-06")] = true
+	}
+	return set
+}
+
+// weeksToCheck returns the weeks to check and the dates the guild fought a war during them
+func (ac *AttendanceChecker) weeksToCheck(guildID string, weeksBack int) ([]WeekPeriod, map[string]bool, error) {
+	weeks := GetWeekPeriodsBack(time.Now().In(ac.loc), weeksBack, ac.policy.WeekStart)
+	if len(weeks) == 0 {
+		return weeks, map[string]bool{}, nil
+	}
+
+	guildWarDates, err := db.GetGuildWarDates(ac.db, guildID, weeks[len(weeks)-1].StartDate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get guild war dates: %w", err)
+	}
+
+	return weeks, dateSet(guildWarDates), nil
+}
+
+// weekAttendance is a member's attendance during one week
+type weekAttendance struct {
+	nights    int // war nights the guild fought
+	available int // war nights not covered by the member's vacations
+	attended  int // war nights the member attended
+	required  int // war nights the policy required
+}
+
+// evaluateWeek counts the war nights of a week the member attended and how many the policy requires.
+// War nights are the days the guild fought a war on one of the policy's war days. Nights covered by
+// a vacation are excused, so the requirement only applies to the nights the member was available.
+func evaluateWeek(policy AttendancePolicy, week WeekPeriod, guildWarDates, memberWarDates, vacationDates map[string]bool) weekAttendance {
+	var result weekAttendance
+	for _, day := range week.Days() {
+		key := day.Format("02-01-06")
+		if !guildWarDates[key] || !policy.IsWarDay(day.Weekday()) {
+			continue
+		}
+		result.nights++
+		if !vacationDates[key] {
+			result.available++
+		}
+		if memberWarDates[key] {
+			result.attended++
+		}
+	}
+
+	result.required = policy.Required(result.available)
+	return result
+}
+
+// vacationDateSet builds the set of calendar days between from and to covered by any of the vacations.
+// Overlapping and back-to-back vacations are merged by the set.
+func vacationDateSet(vacations []db.MemberVacation, from, to time.Time) map[string]bool {
+	loc := from.Location()
+	first := CalendarDate(from, loc)
+	last := CalendarDate(to, loc)
+
+	set := make(map[string]bool)
+	for _, vacation := range vacations {
+		// Vacation dates are calendar days, so compare them with the days being checked
+		start := CalendarDate(vacation.StartDate, loc)
+		end := CalendarDate(vacation.EndDate, loc)
+		if start.Before(first) {
+			start = first
+		}
+		if end.After(last) {
+			end = last
+		}
+		for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+			set[date.Format("02-01-06")] = true
+		}
+	}
+	return set
+}
+
 // CheckMemberAttendance checks attendance for a specific member
 func (ac *AttendanceChecker) CheckMemberAttendance(guildID string, memberID int64, weeksBack int) (*MemberAttendance, error) {
+	weeks, guildWarDates, err := ac.weeksToCheck(guildID, weeksBack)
+	if err != nil {
+		return nil, err
+	}
+
+	return ac.checkMember(guildID, memberID, weeks, guildWarDates)
+}
+
+// checkMember evaluates a member's attendance over the given weeks
+func (ac *AttendanceChecker) checkMember(guildID string, memberID int64, weeks []WeekPeriod, guildWarDates map[string]bool) (*MemberAttendance, error) {
 	// Get member information
 	member, err := db.GetMemberByID(ac.db, memberID, guildID)
 	if err != nil {
@@ -108,17 +227,16 @@ func (ac *AttendanceChecker) CheckMemberAttendance(guildID string, memberID int6
 	if err != nil {
 		return nil, fmt.Errorf("failed to get war dates: %w", err)
 	}
+	memberWarDates := dateSet(warDates)
 
-	// Build set of war dates for quick lookup
-	warDateSet := make(map[string]bool)
-	for _, date := range warDates {
-		warDateSet[date.Format("02-01-06")] = true
-	}
-
-	// Calculate which weeks to check
-	weeks := GetWeekPeriodsBack(weeksBack)
-	missedWeeks := []WeekPeriod{}
+	missedWeeks := []MissedWeek{}
 	totalWeeks := 0
+	excusedWeeks := 0
+
+	var vacationDates map[string]bool
+	if len(weeks) > 0 {
+		vacationDates = vacationDateSet(vacations, weeks[len(weeks)-1].StartDate, weeks[0].EndDate)
+	}
 
 	for _, week := range weeks {
 		// Skip weeks before member was created
@@ -128,22 +246,14 @@ func (ac *AttendanceChecker) CheckMemberAttendance(guildID string, memberID int6
 
 		totalWeeks++
 
-		// Check if member is on vacation for the entire week
-		if ac.isOnVacationForEntireWeek(week, vacations) {
-			continue // Week is excused
-		}
-
-		// Check if member participated in any war during this week
-		participated := false
-		for date := week.StartDate; !date.After(week.EndDate); date = date.AddDate(0, 0, 1) {
-			if warDateSet[date.Format("02-01-06")] {
-				participated = true
-				break
-			}
+		// Check if member attended enough of the war nights they were available for
+		result := evaluateWeek(ac.policy, week, guildWarDates, memberWarDates, vacationDates)
+		if result.nights > 0 && result.available == 0 {
+			excusedWeeks++ // Vacation covers every war night of the week
+			continue
 		}
-
-		if !participated {
-			missedWeeks = append(missedWeeks, week)
+		if result.attended < result.required {
+			missedWeeks = append(missedWeeks, MissedWeek{WeekPeriod: week, Attended: result.attended, Required: result.required})
 		}
 	}
 
@@ -153,7 +263,8 @@ func (ac *AttendanceChecker) CheckMemberAttendance(guildID string, memberID int6
 		CreatedAt:     member.CreatedAt,
 		MissedWeeks:   missedWeeks,
 		TotalWeeks:    totalWeeks,
-		AttendedWeeks: totalWeeks - len(missedWeeks),
+		AttendedWeeks: totalWeeks - len(missedWeeks) - excusedWeeks,
+		ExcusedWeeks:  excusedWeeks,
 	}, nil
 }
 
@@ -165,10 +276,15 @@ func (ac *AttendanceChecker) CheckAllMembersAttendance(guildID string, weeksBack
 		return nil, fmt.Errorf("failed to get members: %w", err)
 	}
 
+	weeks, guildWarDates, err := ac.weeksToCheck(guildID, weeksBack)
+	if err != nil {
+		return nil, err
+	}
+
 	results := make([]MemberAttendance, 0)
 
 	for _, member := range members {
-		attendance, err := ac.CheckMemberAttendance(guildID, member.ID, weeksBack)
+		attendance, err := ac.checkMember(guildID, member.ID, weeks, guildWarDates)
 		if err != nil {
 			// Log error with member details for debugging
 			fmt.Printf("Warning: failed to check attendance for member %d (%s): %v\n", member.ID, member.FamilyName, err)
@@ -180,16 +296,3 @@ func (ac *AttendanceChecker) CheckAllMembersAttendance(guildID string, weeksBack
 
 	return results, nil
 }
-
-// isOnVacationForEntireWeek checks if a member is on vacation for the entire week
-func (ac *AttendanceChecker) isOnVacationForEntireWeek(week WeekPeriod, vacations []db.MemberVacation) bool {
-	for _, vacation := range vacations {
-		// Check if vacation covers the entire week
-		// Vacation must start on or before the week start
-		// and end on or after the week end
-		if !vacation.StartDate.After(week.StartDate) && !vacation.EndDate.Before(week.EndDate) {
-			return true
-		}
-	}
-	return false
-}
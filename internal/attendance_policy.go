@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AttendancePolicy describes what a guild requires from its members each week
+type AttendancePolicy struct {
+	WeekStart  time.Weekday
+	WarDays    []time.Weekday // scheduled war nights; empty means every day the guild fought
+	MinWars    int            // war nights required per week when MinPercent is zero
+	MinPercent int            // percentage of the week's war nights required, zero to use MinWars
+}
+
+// DefaultAttendancePolicy requires one war per Sunday to Saturday week
+func DefaultAttendancePolicy() AttendancePolicy {
+	return AttendancePolicy{WeekStart: time.Sunday, MinWars: 1}
+}
+
+// weekdayNames maps the accepted spellings of each weekday, in lowercase
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ParseWarDays parses a list of weekdays such as "tue, thu, sun".
+// "any" or an empty list means every day the guild fought counts as a war night.
+func ParseWarDays(value string) ([]time.Weekday, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" || value == "any" {
+		return nil, nil
+	}
+
+	var days []time.Weekday
+	seen := make(map[time.Weekday]bool)
+	for _, name := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '/' }) {
+		day, ok := weekdayNames[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown day '%s'", name)
+		}
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+
+	SortWeekdays(days, time.Sunday)
+	return days, nil
+}
+
+// SortWeekdays orders weekdays as they occur in a week starting on weekStart
+func SortWeekdays(days []time.Weekday, weekStart time.Weekday) {
+	offset := func(day time.Weekday) int { return (int(day) - int(weekStart) + 7) % 7 }
+	for i := 1; i < len(days); i++ {
+		for j := i; j > 0 && offset(days[j]) < offset(days[j-1]); j-- {
+			days[j], days[j-1] = days[j-1], days[j]
+		}
+	}
+}
+
+// WarDaysMask converts war days to their config column bitmask, bit 0 being Sunday
+func WarDaysMask(days []time.Weekday) int {
+	mask := 0
+	for _, day := range days {
+		mask |= 1 << uint(day)
+	}
+	return mask
+}
+
+// WarDaysFromMask converts a config column bitmask to war days
+func WarDaysFromMask(mask int) []time.Weekday {
+	var days []time.Weekday
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if mask&(1<<uint(day)) != 0 {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// IsWarDay reports whether wars fought on a weekday count toward attendance
+func (p AttendancePolicy) IsWarDay(day time.Weekday) bool {
+	if len(p.WarDays) == 0 {
+		return true
+	}
+	for _, warDay := range p.WarDays {
+		if warDay == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Required returns how many of a week's war nights a member must attend.
+// Nights without an imported war cannot be attended, so the requirement never exceeds them.
+func (p AttendancePolicy) Required(nights int) int {
+	if p.MinPercent > 0 {
+		return (nights*p.MinPercent + 99) / 100
+	}
+	if p.MinWars > nights {
+		return nights
+	}
+	return p.MinWars
+}
+
+// String describes the policy for attendance reports
+func (p AttendancePolicy) String() string {
+	days := make([]time.Weekday, len(p.WarDays))
+	copy(days, p.WarDays)
+	SortWeekdays(days, p.WeekStart)
+
+	nights := "any day"
+	if len(days) > 0 {
+		names := make([]string, len(days))
+		for idx, day := range days {
+			names[idx] = day.String()[:3]
+		}
+		nights = strings.Join(names, ", ")
+	}
+
+	required := fmt.Sprintf("%d war night(s)", p.MinWars)
+	if p.MinPercent > 0 {
+		required = fmt.Sprintf("%d%% of war nights", p.MinPercent)
+	}
+
+	return fmt.Sprintf("weeks start %s, war nights: %s, required: %s per week", p.WeekStart, nights, required)
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseWarDays(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []time.Weekday
+		valid    bool
+	}{
+		{"tue, thu, sun", []time.Weekday{time.Sunday, time.Tuesday, time.Thursday}, true},
+		{"Saturday Sunday", []time.Weekday{time.Sunday, time.Saturday}, true},
+		{"mon/wed/mon", []time.Weekday{time.Monday, time.Wednesday}, true},
+		{"any", nil, true},
+		{"", nil, true},
+		{"tue, funday", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			days, err := ParseWarDays(tt.input)
+			if (err == nil) != tt.valid {
+				t.Fatalf("ParseWarDays(%q) error = %v, valid %v", tt.input, err, tt.valid)
+			}
+			if !reflect.DeepEqual(days, tt.expected) {
+				t.Errorf("ParseWarDays(%q) = %v, want %v", tt.input, days, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWarDaysMask(t *testing.T) {
+	days := []time.Weekday{time.Sunday, time.Tuesday, time.Thursday}
+
+	mask := WarDaysMask(days)
+	if mask != 0b0010101 {
+		t.Errorf("WarDaysMask(%v) = %b, want 10101", days, mask)
+	}
+	if got := WarDaysFromMask(mask); !reflect.DeepEqual(got, days) {
+		t.Errorf("WarDaysFromMask(%b) = %v, want %v", mask, got, days)
+	}
+	if got := WarDaysFromMask(0); got != nil {
+		t.Errorf("WarDaysFromMask(0) = %v, want nil", got)
+	}
+}
+
+func TestAttendancePolicyRequired(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   AttendancePolicy
+		nights   int
+		expected int
+	}{
+		{"min wars", AttendancePolicy{MinWars: 2}, 3, 2},
+		{"min wars capped at nights", AttendancePolicy{MinWars: 2}, 1, 1},
+		{"no nights", AttendancePolicy{MinWars: 1}, 0, 0},
+		{"percentage", AttendancePolicy{MinWars: 1, MinPercent: 66}, 3, 2},
+		{"full percentage", AttendancePolicy{MinPercent: 100}, 3, 3},
+		{"percentage without nights", AttendancePolicy{MinPercent: 50}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Required(tt.nights); got != tt.expected {
+				t.Errorf("Required(%d) = %d, want %d", tt.nights, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAttendancePolicyString(t *testing.T) {
+	policy := AttendancePolicy{
+		WeekStart: time.Monday,
+		WarDays:   []time.Weekday{time.Sunday, time.Tuesday, time.Thursday},
+		MinWars:   2,
+	}
+
+	expected := "weeks start Monday, war nights: Tue, Thu, Sun, required: 2 war night(s) per week"
+	if got := policy.String(); got != expected {
+		t.Errorf("String() = %q, want %q", got, expected)
+	}
+
+	expected = "weeks start Sunday, war nights: any day, required: 1 war night(s) per week"
+	if got := DefaultAttendancePolicy().String(); got != expected {
+		t.Errorf("DefaultAttendancePolicy().String() = %q, want %q", got, expected)
+	}
+}
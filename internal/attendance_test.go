@@ -3,6 +3,8 @@ package internal
 import (
 	"testing"
 	"time"
+
+	"PanickedBot/internal/db"
 )
 
 func TestGetWeekStart(t *testing.T) {
@@ -128,18 +130,18 @@ func TestMemberAttendanceHasAttendanceIssue(t *testing.T) {
 		{
 			name: "No missed weeks",
 			attendance: MemberAttendance{
-				MissedWeeks: []WeekPeriod{},
+				MissedWeeks: []MissedWeek{},
 			},
 			expected: false,
 		},
 		{
 			name: "One missed week",
 			attendance: MemberAttendance{
-				MissedWeeks: []WeekPeriod{
-					{
+				MissedWeeks: []MissedWeek{
+					{WeekPeriod: WeekPeriod{
 						StartDate: time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC),
 						EndDate:   time.Date(2026, 1, 17, 23, 59, 59, 0, time.UTC),
-					},
+					}},
 				},
 			},
 			expected: true,
@@ -147,15 +149,15 @@ func TestMemberAttendanceHasAttendanceIssue(t *testing.T) {
 		{
 			name: "Multiple missed weeks",
 			attendance: MemberAttendance{
-				MissedWeeks: []WeekPeriod{
-					{
+				MissedWeeks: []MissedWeek{
+					{WeekPeriod: WeekPeriod{
 						StartDate: time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC),
 						EndDate:   time.Date(2026, 1, 17, 23, 59, 59, 0, time.UTC),
-					},
-					{
+					}},
+					{WeekPeriod: WeekPeriod{
 						StartDate: time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC),
 						EndDate:   time.Date(2026, 1, 24, 23, 59, 59, 0, time.UTC),
-					},
+					}},
 				},
 			},
 			expected: true,
@@ -171,3 +173,204 @@ func TestMemberAttendanceHasAttendanceIssue(t *testing.T) {
 		})
 	}
 }
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("timezone database not available: %v", err)
+	}
+	return loc
+}
+
+func TestGetWeekPeriodAcrossDST(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+
+	tests := []struct {
+		name          string
+		date          time.Time
+		expectedStart time.Time
+		expectedEnd   time.Time
+	}{
+		{
+			// Clocks spring forward on Sunday Mar 8, 2026 at 02:00
+			name:          "New York spring forward",
+			date:          time.Date(2026, 3, 11, 20, 0, 0, 0, newYork),
+			expectedStart: time.Date(2026, 3, 8, 0, 0, 0, 0, newYork),
+			expectedEnd:   time.Date(2026, 3, 14, 23, 59, 59, 0, newYork),
+		},
+		{
+			// Clocks fall back on Sunday Nov 1, 2026 at 02:00
+			name:          "New York fall back",
+			date:          time.Date(2026, 11, 7, 23, 30, 0, 0, newYork),
+			expectedStart: time.Date(2026, 11, 1, 0, 0, 0, 0, newYork),
+			expectedEnd:   time.Date(2026, 11, 7, 23, 59, 59, 0, newYork),
+		},
+		{
+			// Clocks spring forward on Sunday Mar 29, 2026 at 02:00
+			name:          "Berlin spring forward",
+			date:          time.Date(2026, 3, 29, 0, 30, 0, 0, berlin),
+			expectedStart: time.Date(2026, 3, 29, 0, 0, 0, 0, berlin),
+			expectedEnd:   time.Date(2026, 4, 4, 23, 59, 59, 0, berlin),
+		},
+		{
+			// Clocks fall back on Sunday Oct 25, 2026 at 03:00
+			name:          "Berlin fall back",
+			date:          time.Date(2026, 10, 31, 22, 0, 0, 0, berlin),
+			expectedStart: time.Date(2026, 10, 25, 0, 0, 0, 0, berlin),
+			expectedEnd:   time.Date(2026, 10, 31, 23, 59, 59, 0, berlin),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := GetWeekPeriod(tt.date)
+			if !result.StartDate.Equal(tt.expectedStart) {
+				t.Errorf("GetWeekPeriod(%v).StartDate = %v, want %v", tt.date, result.StartDate, tt.expectedStart)
+			}
+			if !result.EndDate.Equal(tt.expectedEnd) {
+				t.Errorf("GetWeekPeriod(%v).EndDate = %v, want %v", tt.date, result.EndDate, tt.expectedEnd)
+			}
+		})
+	}
+}
+
+func TestGetWeekPeriodsBackAcrossDST(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+
+	// Early Sunday morning, one week after clocks sprang forward on Mar 29, 2026
+	now := time.Date(2026, 4, 5, 1, 30, 0, 0, berlin)
+	weeks := GetWeekPeriodsBack(now, 3, time.Sunday)
+
+	expected := []string{
+		"05-04-26 to 11-04-26",
+		"29-03-26 to 04-04-26",
+		"22-03-26 to 28-03-26",
+	}
+	if len(weeks) != len(expected) {
+		t.Fatalf("GetWeekPeriodsBack() returned %d weeks, want %d", len(weeks), len(expected))
+	}
+	for idx, week := range weeks {
+		if week.String() != expected[idx] {
+			t.Errorf("week %d = %q, want %q", idx, week.String(), expected[idx])
+		}
+		if week.StartDate.Hour() != 0 || week.StartDate.Location() != berlin {
+			t.Errorf("week %d starts at %v, want midnight in Europe/Berlin", idx, week.StartDate)
+		}
+	}
+}
+
+func TestVacationDateSet(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	// Vacation dates are read from DATE columns as midnight UTC
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	vacation := func(start, end int) db.MemberVacation {
+		return db.MemberVacation{StartDate: day(start), EndDate: day(end)}
+	}
+
+	tests := []struct {
+		name      string
+		loc       *time.Location
+		vacations []db.MemberVacation
+		expected  []int
+	}{
+		{"single day", berlin, []db.MemberVacation{vacation(10, 10)}, []int{10}},
+		{"Monday to Thursday", newYork, []db.MemberVacation{vacation(9, 12)}, []int{9, 10, 11, 12}},
+		{"back to back", berlin, []db.MemberVacation{vacation(9, 10), vacation(11, 12)}, []int{9, 10, 11, 12}},
+		{"overlapping", newYork, []db.MemberVacation{vacation(9, 11), vacation(10, 12)}, []int{9, 10, 11, 12}},
+		{"clipped to the checked days", berlin, []db.MemberVacation{vacation(1, 9), vacation(14, 30)}, []int{8, 9, 14}},
+		{"no vacation", berlin, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Sunday Mar 8 to Saturday Mar 14, 2026, the week clocks spring forward in New York
+			week := GetWeekPeriod(time.Date(2026, 3, 11, 12, 0, 0, 0, tt.loc))
+			set := vacationDateSet(tt.vacations, week.StartDate, week.EndDate)
+
+			if len(set) != len(tt.expected) {
+				t.Errorf("vacationDateSet() covers %d days, want %d", len(set), len(tt.expected))
+			}
+			for _, d := range tt.expected {
+				if !set[day(d).Format("02-01-06")] {
+					t.Errorf("vacationDateSet() does not cover %s", day(d).Format("02-01-06"))
+				}
+			}
+		})
+	}
+}
+
+func TestGetWeekStartOn(t *testing.T) {
+	// Wednesday Jan 14, 2026
+	date := time.Date(2026, 1, 14, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		startDay time.Weekday
+		expected time.Time
+	}{
+		{time.Sunday, time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)},
+		{time.Monday, time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC)},
+		{time.Wednesday, time.Date(2026, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{time.Thursday, time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)},
+		{time.Saturday, time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.startDay.String(), func(t *testing.T) {
+			result := GetWeekStartOn(date, tt.startDay)
+			if !result.Equal(tt.expected) {
+				t.Errorf("GetWeekStartOn(%v, %s) = %v, want %v", date, tt.startDay, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEvaluateWeek(t *testing.T) {
+	// Monday Jan 12 to Sunday Jan 18, 2026
+	week := GetWeekPeriodOn(time.Date(2026, 1, 14, 12, 0, 0, 0, time.UTC), time.Monday)
+	dates := func(days ...int) map[string]bool {
+		set := make(map[string]bool)
+		for _, day := range days {
+			set[time.Date(2026, 1, day, 0, 0, 0, 0, time.UTC).Format("02-01-06")] = true
+		}
+		return set
+	}
+	// Node wars on Tuesday, Thursday and Sunday, plus a Saturday scrim
+	guildWars := dates(13, 15, 17, 18)
+	twoOfThree := AttendancePolicy{WeekStart: time.Monday, WarDays: []time.Weekday{time.Tuesday, time.Thursday, time.Sunday}, MinWars: 2}
+
+	tests := []struct {
+		name             string
+		policy           AttendancePolicy
+		guildWars        map[string]bool
+		memberWars       map[string]bool
+		vacation         map[string]bool
+		expectedAttended int
+		expectedRequired int
+	}{
+		{"two of three nights", twoOfThree, guildWars, dates(13, 18), nil, 2, 2},
+		{"one of three nights", twoOfThree, guildWars, dates(15), nil, 1, 2},
+		{"unscheduled war does not count", twoOfThree, guildWars, dates(13, 17), nil, 1, 2},
+		{"cancelled night lowers the nights", twoOfThree, dates(13), dates(13), nil, 1, 1},
+		{"any day", DefaultAttendancePolicy(), guildWars, dates(17), nil, 1, 1},
+		{"any day without wars", DefaultAttendancePolicy(), dates(), dates(), nil, 0, 0},
+		{"percentage rounds up", AttendancePolicy{WeekStart: time.Monday, MinPercent: 50}, guildWars, dates(13), nil, 1, 2},
+		{"percentage of scheduled nights", AttendancePolicy{WeekStart: time.Monday, WarDays: twoOfThree.WarDays, MinPercent: 60}, guildWars, dates(13, 15), nil, 2, 2},
+		{"away Monday to Thursday", twoOfThree, guildWars, dates(18), dates(12, 13, 14, 15), 1, 1},
+		{"away Monday to Thursday skipping Sunday", twoOfThree, guildWars, dates(), dates(12, 13, 14, 15), 0, 1},
+		{"away the whole week", twoOfThree, guildWars, dates(), dates(12, 13, 14, 15, 16, 17, 18), 0, 0},
+		{"attended while on vacation", twoOfThree, guildWars, dates(13, 15), dates(13, 14, 15, 16, 17, 18), 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := evaluateWeek(tt.policy, week, tt.guildWars, tt.memberWars, tt.vacation)
+			if result.attended != tt.expectedAttended || result.required != tt.expectedRequired {
+				t.Errorf("evaluateWeek() = %d, %d, want %d, %d", result.attended, result.required, tt.expectedAttended, tt.expectedRequired)
+			}
+		})
+	}
+}
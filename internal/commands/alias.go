@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal"
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/discord"
+)
+
+// maxAliasLength matches the member_aliases.alias column
+const maxAliasLength = 128
+
+func aliasCommand() *discordgo.ApplicationCommand {
+	memberOptions := func() []*discordgo.ApplicationCommandOption {
+		return []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "member",
+				Description: "Discord member",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "family_name",
+				Description: "Family name of the member",
+				Required:    false,
+			},
+		}
+	}
+
+	return &discordgo.ApplicationCommand{
+		Name:        "alias",
+		Description: "Manage alternative family names used to match members (officer role required)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Add an alias (old family name, OCR misread or nickname) for a member",
+				Options: append([]*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "alias",
+						Description: "Alternative name that should resolve to the member",
+						Required:    true,
+					},
+				}, memberOptions()...),
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Remove an alias",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "alias",
+						Description: "Alias to remove",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List aliases for all members or a single member",
+				Options:     memberOptions(),
+			},
+		},
+	}
+}
+
+func handleAlias(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	if !hasOfficerPermission(s, i, cfg) {
+		discord.RespondEphemeral(s, i, "You need officer role or admin permission to use this command.")
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		discord.RespondEphemeral(s, i, "Please choose add, remove or list.")
+		return
+	}
+
+	subcommand := options[0]
+	switch subcommand.Name {
+	case "add":
+		handleAliasAdd(s, i, dbx, subcommand.Options)
+	case "remove":
+		handleAliasRemove(s, i, dbx, subcommand.Options)
+	case "list":
+		handleAliasList(s, i, dbx, subcommand.Options)
+	default:
+		discord.RespondEphemeral(s, i, "Unknown subcommand.")
+	}
+}
+
+// findMemberOption resolves the member or family_name option of a subcommand
+// Returns nil without error when neither option was provided
+func findMemberOption(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, options []*discordgo.ApplicationCommandInteractionDataOption) (*internal.Member, error) {
+	var targetUser *discordgo.User
+	var familyName string
+
+	for _, opt := range options {
+		switch opt.Name {
+		case "member":
+			targetUser = opt.UserValue(s)
+		case "family_name":
+			familyName = strings.TrimSpace(opt.StringValue())
+		}
+	}
+
+	if targetUser != nil {
+		return internal.GetMemberByDiscordUserIDIncludingInactive(dbx, i.GuildID, targetUser.ID)
+	}
+	if familyName != "" {
+		return internal.GetMemberByFamilyNameIncludingInactive(dbx, i.GuildID, familyName)
+	}
+
+	return nil, nil
+}
+
+// getAliasOption returns the trimmed alias option
+func getAliasOption(options []*discordgo.ApplicationCommandInteractionDataOption) string {
+	for _, opt := range options {
+		if opt.Name == "alias" {
+			return strings.TrimSpace(opt.StringValue())
+		}
+	}
+	return ""
+}
+
+func handleAliasAdd(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	alias := getAliasOption(options)
+	if alias == "" {
+		discord.RespondEphemeral(s, i, "Alias is required.")
+		return
+	}
+	if utf8.RuneCountInString(alias) > maxAliasLength {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("Alias must be at most %d characters.", maxAliasLength))
+		return
+	}
+
+	m, err := findMemberOption(s, i, dbx, options)
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, "Member not found.")
+		return
+	} else if err != nil {
+		log.Printf("alias lookup error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to add alias. Please try again.")
+		return
+	}
+	if m == nil {
+		discord.RespondEphemeral(s, i, "Please provide either a Discord member or family name.")
+		return
+	}
+
+	err = db.AddMemberAlias(dbx, i.GuildID, m.ID, alias, i.Member.User.ID)
+	if errors.Is(err, db.ErrAliasTaken) {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("'%s' is already a family name or alias in this guild.", alias))
+		return
+	} else if err != nil {
+		log.Printf("alias add error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to add alias. Please try again.")
+		return
+	}
+
+	discord.RespondText(s, i, fmt.Sprintf("Alias '%s' now resolves to '%s'.", alias, m.FamilyName))
+}
+
+func handleAliasRemove(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	alias := getAliasOption(options)
+	if alias == "" {
+		discord.RespondEphemeral(s, i, "Alias is required.")
+		return
+	}
+
+	familyName, err := db.RemoveMemberAlias(dbx, i.GuildID, alias)
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("Alias '%s' not found.", alias))
+		return
+	} else if err != nil {
+		log.Printf("alias remove error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to remove alias. Please try again.")
+		return
+	}
+
+	discord.RespondText(s, i, fmt.Sprintf("Alias '%s' removed from '%s'.", alias, familyName))
+}
+
+func handleAliasList(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	m, err := findMemberOption(s, i, dbx, options)
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, "Member not found.")
+		return
+	} else if err != nil {
+		log.Printf("alias lookup error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to list aliases. Please try again.")
+		return
+	}
+
+	var memberID int64
+	if m != nil {
+		memberID = m.ID
+	}
+
+	aliases, err := db.GetMemberAliases(dbx, i.GuildID, memberID)
+	if err != nil {
+		log.Printf("alias list error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to list aliases. Please try again.")
+		return
+	}
+
+	if len(aliases) == 0 {
+		if m != nil {
+			discord.RespondEphemeral(s, i, fmt.Sprintf("'%s' has no aliases.", m.FamilyName))
+		} else {
+			discord.RespondEphemeral(s, i, "No aliases found.")
+		}
+		return
+	}
+
+	discord.RespondEphemeral(s, i, formatAliasList(aliases))
+}
+
+// formatAliasList groups aliases by family name, one member per line
+func formatAliasList(aliases []db.MemberAlias) string {
+	var b strings.Builder
+	b.WriteString("**Aliases**\n")
+
+	for idx := 0; idx < len(aliases); {
+		familyName := aliases[idx].FamilyName
+		var names []string
+		for idx < len(aliases) && aliases[idx].FamilyName == familyName {
+			names = append(names, aliases[idx].Alias)
+			idx++
+		}
+		b.WriteString(fmt.Sprintf("%s: %s\n", familyName, strings.Join(names, ", ")))
+	}
+
+	// Keep within Discord's 2000 character message limit
+	return truncateString(b.String(), 2000)
+}
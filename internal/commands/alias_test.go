@@ -0,0 +1,20 @@
+package commands
+
+import (
+	"testing"
+
+	"PanickedBot/internal/db"
+)
+
+func TestFormatAliasList(t *testing.T) {
+	aliases := []db.MemberAlias{
+		{Alias: "Hammiity", FamilyName: "Hammity"},
+		{Alias: "Hams", FamilyName: "Hammity"},
+		{Alias: "OldName", FamilyName: "Kethrya"},
+	}
+
+	expected := "**Aliases**\nHammity: Hammiity, Hams\nKethrya: OldName\n"
+	if got := formatAliasList(aliases); got != expected {
+		t.Errorf("formatAliasList() = %q, expected %q", got, expected)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 
@@ -39,7 +40,7 @@ func handleAttendance(s *discordgo.Session, i *discordgo.InteractionCreate, dbx
 	}
 
 	// Create attendance checker
-	checker := internal.NewAttendanceChecker(dbx)
+	checker := internal.NewAttendanceChecker(dbx, cfg)
 
 	// Check all members attendance
 	results, err := checker.CheckAllMembersAttendance(i.GuildID, int(weeksBack))
@@ -59,7 +60,8 @@ func handleAttendance(s *discordgo.Session, i *discordgo.InteractionCreate, dbx
 
 	// Build response message
 	var message strings.Builder
-	message.WriteString(fmt.Sprintf("**Attendance Report (Last %d weeks)**\n\n", weeksBack))
+	message.WriteString(fmt.Sprintf("**Attendance Report (Last %d weeks)**\n", weeksBack))
+	message.WriteString(fmt.Sprintf("Policy: %s\n\n", checker.Policy()))
 
 	if len(membersWithIssues) == 0 {
 		message.WriteString("✅ No members have attendance issues!")
@@ -76,7 +78,7 @@ func handleAttendance(s *discordgo.Session, i *discordgo.InteractionCreate, dbx
 				message.WriteString("  • Missed weeks: ")
 				weekStrs := make([]string, len(result.MissedWeeks))
 				for idx, week := range result.MissedWeeks {
-					weekStrs[idx] = week.StartDate.Format("02-01-06")
+					weekStrs[idx] = formatMissedWeek(week)
 				}
 				message.WriteString(strings.Join(weekStrs, ", "))
 				message.WriteString("\n")
@@ -148,7 +150,7 @@ func handleCheckAttendance(s *discordgo.Session, i *discordgo.InteractionCreate,
 	}
 
 	// Create attendance checker
-	checker := internal.NewAttendanceChecker(dbx)
+	checker := internal.NewAttendanceChecker(dbx, cfg)
 
 	// Check member attendance
 	result, err := checker.CheckMemberAttendance(i.GuildID, member.ID, int(weeksBack))
@@ -161,21 +163,166 @@ func handleCheckAttendance(s *discordgo.Session, i *discordgo.InteractionCreate,
 	// Build response message
 	var message strings.Builder
 	message.WriteString(fmt.Sprintf("**Attendance Report for %s**\n", result.FamilyName))
-	message.WriteString(fmt.Sprintf("Member since: %s\n\n", result.CreatedAt.Format("02-01-06")))
+	message.WriteString(fmt.Sprintf("Member since: %s\n", result.CreatedAt.In(cfg.Location()).Format("02-01-06")))
+	message.WriteString(fmt.Sprintf("Policy: %s\n\n", checker.Policy()))
 	message.WriteString(fmt.Sprintf("**Last %d weeks:**\n", weeksBack))
 	message.WriteString(fmt.Sprintf("• Total weeks: %d\n", result.TotalWeeks))
 	message.WriteString(fmt.Sprintf("• Attended: %d weeks\n", result.AttendedWeeks))
-	message.WriteString(fmt.Sprintf("• Missed: %d weeks\n\n", len(result.MissedWeeks)))
+	message.WriteString(fmt.Sprintf("• Missed: %d weeks\n", len(result.MissedWeeks)))
+	message.WriteString(fmt.Sprintf("• Excused by vacation or exclusion: %d weeks\n\n", result.ExcusedWeeks))
 
 	if len(result.MissedWeeks) == 0 {
 		message.WriteString("✅ No missed weeks!")
 	} else {
 		message.WriteString("⚠️ **Missed weeks:**\n")
 		for _, week := range result.MissedWeeks {
-			message.WriteString(fmt.Sprintf("• Week of %s\n", week.StartDate.Format("02-01-06")))
+			message.WriteString(fmt.Sprintf("• Week of %s\n", formatMissedWeek(week)))
 		}
 	}
 
 	// Send response
 	discord.RespondText(s, i, message.String())
 }
+
+func setAttendanceCommand() *discordgo.ApplicationCommand {
+	weekdayChoices := make([]*discordgo.ApplicationCommandOptionChoice, 0, 7)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		weekdayChoices = append(weekdayChoices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  day.String(),
+			Value: int(day),
+		})
+	}
+
+	return &discordgo.ApplicationCommand{
+		Name:        "setattendance",
+		Description: "Set the weekly attendance policy (officer role required)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "week_start",
+				Description: "First day of attendance weeks (default Sunday)",
+				Required:    false,
+				Choices:     weekdayChoices,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "war_days",
+				Description: "Scheduled war nights, e.g. \"tue, thu, sun\", or \"any\" for every day a war was fought",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "min_wars",
+				Description: "War nights required per week",
+				Required:    false,
+				MinValue:    float64Ptr(1),
+				MaxValue:    7,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "min_percent",
+				Description: "Percentage of the week's war nights required, instead of min_wars",
+				Required:    false,
+				MinValue:    float64Ptr(1),
+				MaxValue:    100,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "vacation_approval",
+				Description: "Whether vacations requested by members need officer approval before they count",
+				Required:    false,
+			},
+		},
+	}
+}
+
+func handleSetAttendance(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	if !hasOfficerPermission(s, i, cfg) {
+		discord.RespondEphemeral(s, i, "You need officer role or admin permission to use this command.")
+		return
+	}
+
+	// Options left out keep their current value
+	policy := cfg.AttendancePolicy()
+	changed := false
+	var minWarsSet, minPercentSet bool
+	var vacationApproval *bool
+
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "week_start":
+			policy.WeekStart = time.Weekday(opt.IntValue())
+			changed = true
+		case "war_days":
+			days, err := internal.ParseWarDays(opt.StringValue())
+			if err != nil {
+				discord.RespondEphemeral(s, i, fmt.Sprintf("Invalid war_days: %v. Use day names such as \"tue, thu, sun\", or \"any\".", err))
+				return
+			}
+			policy.WarDays = days
+			changed = true
+		case "min_wars":
+			policy.MinWars = int(opt.IntValue())
+			policy.MinPercent = 0
+			minWarsSet = true
+			changed = true
+		case "min_percent":
+			policy.MinPercent = int(opt.IntValue())
+			minPercentSet = true
+			changed = true
+		case "vacation_approval":
+			value := opt.BoolValue()
+			vacationApproval = &value
+		}
+	}
+
+	if !changed && vacationApproval == nil {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("Current attendance policy: %s.\n%s", policy, formatVacationApproval(cfg.VacationApproval)))
+		return
+	}
+
+	if minWarsSet && minPercentSet {
+		discord.RespondEphemeral(s, i, "Provide either min_wars or min_percent, not both.")
+		return
+	}
+
+	if len(policy.WarDays) > 0 && policy.MinPercent == 0 && policy.MinWars > len(policy.WarDays) {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("min_wars (%d) cannot exceed the number of war days (%d).", policy.MinWars, len(policy.WarDays)))
+		return
+	}
+
+	var updates []string
+	if changed {
+		err := db.UpdateAttendancePolicy(dbx, i.GuildID, int(policy.WeekStart), internal.WarDaysMask(policy.WarDays), policy.MinWars, policy.MinPercent)
+		if err != nil {
+			log.Printf("setattendance error: %v", err)
+			discord.RespondEphemeral(s, i, "Failed to save attendance policy. Please try again.")
+			return
+		}
+		updates = append(updates, "Attendance policy updated: "+policy.String()+".")
+	}
+
+	if vacationApproval != nil {
+		if err := db.UpdateVacationApproval(dbx, i.GuildID, *vacationApproval); err != nil {
+			log.Printf("setattendance vacation approval error: %v", err)
+			discord.RespondEphemeral(s, i, "Failed to save vacation approval setting. Please try again.")
+			return
+		}
+		updates = append(updates, formatVacationApproval(*vacationApproval))
+	}
+
+	discord.RespondText(s, i, strings.Join(updates, "\n"))
+}
+
+// formatVacationApproval describes whether member vacation requests need officer approval
+func formatVacationApproval(required bool) string {
+	if required {
+		return "Vacations requested by members need officer approval."
+	}
+	return "Vacations requested by members count right away."
+}
+
+// formatMissedWeek describes a missed week and how many war nights were attended
+func formatMissedWeek(week internal.MissedWeek) string {
+	return fmt.Sprintf("%s (%d of %d war nights)", week.StartDate.Format("02-01-06"), week.Attended, week.Required)
+}
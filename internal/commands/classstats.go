@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/discord"
+)
+
+func classStatsCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "classstats",
+		Description: "Show average kills, deaths and K/D per class and spec (officer role required)",
+		Options:     warFilterOptions(),
+	}
+}
+
+// classStatKD calculates the K/D ratio of a class stat, like /warstats
+func classStatKD(kills, deaths int) float64 {
+	if deaths == 0 {
+		return float64(kills)
+	}
+	return float64(kills) / float64(deaths)
+}
+
+// sortClassStats orders class stats from the best K/D to the worst, then by class and spec
+func sortClassStats(stats []db.ClassStat) {
+	sort.SliceStable(stats, func(a, b int) bool {
+		kdA := classStatKD(stats[a].TotalKills, stats[a].TotalDeaths)
+		kdB := classStatKD(stats[b].TotalKills, stats[b].TotalDeaths)
+		if kdA != kdB {
+			return kdA > kdB
+		}
+		if stats[a].Class != stats[b].Class {
+			return stats[a].Class < stats[b].Class
+		}
+		return stats[a].Spec < stats[b].Spec
+	})
+}
+
+// formatClassStatLine formats one row of the class stats table
+func formatClassStatLine(class, spec, members string, lines, kills, deaths int) string {
+	avgKills, avgDeaths := 0.0, 0.0
+	if lines > 0 {
+		avgKills = float64(kills) / float64(lines)
+		avgDeaths = float64(deaths) / float64(lines)
+	}
+	return fmt.Sprintf("%-12s %-10s %4s %5d %6.1f %6.1f %5.2f\n",
+		truncateString(class, 12), truncateString(spec, 10), members, lines, avgKills, avgDeaths, classStatKD(kills, deaths))
+}
+
+// classStatsTable builds the class stats report, best K/D first
+func classStatsTable(stats []db.ClassStat, description string) pagedTable {
+	sortClassStats(stats)
+
+	var lines []string
+	totalLines, totalKills, totalDeaths := 0, 0, 0
+	hasUnknown := false
+	for _, stat := range stats {
+		class, spec := stat.Class, stat.Spec
+		if class == "" {
+			class = "Unknown"
+			hasUnknown = true
+		}
+		if spec == "" {
+			spec = "-"
+		} else {
+			spec = strings.ToUpper(spec[:1]) + spec[1:]
+		}
+
+		lines = append(lines, formatClassStatLine(class, spec, fmt.Sprintf("%d", stat.Members), stat.Lines, stat.TotalKills, stat.TotalDeaths))
+		totalLines += stat.Lines
+		totalKills += stat.TotalKills
+		totalDeaths += stat.TotalDeaths
+	}
+
+	separator := strings.Repeat("-", 54) + "\n"
+
+	note := "\nWars counts each member's appearances; averages are per war played."
+	if hasUnknown {
+		note += " Unknown lists lines recorded without a class snapshot."
+	}
+
+	return pagedTable{
+		Title: reportTitle("Class Stats", description),
+		Header: fmt.Sprintf("%-12s %-10s %4s %5s %6s %6s %5s\n",
+			"Class", "Spec", "Mbrs", "Wars", "Avg K", "Avg D", "K/D") + separator,
+		Lines:  lines,
+		Footer: separator + formatClassStatLine("Total", "", "", totalLines, totalKills, totalDeaths),
+		Note:   note,
+	}
+}
+
+func handleClassStats(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	if !hasOfficerPermission(s, i, cfg) {
+		discord.RespondEphemeral(s, i, "You need officer role or admin permission to use this command.")
+		return
+	}
+
+	filter, description, problem := parseWarFilter(i.ApplicationCommandData().Options, cfg)
+	if problem != "" {
+		discord.RespondEphemeral(s, i, problem)
+		return
+	}
+	stats, err := db.GetClassStats(dbx, i.GuildID, filter)
+	if err != nil {
+		log.Printf("classstats error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve class statistics. Please try again.")
+		return
+	}
+
+	if len(stats) == 0 {
+		discord.RespondEphemeral(s, i, "No war data found for these wars.")
+		return
+	}
+
+	respondPaged(s, i, classStatsTable(stats, description).pages())
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"PanickedBot/internal/db"
+)
+
+func TestSortClassStats(t *testing.T) {
+	stats := []db.ClassStat{
+		{Class: "Warrior", Spec: "succession", TotalKills: 10, TotalDeaths: 10},
+		{Class: "Archer", Spec: "awakening", TotalKills: 30, TotalDeaths: 10},
+		{Class: "Warrior", Spec: "awakening", TotalKills: 20, TotalDeaths: 20},
+		{Class: "", Spec: "", TotalKills: 5, TotalDeaths: 0},
+	}
+
+	sortClassStats(stats)
+
+	var got []string
+	for _, s := range stats {
+		got = append(got, s.Class+"/"+s.Spec)
+	}
+	want := "/|Archer/awakening|Warrior/awakening|Warrior/succession"
+	if strings.Join(got, "|") != want {
+		t.Errorf("sortClassStats() order = %v, want %s", got, want)
+	}
+}
+
+func TestClassStatsTable(t *testing.T) {
+	stats := []db.ClassStat{
+		{Class: "Warrior", Spec: "awakening", Members: 2, Lines: 4, TotalKills: 40, TotalDeaths: 20},
+		{Class: "", Spec: "", Members: 1, Lines: 2, TotalKills: 2, TotalDeaths: 4},
+	}
+
+	table := classStatsTable(stats, "Siege")
+
+	if table.Title != "Class Stats: Siege" {
+		t.Errorf("Title = %q", table.Title)
+	}
+	if len(table.Lines) != 2 {
+		t.Fatalf("Lines = %d, want 2", len(table.Lines))
+	}
+	if want := formatClassStatLine("Warrior", "Awakening", "2", 4, 40, 20); table.Lines[0] != want {
+		t.Errorf("first line = %q, want %q", table.Lines[0], want)
+	}
+	if want := formatClassStatLine("Unknown", "-", "1", 2, 2, 4); table.Lines[1] != want {
+		t.Errorf("second line = %q, want %q", table.Lines[1], want)
+	}
+	if !strings.Contains(table.Footer, formatClassStatLine("Total", "", "", 6, 42, 24)) {
+		t.Errorf("Footer = %q, want the totals", table.Footer)
+	}
+	if !strings.Contains(table.Note, "Unknown") {
+		t.Errorf("Note = %q, want the Unknown explanation", table.Note)
+	}
+
+	known := classStatsTable(stats[:1], "")
+	if strings.Contains(known.Note, "Unknown") {
+		t.Errorf("Note = %q, want no Unknown explanation", known.Note)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"log"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 
@@ -30,6 +31,10 @@ func getSpecChoices() []*discordgo.ApplicationCommandOptionChoice {
 func GetCommands() []*discordgo.ApplicationCommand {
 	return []*discordgo.ApplicationCommand{
 		setupCommand(),
+		setOCRCommand(),
+		setAttendanceCommand(),
+		aliasCommand(),
+		editWarCommand(),
 		{
 			Name:        "addteam",
 			Description: "Add a new team (officer role required)",
@@ -105,23 +110,29 @@ func GetCommands() []*discordgo.ApplicationCommand {
 					Type:        discordgo.ApplicationCommandOptionInteger,
 					Name:        "ap",
 					Description: "Attack Power (AP)",
-					Required:    true,
+					Required:    false,
 					MinValue:    float64Ptr(0),
 				},
 				{
 					Type:        discordgo.ApplicationCommandOptionInteger,
 					Name:        "aap",
 					Description: "Awakening Attack Power (AAP)",
-					Required:    true,
+					Required:    false,
 					MinValue:    float64Ptr(0),
 				},
 				{
 					Type:        discordgo.ApplicationCommandOptionInteger,
 					Name:        "dp",
 					Description: "Defense Power (DP)",
-					Required:    true,
+					Required:    false,
 					MinValue:    float64Ptr(0),
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "screenshot",
+					Description: "Screenshot of the in-game stat window, instead of entering AP, AAP and DP",
+					Required:    false,
+				},
 				{
 					Type:        discordgo.ApplicationCommandOptionUser,
 					Name:        "member",
@@ -130,6 +141,10 @@ func GetCommands() []*discordgo.ApplicationCommand {
 				},
 			},
 		},
+		statsCommand(),
+		gearHistoryCommand(),
+		gearReportCommand(),
+		statCapCommand(),
 		{
 			Name:        "updatemember",
 			Description: "Update another member's information (officer role required)",
@@ -166,10 +181,15 @@ func GetCommands() []*discordgo.ApplicationCommand {
 					Required:    false,
 				},
 				{
-					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "meets_cap",
-					Description: "Whether member meets required stat caps",
+					Description: "Override whether the member meets the stat caps, or compute it from their gear",
 					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Yes (override)", Value: "yes"},
+						{Name: "No (override)", Value: "no"},
+						{Name: "Automatic from stat caps", Value: "auto"},
+					},
 				},
 			},
 		},
@@ -194,6 +214,67 @@ func GetCommands() []*discordgo.ApplicationCommand {
 		{
 			Name:        "roster",
 			Description: "Get all roster member information (officer role required)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "view",
+					Description: "Columns to show (default: overview)",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Overview: class, spec, GS and cap", Value: "overview"},
+						{Name: "Gear stats: AP, AAP, DP, evasion, DR, DRR, accuracy, HP", Value: "stats"},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "sort",
+					Description: "Order of the members (default: GS)",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "GS, highest first", Value: rosterSortGS},
+						{Name: "Family name", Value: rosterSortFamilyName},
+						{Name: "Class", Value: rosterSortClass},
+						{Name: "Join date, oldest first", Value: rosterSortJoined},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "team",
+					Description: "Only show members of this team",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "class",
+					Description: "Only show members of this class",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "spec",
+					Description: "Only show members with this specialization",
+					Required:    false,
+					Choices:     getSpecChoices(),
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "meets_cap",
+					Description: "Only show members who meet (true) or miss (false) the cap",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "include_mercs",
+					Description: "Include mercenary members (default: false)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "include_inactive",
+					Description: "Include inactive members (default: false)",
+					Required:    false,
+				},
+			},
 		},
 		{
 			Name:        "link",
@@ -232,43 +313,41 @@ func GetCommands() []*discordgo.ApplicationCommand {
 			},
 		},
 		{
-			Name:        "vacation",
-			Description: "Add a vacation period for a member (officer role required)",
+			Name:        "kdexception",
+			Description: "Leave a member's K/D out of guild totals, e.g. shotcallers and flag carriers (officer role required)",
 			Options: []*discordgo.ApplicationCommandOption{
 				{
-					Type:        discordgo.ApplicationCommandOptionUser,
-					Name:        "member",
-					Description: "Discord member going on vacation",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "start_date",
-					Description: "Vacation start date (DD-MM-YY)",
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "is_exception",
+					Description: "Whether the member's kills and deaths are left out of guild totals",
 					Required:    true,
 				},
 				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "end_date",
-					Description: "Vacation end date (DD-MM-YY)",
-					Required:    true,
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "member",
+					Description: "Discord member to update",
+					Required:    false,
 				},
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "reason",
-					Description: "Optional reason for vacation",
+					Name:        "family_name",
+					Description: "Family name of the member to update, e.g. members without Discord",
 					Required:    false,
 				},
 			},
 		},
+		importRosterCommand(),
+		vacationCommand(),
+		exclusionCommand(),
 		{
 			Name:        "warstats",
-			Description: "Get war statistics for all roster members or a specific war date (officer role required)",
-			Options: []*discordgo.ApplicationCommandOption{
+			Description: "Get war statistics for all roster members or a specific war (officer role required)",
+			Options: append([]*discordgo.ApplicationCommandOption{
+				warOption("Optional war to show stats for (search by ID, date or label)", false),
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "date",
-					Description: "Optional war date in DD-MM-YY format to show stats for that specific war",
+					Description: "Optional war date in DD-MM-YY format, when only one war was fought that day",
 					Required:    false,
 				},
 				{
@@ -289,27 +368,46 @@ func GetCommands() []*discordgo.ApplicationCommand {
 					Description: "Filter results to only members of this team",
 					Required:    false,
 				},
-			},
+			}, warFilterOptions()...),
 		},
 		{
 			Name:        "warresults",
 			Description: "Get results of all wars from most recent to oldest (officer role required)",
+			Options:     warFilterOptions(),
 		},
+		classStatsCommand(),
+		myStatsCommand(),
+		exportCommand(),
 		{
 			Name:        "removewar",
-			Description: "Remove war data for a specific date (officer role required)",
+			Description: "Remove the data of a war (officer role required)",
 			Options: []*discordgo.ApplicationCommandOption{
+				warOption("War to remove (search by ID, date or label)", false),
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "date",
-					Description: "War date in DD-MM-YY format",
-					Required:    true,
+					Description: "War date in DD-MM-YY format, when only one war was fought that day",
+					Required:    false,
 				},
 			},
 		},
+		{
+			Name:        "excludewar",
+			Description: "Exclude a war from statistics and attendance (officer role required)",
+			Options: []*discordgo.ApplicationCommandOption{
+				warOption("War to exclude (search by ID, date or label)", true),
+			},
+		},
+		{
+			Name:        "includewar",
+			Description: "Include an excluded war in statistics and attendance again (officer role required)",
+			Options: []*discordgo.ApplicationCommandOption{
+				warOption("War to include (search by ID, date or label)", true),
+			},
+		},
 		{
 			Name:        "addwar",
-			Description: "Import war data from a CSV or image file (officer role required)",
+			Description: "Import war data from a CSV file or scoreboard screenshots (officer role required)",
 			Options: []*discordgo.ApplicationCommandOption{
 				{
 					Type:        discordgo.ApplicationCommandOptionAttachment,
@@ -348,6 +446,31 @@ func GetCommands() []*discordgo.ApplicationCommand {
 						{Name: "Uncapped", Value: "uncapped"},
 					},
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "label",
+					Description: "Name telling this war apart from others on the same day, e.g. the node",
+					Required:    false,
+					MaxLength:   maxWarLabelLength,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "file2",
+					Description: "Additional scoreboard screenshot of the same war",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "file3",
+					Description: "Additional scoreboard screenshot of the same war",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "file4",
+					Description: "Additional scoreboard screenshot of the same war",
+					Required:    false,
+				},
 			},
 		},
 		{
@@ -394,8 +517,23 @@ func GetCommands() []*discordgo.ApplicationCommand {
 }
 
 // CreateInteractionHandler creates the interaction handler for commands
-func CreateInteractionHandler(database *db.DB) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func CreateInteractionHandler(database *db.DB, jobs *WarJobQueue) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		if i.Type == discordgo.InteractionMessageComponent {
+			handleMessageComponent(s, i, database)
+			return
+		}
+
+		if i.Type == discordgo.InteractionModalSubmit {
+			handleModalSubmit(s, i, database)
+			return
+		}
+
+		if i.Type == discordgo.InteractionApplicationCommandAutocomplete {
+			handleAutocomplete(s, i, database)
+			return
+		}
+
 		if i.Type != discordgo.InteractionApplicationCommand {
 			return
 		}
@@ -445,7 +583,16 @@ func CreateInteractionHandler(database *db.DB) func(s *discordgo.Session, i *dis
 			handleUpdateSelf(s, i, database, cfg)
 
 		case "gear":
-			handleGear(s, i, database, cfg)
+			handleGear(s, i, database, cfg, jobs)
+
+		case "gearhistory":
+			handleGearHistory(s, i, database, cfg)
+		case "gearreport":
+			handleGearReport(s, i, database, cfg)
+		case "statcap":
+			handleStatCap(s, i, database, cfg)
+		case "stats":
+			handleStats(s, i, database, cfg)
 
 		case "updatemember":
 			handleUpdateMember(s, i, database, cfg)
@@ -462,11 +609,23 @@ func CreateInteractionHandler(database *db.DB) func(s *discordgo.Session, i *dis
 		case "link":
 			handleLink(s, i, database, cfg)
 
+		case "alias":
+			handleAlias(s, i, database, cfg)
+
+		case "setocr":
+			handleSetOCR(s, i, database, cfg)
+
 		case "merc":
 			handleMerc(s, i, database, cfg)
+		case "kdexception":
+			handleKDException(s, i, database, cfg)
+		case "importroster":
+			handleImportRoster(s, i, database, cfg)
 
 		case "vacation":
 			handleVacation(s, i, database, cfg)
+		case "exclusion":
+			handleExclusion(s, i, database, cfg)
 
 		case "warstats":
 			handleWarStats(s, i, database, cfg)
@@ -474,11 +633,29 @@ func CreateInteractionHandler(database *db.DB) func(s *discordgo.Session, i *dis
 		case "warresults":
 			handleWarResults(s, i, database, cfg)
 
+		case "classstats":
+			handleClassStats(s, i, database, cfg)
+
+		case "mystats":
+			handleMyStats(s, i, database, cfg)
+
+		case "export":
+			handleExport(s, i, database, cfg)
+
 		case "removewar":
 			handleRemoveWar(s, i, database, cfg)
 
+		case "editwar":
+			handleEditWar(s, i, database, cfg)
+
+		case "excludewar":
+			handleExcludeWar(s, i, database, cfg)
+
+		case "includewar":
+			handleIncludeWar(s, i, database, cfg)
+
 		case "addwar":
-			handleAddWar(s, i, database, cfg)
+			handleAddWar(s, i, database, cfg, jobs)
 
 		case "attendance":
 			handleAttendance(s, i, database, cfg)
@@ -486,9 +663,133 @@ func CreateInteractionHandler(database *db.DB) func(s *discordgo.Session, i *dis
 		case "checkattendance":
 			handleCheckAttendance(s, i, database, cfg)
 
+		case "setattendance":
+			handleSetAttendance(s, i, database, cfg)
+
 		default:
 			discord.RespondEphemeral(s, i, "Unknown command.")
 		}
 
 	}
 }
+
+// handleMessageComponent routes button and select menu interactions by their custom ID prefix
+func handleMessageComponent(s *discordgo.Session, i *discordgo.InteractionCreate, database *db.DB) {
+	if i.GuildID == "" {
+		return
+	}
+
+	cfg, err := internal.LoadGuildConfig(database, i.GuildID)
+	if err != nil {
+		log.Printf("load guild config: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to load guild configuration. Please try again.")
+		return
+	}
+
+	prefix, _, _ := strings.Cut(i.MessageComponentData().CustomID, ":")
+	switch prefix {
+	case warReviewPrefix:
+		handleWarReviewComponent(s, i, database, cfg)
+
+	case gearScreenshotPrefix:
+		handleGearScreenshotComponent(s, i, database, cfg)
+
+	case pagePrefix:
+		handlePageComponent(s, i)
+
+	default:
+		discord.RespondEphemeral(s, i, "Unknown action.")
+	}
+}
+
+// handleModalSubmit routes submitted modals by their custom ID prefix
+func handleModalSubmit(s *discordgo.Session, i *discordgo.InteractionCreate, database *db.DB) {
+	if i.GuildID == "" {
+		return
+	}
+
+	cfg, err := internal.LoadGuildConfig(database, i.GuildID)
+	if err != nil {
+		log.Printf("load guild config: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to load guild configuration. Please try again.")
+		return
+	}
+
+	prefix, _, _ := strings.Cut(i.ModalSubmitData().CustomID, ":")
+	switch prefix {
+	case warReviewPrefix:
+		handleWarReviewModal(s, i, database, cfg)
+
+	default:
+		discord.RespondEphemeral(s, i, "Unknown action.")
+	}
+}
+
+// focusedOption returns the option being typed in an autocomplete interaction, looking into subcommands
+func focusedOption(options []*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, opt := range options {
+		if opt.Focused {
+			return opt
+		}
+		if focused := focusedOption(opt.Options); focused != nil {
+			return focused
+		}
+	}
+	return nil
+}
+
+// findOption returns the option with the given name, looking into subcommands
+func findOption(options []*discordgo.ApplicationCommandInteractionDataOption, name string) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, opt := range options {
+		if opt.Name == name {
+			return opt
+		}
+		if found := findOption(opt.Options, name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// handleAutocomplete suggests values for autocompleted command options
+func handleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, database *db.DB) {
+	if i.GuildID == "" {
+		discord.RespondAutocomplete(s, i, nil)
+		return
+	}
+
+	focused := focusedOption(i.ApplicationCommandData().Options)
+	if focused == nil {
+		discord.RespondAutocomplete(s, i, nil)
+		return
+	}
+
+	cfg, err := internal.LoadGuildConfig(database, i.GuildID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("load guild config: %v", err)
+		}
+		discord.RespondAutocomplete(s, i, nil)
+		return
+	}
+
+	switch focused.Name {
+	case "war":
+		handleWarAutocomplete(s, i, database, cfg, focused.StringValue())
+	case "line":
+		warRef := ""
+		if war := findOption(i.ApplicationCommandData().Options, "war"); war != nil {
+			warRef = war.StringValue()
+		}
+		handleWarLineAutocomplete(s, i, database, cfg, warRef, focused.StringValue())
+	case "ocr_name":
+		handleOCRNameAutocomplete(s, i, database, cfg, focused.StringValue())
+	case "vacation":
+		handleVacationAutocomplete(s, i, database, cfg, focused.StringValue())
+	case "exclusion":
+		handleExclusionAutocomplete(s, i, database, cfg, focused.StringValue())
+
+	default:
+		discord.RespondAutocomplete(s, i, nil)
+	}
+}
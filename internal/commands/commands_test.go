@@ -2,6 +2,8 @@ package commands
 
 import (
 	"testing"
+
+	"github.com/bwmarrin/discordgo"
 )
 
 func TestFloat64Ptr(t *testing.T) {
@@ -101,3 +103,75 @@ func TestGetCommands(t *testing.T) {
 		}
 	}
 }
+
+func TestCommandOptionOrder(t *testing.T) {
+	// Discord rejects commands that list optional options before required ones
+	var check func(path string, options []*discordgo.ApplicationCommandOption)
+	check = func(path string, options []*discordgo.ApplicationCommandOption) {
+		seenOptional := false
+		for _, opt := range options {
+			switch opt.Type {
+			case discordgo.ApplicationCommandOptionSubCommand, discordgo.ApplicationCommandOptionSubCommandGroup:
+				check(path+" "+opt.Name, opt.Options)
+				continue
+			}
+			if opt.Required && seenOptional {
+				t.Errorf("/%s: required option %q follows an optional option", path, opt.Name)
+			}
+			if !opt.Required {
+				seenOptional = true
+			}
+		}
+	}
+
+	for _, cmd := range GetCommands() {
+		check(cmd.Name, cmd.Options)
+	}
+}
+
+func TestCommandDescriptionLength(t *testing.T) {
+	// Discord rejects command, option and choice texts longer than 100 characters
+	var check func(path string, options []*discordgo.ApplicationCommandOption)
+	check = func(path string, options []*discordgo.ApplicationCommandOption) {
+		for _, opt := range options {
+			if n := len([]rune(opt.Description)); n > 100 {
+				t.Errorf("/%s %s: description is %d characters", path, opt.Name, n)
+			}
+			for _, choice := range opt.Choices {
+				if n := len([]rune(choice.Name)); n > 100 {
+					t.Errorf("/%s %s: choice %q is %d characters", path, opt.Name, choice.Name, n)
+				}
+			}
+			check(path+" "+opt.Name, opt.Options)
+		}
+	}
+
+	for _, cmd := range GetCommands() {
+		if n := len([]rune(cmd.Description)); n > 100 {
+			t.Errorf("/%s: description is %d characters", cmd.Name, n)
+		}
+		check(cmd.Name, cmd.Options)
+	}
+}
+
+func TestFocusedOption(t *testing.T) {
+	options := []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "date", Type: discordgo.ApplicationCommandOptionString, Value: "15-01-25"},
+		{
+			Name: "rename",
+			Type: discordgo.ApplicationCommandOptionSubCommand,
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "war", Type: discordgo.ApplicationCommandOptionString, Value: "12", Focused: true},
+			},
+		},
+	}
+
+	focused := focusedOption(options)
+	if focused == nil || focused.Name != "war" {
+		t.Fatalf("focusedOption() = %+v, want the war option", focused)
+	}
+
+	if focusedOption(options[:1]) != nil {
+		t.Error("focusedOption() should return nil when no option is focused")
+	}
+}
@@ -0,0 +1,489 @@
+package commands
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/discord"
+)
+
+func editWarCommand() *discordgo.ApplicationCommand {
+	warChoice := func() *discordgo.ApplicationCommandOption {
+		return warOption("War to edit (search by ID, date or label)", true)
+	}
+	lineChoice := func() *discordgo.ApplicationCommandOption {
+		return &discordgo.ApplicationCommandOption{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "line",
+			Description:  "Line of the war (search by name)",
+			Required:     true,
+			Autocomplete: true,
+		}
+	}
+	countOption := func(name, description string, required bool) *discordgo.ApplicationCommandOption {
+		return &discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        name,
+			Description: description,
+			Required:    required,
+			MinValue:    float64Ptr(0),
+		}
+	}
+	memberOptions := func() []*discordgo.ApplicationCommandOption {
+		return []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "member",
+				Description: "Discord member",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "family_name",
+				Description: "Family name of the member",
+				Required:    false,
+			},
+		}
+	}
+
+	return &discordgo.ApplicationCommand{
+		Name:        "editwar",
+		Description: "Correct an imported war without re-importing it (officer role required)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "details",
+				Description: "Change the result, type, tier or label of a war",
+				Options: []*discordgo.ApplicationCommandOption{
+					warChoice(),
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "result",
+						Description: "War result",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Win", Value: "win"},
+							{Name: "Lose", Value: "lose"},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "war_type",
+						Description: "Type of war",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Node War", Value: "node"},
+							{Name: "Siege", Value: "siege"},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "tier",
+						Description: "War tier",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Tier 1", Value: "1"},
+							{Name: "Tier 2", Value: "2"},
+							{Name: "Uncapped", Value: "uncapped"},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "label",
+						Description: "New label for the war",
+						Required:    false,
+						MaxLength:   maxWarLabelLength,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "clear_label",
+						Description: "Remove the war's label",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "addline",
+				Description: "Add a member missing from a war",
+				Options: append([]*discordgo.ApplicationCommandOption{
+					warChoice(),
+					countOption("kills", "Kills", true),
+					countOption("deaths", "Deaths", true),
+				}, memberOptions()...),
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "setline",
+				Description: "Correct the kills, deaths or linked member of a line",
+				Options: append([]*discordgo.ApplicationCommandOption{
+					warChoice(),
+					lineChoice(),
+					countOption("kills", "Corrected kills", false),
+					countOption("deaths", "Corrected deaths", false),
+				}, memberOptions()...),
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "removeline",
+				Description: "Remove a line from a war",
+				Options: []*discordgo.ApplicationCommandOption{
+					warChoice(),
+					lineChoice(),
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "relink",
+				Description: "Link every line with an imported name to a roster member",
+				Options: append([]*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "ocr_name",
+						Description:  "Imported name to link, unmatched or linked to the wrong member",
+						Required:     true,
+						Autocomplete: true,
+					},
+				}, memberOptions()...),
+			},
+		},
+	}
+}
+
+func handleEditWar(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	if !hasOfficerPermission(s, i, cfg) {
+		discord.RespondEphemeral(s, i, "You need officer role or admin permission to use this command.")
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		discord.RespondEphemeral(s, i, "Please choose what to edit.")
+		return
+	}
+
+	subcommand := options[0]
+	switch subcommand.Name {
+	case "details":
+		handleEditWarDetails(s, i, dbx, cfg, subcommand.Options)
+	case "addline":
+		handleEditWarAddLine(s, i, dbx, cfg, subcommand.Options)
+	case "setline":
+		handleEditWarSetLine(s, i, dbx, cfg, subcommand.Options)
+	case "removeline":
+		handleEditWarRemoveLine(s, i, dbx, cfg, subcommand.Options)
+	case "relink":
+		handleEditWarRelink(s, i, dbx, subcommand.Options)
+	default:
+		discord.RespondEphemeral(s, i, "Unknown subcommand.")
+	}
+}
+
+// stringOption returns the trimmed value of a string option, empty when absent
+func stringOption(options []*discordgo.ApplicationCommandInteractionDataOption, name string) string {
+	if opt := findOption(options, name); opt != nil {
+		return strings.TrimSpace(opt.StringValue())
+	}
+	return ""
+}
+
+// intOption returns the value of an integer option, nil when absent
+func intOption(options []*discordgo.ApplicationCommandInteractionDataOption, name string) *int {
+	if opt := findOption(options, name); opt != nil {
+		value := int(opt.IntValue())
+		return &value
+	}
+	return nil
+}
+
+// formatWarLine describes a war line for messages and autocomplete choices
+func formatWarLine(line db.WarLine) string {
+	name := line.OCRName
+	switch {
+	case line.MemberID == 0:
+		name += " (unmatched)"
+	case !strings.EqualFold(line.FamilyName, line.OCRName):
+		name += " → " + line.FamilyName
+	}
+	return fmt.Sprintf("%s: %d/%d", name, line.Kills, line.Deaths)
+}
+
+// editWarError responds to a failed war edit
+func editWarError(s *discordgo.Session, i *discordgo.InteractionCreate, war *db.War, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		discord.RespondEphemeral(s, i, fmt.Sprintf("Line not found in war %s.", formatWarName(*war)))
+	case errors.Is(err, db.ErrWarLineExists):
+		discord.RespondEphemeral(s, i, fmt.Sprintf("That member already has a line in war %s. Edit or remove it instead.", formatWarName(*war)))
+	default:
+		log.Printf("editwar error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to edit war. Please try again.")
+	}
+}
+
+// resolveWarLineID parses the line option of a war edit
+func resolveWarLineID(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) (int64, bool) {
+	lineID, err := strconv.ParseInt(stringOption(options, "line"), 10, 64)
+	if err != nil || lineID <= 0 {
+		discord.RespondEphemeral(s, i, "Please choose a line from the list.")
+		return 0, false
+	}
+	return lineID, true
+}
+
+func handleEditWarDetails(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	war, ok := resolveWar(s, i, dbx, cfg, stringOption(options, "war"), "")
+	if !ok {
+		return
+	}
+
+	var fields db.WarUpdateFields
+	var changes []string
+
+	if result := stringOption(options, "result"); result != "" {
+		fields.Result = &result
+		changes = append(changes, "result: "+strings.Title(result))
+	}
+	if warType := stringOption(options, "war_type"); warType != "" {
+		fields.WarType = &warType
+		changes = append(changes, "type: "+strings.Title(warType))
+	}
+	if tier := stringOption(options, "tier"); tier != "" {
+		fields.Tier = &tier
+		changes = append(changes, "tier: "+tier)
+	}
+
+	label := stringOption(options, "label")
+	clearLabel := false
+	if opt := findOption(options, "clear_label"); opt != nil {
+		clearLabel = opt.BoolValue()
+	}
+	if label != "" && clearLabel {
+		discord.RespondEphemeral(s, i, "Provide either a new label or clear_label, not both.")
+		return
+	}
+	if label != "" || clearLabel {
+		fields.Label = &label
+		if clearLabel {
+			changes = append(changes, "label removed")
+		} else {
+			changes = append(changes, "label: "+label)
+		}
+	}
+
+	if len(changes) == 0 {
+		discord.RespondEphemeral(s, i, "Please provide at least one field to change.")
+		return
+	}
+
+	updated, err := db.UpdateWar(dbx, i.GuildID, war.ID, fields)
+	if err != nil {
+		editWarError(s, i, war, err)
+		return
+	}
+
+	discord.RespondText(s, i, fmt.Sprintf("Updated war %s (%s).", formatWarName(*updated), strings.Join(changes, ", ")))
+}
+
+func handleEditWarAddLine(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	war, ok := resolveWar(s, i, dbx, cfg, stringOption(options, "war"), "")
+	if !ok {
+		return
+	}
+
+	m, err := findMemberOption(s, i, dbx, options)
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, "Member not found.")
+		return
+	} else if err != nil {
+		log.Printf("editwar member lookup error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to edit war. Please try again.")
+		return
+	}
+	if m == nil {
+		discord.RespondEphemeral(s, i, "Please provide either a Discord member or family name.")
+		return
+	}
+
+	kills, deaths := intOption(options, "kills"), intOption(options, "deaths")
+	if kills == nil || deaths == nil {
+		discord.RespondEphemeral(s, i, "Kills and deaths are required.")
+		return
+	}
+
+	if err := db.AddWarLine(dbx, i.GuildID, war.ID, m.ID, m.FamilyName, *kills, *deaths); err != nil {
+		editWarError(s, i, war, err)
+		return
+	}
+
+	discord.RespondText(s, i, fmt.Sprintf("Added %s (%d/%d) to war %s.", m.FamilyName, *kills, *deaths, formatWarName(*war)))
+}
+
+func handleEditWarSetLine(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	war, ok := resolveWar(s, i, dbx, cfg, stringOption(options, "war"), "")
+	if !ok {
+		return
+	}
+
+	lineID, ok := resolveWarLineID(s, i, options)
+	if !ok {
+		return
+	}
+
+	fields := db.WarLineFields{
+		Kills:  intOption(options, "kills"),
+		Deaths: intOption(options, "deaths"),
+	}
+
+	m, err := findMemberOption(s, i, dbx, options)
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, "Member not found.")
+		return
+	} else if err != nil {
+		log.Printf("editwar member lookup error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to edit war. Please try again.")
+		return
+	}
+	if m != nil {
+		fields.MemberID = &m.ID
+		fields.FamilyName = m.FamilyName
+	}
+
+	if fields.Kills == nil && fields.Deaths == nil && fields.MemberID == nil {
+		discord.RespondEphemeral(s, i, "Please provide kills, deaths or a member to link.")
+		return
+	}
+
+	line, err := db.UpdateWarLine(dbx, i.GuildID, war.ID, lineID, fields)
+	if err != nil {
+		editWarError(s, i, war, err)
+		return
+	}
+
+	discord.RespondText(s, i, fmt.Sprintf("Updated line in war %s: %s.", formatWarName(*war), formatWarLine(*line)))
+}
+
+func handleEditWarRemoveLine(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	war, ok := resolveWar(s, i, dbx, cfg, stringOption(options, "war"), "")
+	if !ok {
+		return
+	}
+
+	lineID, ok := resolveWarLineID(s, i, options)
+	if !ok {
+		return
+	}
+
+	line, err := db.RemoveWarLine(dbx, i.GuildID, war.ID, lineID)
+	if err != nil {
+		editWarError(s, i, war, err)
+		return
+	}
+
+	discord.RespondText(s, i, fmt.Sprintf("Removed %s from war %s.", formatWarLine(*line), formatWarName(*war)))
+}
+
+func handleEditWarRelink(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	ocrName := stringOption(options, "ocr_name")
+	if ocrName == "" {
+		discord.RespondEphemeral(s, i, "Imported name is required.")
+		return
+	}
+
+	m, err := findMemberOption(s, i, dbx, options)
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, "Member not found.")
+		return
+	} else if err != nil {
+		log.Printf("editwar member lookup error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to relink lines. Please try again.")
+		return
+	}
+	if m == nil {
+		discord.RespondEphemeral(s, i, "Please provide either a Discord member or family name.")
+		return
+	}
+
+	relinked, skipped, err := db.RelinkOCRName(dbx, i.GuildID, ocrName, m.ID, m.FamilyName)
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("No lines named '%s' found.", ocrName))
+		return
+	} else if err != nil {
+		log.Printf("editwar relink error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to relink lines. Please try again.")
+		return
+	}
+
+	msg := fmt.Sprintf("Linked %d line(s) named '%s' to %s.", relinked, ocrName, m.FamilyName)
+	if skipped > 0 {
+		msg += fmt.Sprintf(" Skipped %d war(s) where %s already has a line; fix those with /editwar setline or removeline.", skipped, m.FamilyName)
+	}
+	discord.RespondText(s, i, msg)
+}
+
+// handleWarLineAutocomplete suggests lines of the war chosen in the same command
+func handleWarLineAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, warRef, search string) {
+	warID, ok := parseWarID(warRef)
+	if !ok || !hasOfficerPermission(s, i, cfg) {
+		discord.RespondAutocomplete(s, i, nil)
+		return
+	}
+
+	lines, err := db.GetWarLines(dbx, i.GuildID, warID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("war line autocomplete error: %v", err)
+		}
+		discord.RespondAutocomplete(s, i, nil)
+		return
+	}
+
+	search = strings.ToLower(strings.TrimSpace(search))
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, maxAutocompleteChoices)
+	for _, line := range lines {
+		if len(choices) == maxAutocompleteChoices {
+			break
+		}
+		if search != "" && !strings.Contains(strings.ToLower(line.OCRName), search) && !strings.Contains(strings.ToLower(line.FamilyName), search) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncateString(formatWarLine(line), 100),
+			Value: strconv.FormatInt(line.ID, 10),
+		})
+	}
+
+	discord.RespondAutocomplete(s, i, choices)
+}
+
+// handleOCRNameAutocomplete suggests imported names, names with unmatched lines first
+func handleOCRNameAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, search string) {
+	if !hasOfficerPermission(s, i, cfg) {
+		discord.RespondAutocomplete(s, i, nil)
+		return
+	}
+
+	names, err := db.SearchWarLineOCRNames(dbx, i.GuildID, strings.TrimSpace(search), maxAutocompleteChoices)
+	if err != nil {
+		log.Printf("ocr name autocomplete error: %v", err)
+		discord.RespondAutocomplete(s, i, nil)
+		return
+	}
+
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(names))
+	for _, name := range names {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncateString(name, 100),
+			Value: name,
+		})
+	}
+
+	discord.RespondAutocomplete(s, i, choices)
+}
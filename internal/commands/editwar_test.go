@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal/db"
+)
+
+func TestFormatWarLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     db.WarLine
+		expected string
+	}{
+		{
+			name:     "unmatched",
+			line:     db.WarLine{OCRName: "Hammlty", Kills: 10, Deaths: 5},
+			expected: "Hammlty (unmatched): 10/5",
+		},
+		{
+			name:     "matched same name",
+			line:     db.WarLine{OCRName: "Hammity", Kills: 3, Deaths: 0, MemberID: 4, FamilyName: "hammity"},
+			expected: "Hammity: 3/0",
+		},
+		{
+			name:     "matched other name",
+			line:     db.WarLine{OCRName: "Hammlty", Kills: 10, Deaths: 5, MemberID: 4, FamilyName: "Hammity"},
+			expected: "Hammlty → Hammity: 10/5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatWarLine(tt.line); got != tt.expected {
+				t.Errorf("formatWarLine() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindOption(t *testing.T) {
+	options := []*discordgo.ApplicationCommandInteractionDataOption{
+		{
+			Name: "setline",
+			Type: discordgo.ApplicationCommandOptionSubCommand,
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "war", Type: discordgo.ApplicationCommandOptionString, Value: "12"},
+				{Name: "line", Type: discordgo.ApplicationCommandOptionString, Value: "40", Focused: true},
+			},
+		},
+	}
+
+	if opt := findOption(options, "war"); opt == nil || opt.StringValue() != "12" {
+		t.Errorf("findOption(war) = %v, expected option with value 12", opt)
+	}
+	if opt := findOption(options, "setline"); opt == nil || opt.Type != discordgo.ApplicationCommandOptionSubCommand {
+		t.Errorf("findOption(setline) = %v, expected subcommand", opt)
+	}
+	if opt := findOption(options, "kills"); opt != nil {
+		t.Errorf("findOption(kills) = %v, expected nil", opt)
+	}
+	if got := stringOption(options, "line"); got != "40" {
+		t.Errorf("stringOption(line) = %q, expected %q", got, "40")
+	}
+	if got := intOption(options, "kills"); got != nil {
+		t.Errorf("intOption(kills) = %d, expected nil", *got)
+	}
+}
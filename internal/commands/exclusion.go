@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/discord"
+)
+
+func exclusionCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "exclusion",
+		Description: "Exclude members from attendance or war stats for a period (officer role required)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Exclude a member from attendance and/or war stats between two dates",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "start_date",
+						Description: "Exclusion start date (DD-MM-YY)",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "end_date",
+						Description: "Exclusion end date (DD-MM-YY)",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "reason",
+						Description: "Why the member is excluded, e.g. new-player onboarding",
+						Required:    true,
+						MaxLength:   maxVacationReasonLength,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "scope",
+						Description: "What the member is excluded from (default: both)",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Attendance and war stats", Value: db.ExclusionScopeAll},
+							{Name: "Attendance only", Value: db.ExclusionScopeAttendance},
+							{Name: "War stats (K/D) only", Value: db.ExclusionScopeStats},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Name:        "member",
+						Description: "Discord member",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "family_name",
+						Description: "Family name of the member",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List current and upcoming exclusions",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Remove an exclusion",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "exclusion",
+						Description:  "Exclusion to remove",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func handleExclusion(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	if !hasOfficerPermission(s, i, cfg) {
+		discord.RespondEphemeral(s, i, "You need officer role or admin permission to use this command.")
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		discord.RespondEphemeral(s, i, "Please choose add, list or remove.")
+		return
+	}
+
+	subcommand := options[0]
+	switch subcommand.Name {
+	case "add":
+		handleExclusionAdd(s, i, dbx, cfg, subcommand.Options)
+	case "list":
+		handleExclusionList(s, i, dbx, cfg)
+	case "remove":
+		handleExclusionRemove(s, i, dbx, subcommand.Options)
+	default:
+		discord.RespondEphemeral(s, i, "Unknown subcommand.")
+	}
+}
+
+// formatExclusionScope describes what an exclusion applies to
+func formatExclusionScope(scope string) string {
+	switch scope {
+	case db.ExclusionScopeAttendance:
+		return "attendance"
+	case db.ExclusionScopeStats:
+		return "war stats"
+	default:
+		return "attendance and war stats"
+	}
+}
+
+// formatExclusion describes an exclusion for messages and autocomplete choices
+func formatExclusion(e db.Exclusion) string {
+	text := fmt.Sprintf("#%d %s: %s to %s, excluded from %s", e.ID, e.FamilyName,
+		e.StartDate.Format("02-01-06"), e.EndDate.Format("02-01-06"), formatExclusionScope(e.Scope))
+	if e.Reason != "" {
+		text += " - " + e.Reason
+	}
+	return text
+}
+
+func handleExclusionAdd(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	startDate, endDate, problem := parseDateRange(stringOption(options, "start_date"), stringOption(options, "end_date"), cfg.Location())
+	if problem != "" {
+		discord.RespondEphemeral(s, i, problem)
+		return
+	}
+
+	reason := stringOption(options, "reason")
+	if reason == "" {
+		discord.RespondEphemeral(s, i, "Reason is required.")
+		return
+	}
+
+	scope := stringOption(options, "scope")
+	if scope == "" {
+		scope = db.ExclusionScopeAll
+	}
+
+	m, err := findMemberOption(s, i, dbx, options)
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, "Member not found.")
+		return
+	} else if err != nil {
+		log.Printf("exclusion lookup error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to add exclusion. Please try again.")
+		return
+	}
+	if m == nil {
+		discord.RespondEphemeral(s, i, "Please provide either a Discord member or family name.")
+		return
+	}
+
+	id, err := db.CreateExclusion(dbx, i.GuildID, m.ID, scope, startDate, endDate, reason, i.Member.User.ID)
+	if err != nil {
+		log.Printf("exclusion add error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to add exclusion. Please try again.")
+		return
+	}
+
+	exclusion := db.Exclusion{ID: id, FamilyName: m.FamilyName, Scope: scope, StartDate: startDate, EndDate: endDate, Reason: reason}
+	discord.RespondText(s, i, fmt.Sprintf("Added exclusion %s.", formatExclusion(exclusion)))
+}
+
+func handleExclusionList(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	exclusions, err := db.GetActiveExclusions(dbx, i.GuildID, time.Now().In(cfg.Location()))
+	if err != nil {
+		log.Printf("exclusion list error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve exclusions. Please try again.")
+		return
+	}
+
+	if len(exclusions) == 0 {
+		discord.RespondEphemeral(s, i, "No current or upcoming exclusions.")
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("**Current and upcoming exclusions (%d)**\n", len(exclusions)))
+	for _, e := range exclusions {
+		b.WriteString("• " + formatExclusion(e) + "\n")
+	}
+
+	// Keep within Discord's 2000 character message limit
+	discord.RespondEphemeral(s, i, truncateString(b.String(), 2000))
+}
+
+func handleExclusionRemove(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	id, ok := parseIDOption(stringOption(options, "exclusion"))
+	if !ok {
+		discord.RespondEphemeral(s, i, "Please pick an exclusion from the list.")
+		return
+	}
+
+	deleted, err := db.DeleteExclusion(dbx, i.GuildID, id)
+	if err != nil {
+		log.Printf("exclusion remove error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to remove exclusion. Please try again.")
+		return
+	}
+	if !deleted {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("Exclusion #%d not found.", id))
+		return
+	}
+
+	discord.RespondText(s, i, fmt.Sprintf("Exclusion #%d removed.", id))
+}
+
+// handleExclusionAutocomplete suggests the guild's current and upcoming exclusions by ID
+func handleExclusionAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, search string) {
+	if !hasOfficerPermission(s, i, cfg) {
+		discord.RespondAutocomplete(s, i, nil)
+		return
+	}
+
+	exclusions, err := db.GetActiveExclusions(dbx, i.GuildID, time.Now().In(cfg.Location()))
+	if err != nil {
+		log.Printf("exclusion autocomplete error: %v", err)
+		discord.RespondAutocomplete(s, i, nil)
+		return
+	}
+
+	search = strings.ToLower(strings.TrimSpace(search))
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, maxAutocompleteChoices)
+	for _, e := range exclusions {
+		name := formatExclusion(e)
+		if search != "" && !strings.Contains(strings.ToLower(name), search) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncateString(name, 100),
+			Value: strconv.FormatInt(e.ID, 10),
+		})
+		if len(choices) == maxAutocompleteChoices {
+			break
+		}
+	}
+
+	discord.RespondAutocomplete(s, i, choices)
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"PanickedBot/internal/db"
+)
+
+func TestFormatExclusion(t *testing.T) {
+	start := time.Date(2024, 12, 2, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 12, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		exclusion db.Exclusion
+		expected  string
+	}{
+		{
+			name:      "attendance",
+			exclusion: db.Exclusion{ID: 4, FamilyName: "Newbie", Scope: db.ExclusionScopeAttendance, StartDate: start, EndDate: end, Reason: "New-player onboarding"},
+			expected:  "#4 Newbie: 02-12-24 to 15-12-24, excluded from attendance - New-player onboarding",
+		},
+		{
+			name:      "stats",
+			exclusion: db.Exclusion{ID: 5, FamilyName: "Hammity", Scope: db.ExclusionScopeStats, StartDate: start, EndDate: end, Reason: "Testing a new class"},
+			expected:  "#5 Hammity: 02-12-24 to 15-12-24, excluded from war stats - Testing a new class",
+		},
+		{
+			name:      "all without reason",
+			exclusion: db.Exclusion{ID: 6, FamilyName: "Zephyr", Scope: db.ExclusionScopeAll, StartDate: start, EndDate: end},
+			expected:  "#6 Zephyr: 02-12-24 to 15-12-24, excluded from attendance and war stats",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatExclusion(tt.exclusion); got != tt.expected {
+				t.Errorf("formatExclusion() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
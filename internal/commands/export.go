@@ -0,0 +1,363 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal"
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/discord"
+)
+
+// Data sets /export can write
+const (
+	exportRoster     = "roster"
+	exportWarStats   = "warstats"
+	exportWarResults = "warresults"
+	exportWarLines   = "warlines"
+)
+
+// Export file formats
+const (
+	exportCSV  = "csv"
+	exportJSON = "json"
+)
+
+// exportDateFormat is the date format of exported files, which spreadsheets recognise
+const exportDateFormat = "2006-01-02"
+
+func exportCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "export",
+		Description: "Download roster or war data as a CSV or JSON file (officer role required)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "data",
+				Description: "Data to export",
+				Required:    true,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Roster", Value: exportRoster},
+					{Name: "War stats per member", Value: exportWarStats},
+					{Name: "War results", Value: exportWarResults},
+					{Name: "War lines", Value: exportWarLines},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "format",
+				Description: "File format (default: CSV)",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "CSV", Value: exportCSV},
+					{Name: "JSON", Value: exportJSON},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "from",
+				Description: "War lines only: first war date in DD-MM-YY format",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "to",
+				Description: "War lines only: last war date in DD-MM-YY format",
+				Required:    false,
+			},
+		},
+	}
+}
+
+// exportTable is the data of an export before it is written as CSV or JSON.
+// Values are strings, numbers, bools, []string, time.Time or nil for missing values.
+type exportTable struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// exportValue converts an optional value to a table value, nil when missing
+func exportValue[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// exportKD calculates a K/D ratio rounded to two decimals, like /warstats shows it
+func exportKD(kills, deaths int) float64 {
+	kd := float64(kills)
+	if deaths > 0 {
+		kd = float64(kills) / float64(deaths)
+	}
+	return math.Round(kd*100) / 100
+}
+
+// rosterExport lists every roster member with all stats, flags and teams
+func rosterExport(members []internal.Member, teams map[int64][]string, loc *time.Location) exportTable {
+	table := exportTable{Columns: []string{
+		"id", "family_name", "discord_user_id", "display_name", "class", "spec",
+		"ap", "aap", "dp", "gs", "evasion", "dr", "drr", "accuracy", "hp", "total_ap", "total_aap",
+		"gear_verified_at", "meets_cap", "meets_cap_override", "is_exception", "is_mercenary", "is_active",
+		"teams", "joined_at",
+	}}
+
+	for _, m := range members {
+		var verifiedAt any
+		if m.GearVerifiedAt != nil {
+			verifiedAt = m.GearVerifiedAt.In(loc)
+		}
+
+		memberTeams := teams[m.ID]
+		if memberTeams == nil {
+			memberTeams = []string{}
+		}
+
+		table.Rows = append(table.Rows, []any{
+			m.ID, m.FamilyName, exportValue(m.DiscordUserID), exportValue(m.DisplayName), exportValue(m.Class), exportValue(m.Spec),
+			exportValue(m.AP), exportValue(m.AAP), exportValue(m.DP), calculateGS(m.AP, m.AAP, m.DP),
+			exportValue(m.Evasion), exportValue(m.DR), exportValue(m.DRR), exportValue(m.Accuracy), exportValue(m.HP),
+			exportValue(m.TotalAP), exportValue(m.TotalAAP),
+			verifiedAt, m.MeetsCap, exportValue(m.CapOverride), m.IsException, m.IsMercenary, m.IsActive,
+			memberTeams, m.CreatedAt.In(loc),
+		})
+	}
+	return table
+}
+
+// warStatsExport lists the war totals of every member
+func warStatsExport(stats []db.WarStats) exportTable {
+	table := exportTable{Columns: []string{"family_name", "wars", "most_recent_war", "kills", "deaths", "kd"}}
+	for _, stat := range stats {
+		table.Rows = append(table.Rows, []any{
+			stat.FamilyName, stat.TotalWars, exportValue(stat.MostRecentWar),
+			stat.TotalKills, stat.TotalDeaths, exportKD(stat.TotalKills, stat.TotalDeaths),
+		})
+	}
+	return table
+}
+
+// warResultsExport lists the result and totals of every war
+func warResultsExport(results []db.WarResult) exportTable {
+	table := exportTable{Columns: []string{"war_id", "war_date", "label", "result", "excluded", "kills", "deaths", "kd"}}
+	for _, result := range results {
+		table.Rows = append(table.Rows, []any{
+			result.ID, result.WarDate, result.Label, result.Result, result.IsExcluded,
+			result.TotalKills, result.TotalDeaths, exportKD(result.TotalKills, result.TotalDeaths),
+		})
+	}
+	return table
+}
+
+// warLinesExport lists every scoreboard line of the given wars
+func warLinesExport(lines []db.ExportWarLine) exportTable {
+	table := exportTable{Columns: []string{
+		"war_id", "war_date", "label", "result", "war_type", "tier", "excluded",
+		"line_id", "name", "member_id", "family_name", "class", "spec", "kills", "deaths",
+	}}
+	for _, l := range lines {
+		var memberID, familyName, class, spec any
+		if l.Line.MemberID != 0 {
+			memberID, familyName = l.Line.MemberID, l.Line.FamilyName
+		}
+		if l.Class != "" {
+			class = l.Class
+		}
+		if l.Spec != "" {
+			spec = l.Spec
+		}
+
+		table.Rows = append(table.Rows, []any{
+			l.War.ID, l.War.WarDate, l.War.Label, l.War.Result, l.War.WarType, l.War.Tier, l.War.IsExcluded,
+			l.Line.ID, l.Line.OCRName, memberID, familyName, class, spec, l.Line.Kills, l.Line.Deaths,
+		})
+	}
+	return table
+}
+
+// csvValue formats a table value for a CSV cell; missing values are left empty
+func csvValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return csvText(v)
+	case []string:
+		return csvText(strings.Join(v, "; "))
+	case time.Time:
+		return v.Format(exportDateFormat)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// csvText quotes text that spreadsheet programs would run as a formula, such as
+// a family name or label starting with "=", so exports are safe to open
+func csvText(v string) string {
+	if v != "" && strings.ContainsRune("=+-@", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+// writeCSV writes the table as CSV with a header row
+func (t exportTable) writeCSV() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(t.Columns); err != nil {
+		return nil, err
+	}
+	for _, row := range t.Rows {
+		record := make([]string, len(row))
+		for idx, v := range row {
+			record[idx] = csvValue(v)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// writeJSON writes the table as a JSON array with one object per row; missing values are null
+func (t exportTable) writeJSON() ([]byte, error) {
+	objects := make([]map[string]any, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		object := make(map[string]any, len(t.Columns))
+		for idx, column := range t.Columns {
+			v := row[idx]
+			if date, ok := v.(time.Time); ok {
+				v = date.Format(exportDateFormat)
+			}
+			object[column] = v
+		}
+		objects = append(objects, object)
+	}
+	return json.MarshalIndent(objects, "", "  ")
+}
+
+func handleExport(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	if !hasOfficerPermission(s, i, cfg) {
+		discord.RespondEphemeral(s, i, "You need officer role or admin permission to use this command.")
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	data := stringOption(options, "data")
+	format := stringOption(options, "format")
+	if format == "" {
+		format = exportCSV
+	}
+	fromStr := stringOption(options, "from")
+	toStr := stringOption(options, "to")
+
+	loc := cfg.Location()
+	var from, to time.Time
+	if data == exportWarLines {
+		var problem string
+		from, to, problem = parseOpenDateRange(fromStr, toStr, loc)
+		if problem != "" {
+			discord.RespondEphemeral(s, i, problem)
+			return
+		}
+	} else if fromStr != "" || toStr != "" {
+		discord.RespondEphemeral(s, i, "The from and to dates only apply to war lines.")
+		return
+	}
+
+	// Large guilds can take a while to query and upload
+	if err := discord.DeferEphemeral(s, i); err != nil {
+		log.Printf("export defer error: %v", err)
+		return
+	}
+
+	var table exportTable
+	switch data {
+	case exportRoster:
+		members, err := internal.GetRosterMembers(dbx, i.GuildID, internal.RosterFilter{IncludeMercs: true, IncludeInactive: true})
+		if err != nil {
+			log.Printf("export roster error: %v", err)
+			_ = discord.FollowUpEphemeral(s, i, "Failed to retrieve roster members. Please try again.")
+			return
+		}
+		teams, err := db.GetGuildMemberTeamNames(dbx, i.GuildID)
+		if err != nil {
+			log.Printf("export teams error: %v", err)
+			_ = discord.FollowUpEphemeral(s, i, "Failed to retrieve teams. Please try again.")
+			return
+		}
+		table = rosterExport(members, teams, loc)
+
+	case exportWarStats:
+		stats, err := db.GetWarStats(dbx, i.GuildID, true, true, "", db.WarFilter{})
+		if err != nil {
+			log.Printf("export war stats error: %v", err)
+			_ = discord.FollowUpEphemeral(s, i, "Failed to retrieve war statistics. Please try again.")
+			return
+		}
+		table = warStatsExport(stats)
+
+	case exportWarResults:
+		results, err := db.GetWarResults(dbx, i.GuildID, db.WarFilter{})
+		if err != nil {
+			log.Printf("export war results error: %v", err)
+			_ = discord.FollowUpEphemeral(s, i, "Failed to retrieve war results. Please try again.")
+			return
+		}
+		table = warResultsExport(results)
+
+	case exportWarLines:
+		lines, err := db.GetWarLinesInRange(dbx, i.GuildID, from, to)
+		if err != nil {
+			log.Printf("export war lines error: %v", err)
+			_ = discord.FollowUpEphemeral(s, i, "Failed to retrieve war lines. Please try again.")
+			return
+		}
+		table = warLinesExport(lines)
+
+	default:
+		_ = discord.FollowUpEphemeral(s, i, "Unknown data to export.")
+		return
+	}
+
+	if len(table.Rows) == 0 {
+		_ = discord.FollowUpEphemeral(s, i, "There is no data to export.")
+		return
+	}
+
+	var content []byte
+	var err error
+	contentType := "text/csv"
+	if format == exportJSON {
+		content, err = table.writeJSON()
+		contentType = "application/json"
+	} else {
+		content, err = table.writeCSV()
+	}
+	if err != nil {
+		log.Printf("export write error: %v", err)
+		_ = discord.FollowUpEphemeral(s, i, "Failed to create the export file. Please try again.")
+		return
+	}
+
+	filename := fmt.Sprintf("%s_%s.%s", data, time.Now().In(loc).Format(exportDateFormat), format)
+	msg := fmt.Sprintf("📄 Exported %d rows.", len(table.Rows))
+	if err := discord.FollowUpFile(s, i, msg, filename, contentType, content); err != nil {
+		log.Printf("export upload error: %v", err)
+		_ = discord.FollowUpEphemeral(s, i, "Failed to upload the export file. Please try again.")
+	}
+}
@@ -0,0 +1,199 @@
+package commands
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"PanickedBot/internal"
+	"PanickedBot/internal/db"
+)
+
+func TestExportKD(t *testing.T) {
+	tests := []struct {
+		name   string
+		kills  int
+		deaths int
+		want   float64
+	}{
+		{"rounded to two decimals", 10, 3, 3.33},
+		{"no deaths", 7, 0, 7},
+		{"no kills or deaths", 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exportKD(tt.kills, tt.deaths); got != tt.want {
+				t.Errorf("exportKD(%d, %d) = %v, want %v", tt.kills, tt.deaths, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"missing", nil, ""},
+		{"string", "Kethrya", "Kethrya"},
+		{"int", 312, "312"},
+		{"int64", int64(42), "42"},
+		{"float", 1.5, "1.5"},
+		{"bool", true, "true"},
+		{"date", time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC), "2024-12-25"},
+		{"list", []string{"Alpha", "Bravo"}, "Alpha; Bravo"},
+		{"empty list", []string{}, ""},
+		{"formula", "=HYPERLINK(\"x\")", "'=HYPERLINK(\"x\")"},
+		{"plus", "+1", "'+1"},
+		{"minus", "-2+3", "'-2+3"},
+		{"at", "@SUM(A1)", "'@SUM(A1)"},
+		{"formula in list", []string{"=1+1", "Bravo"}, "'=1+1; Bravo"},
+		{"negative number", -3, "-3"},
+		{"empty string", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvValue(tt.value); got != tt.want {
+				t.Errorf("csvValue(%v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportTableWriteCSV(t *testing.T) {
+	table := exportTable{
+		Columns: []string{"family_name", "ap", "teams"},
+		Rows: [][]any{
+			{"Kethrya", 312, []string{"Alpha", "Bravo"}},
+			{"Comma, Name", nil, []string{}},
+		},
+	}
+
+	got, err := table.writeCSV()
+	if err != nil {
+		t.Fatalf("writeCSV() error = %v", err)
+	}
+
+	want := "family_name,ap,teams\nKethrya,312,Alpha; Bravo\n\"Comma, Name\",,\n"
+	if string(got) != want {
+		t.Errorf("writeCSV() = %q, want %q", got, want)
+	}
+}
+
+func TestExportTableWriteJSON(t *testing.T) {
+	table := exportTable{
+		Columns: []string{"family_name", "ap", "joined_at", "teams"},
+		Rows: [][]any{
+			{"Kethrya", 312, time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC), []string{"Alpha"}},
+			{"Panicked", nil, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), []string{}},
+		},
+	}
+
+	got, err := table.writeJSON()
+	if err != nil {
+		t.Fatalf("writeJSON() error = %v", err)
+	}
+
+	var objects []map[string]any
+	if err := json.Unmarshal(got, &objects); err != nil {
+		t.Fatalf("writeJSON() wrote invalid JSON: %v", err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("writeJSON() wrote %d objects, want 2", len(objects))
+	}
+
+	first := objects[0]
+	if first["family_name"] != "Kethrya" || first["ap"] != float64(312) || first["joined_at"] != "2024-12-25" {
+		t.Errorf("writeJSON() first object = %v", first)
+	}
+	if teams, ok := first["teams"].([]any); !ok || len(teams) != 1 || teams[0] != "Alpha" {
+		t.Errorf("writeJSON() teams = %v, want [Alpha]", first["teams"])
+	}
+
+	second := objects[1]
+	if ap, ok := second["ap"]; !ok || ap != nil {
+		t.Errorf("writeJSON() missing ap = %v, want null", ap)
+	}
+	if teams, ok := second["teams"].([]any); !ok || len(teams) != 0 {
+		t.Errorf("writeJSON() empty teams = %v, want []", second["teams"])
+	}
+}
+
+func TestRosterExport(t *testing.T) {
+	loc := time.UTC
+	verified := time.Date(2024, 12, 20, 18, 0, 0, 0, time.UTC)
+	members := []internal.Member{
+		{ID: 1, FamilyName: "Kethrya", AP: intPtr(300), AAP: intPtr(310), DP: intPtr(400), GearVerifiedAt: &verified, MeetsCap: true, IsActive: true},
+		{ID: 2, FamilyName: "Panicked", IsMercenary: true},
+	}
+	teams := map[int64][]string{1: {"Alpha", "Bravo"}}
+
+	table := rosterExport(members, teams, loc)
+	if len(table.Rows) != 2 {
+		t.Fatalf("rosterExport() rows = %d, want 2", len(table.Rows))
+	}
+
+	column := func(row []any, name string) any {
+		for idx, c := range table.Columns {
+			if c == name {
+				return row[idx]
+			}
+		}
+		t.Fatalf("rosterExport() has no %s column", name)
+		return nil
+	}
+
+	for _, row := range table.Rows {
+		if len(row) != len(table.Columns) {
+			t.Fatalf("rosterExport() row has %d values for %d columns", len(row), len(table.Columns))
+		}
+	}
+
+	first, second := table.Rows[0], table.Rows[1]
+	if got := column(first, "gs"); got != calculateGS(intPtr(300), intPtr(310), intPtr(400)) {
+		t.Errorf("gs = %v", got)
+	}
+	if got := csvValue(column(first, "teams")); got != "Alpha; Bravo" {
+		t.Errorf("teams = %q, want %q", got, "Alpha; Bravo")
+	}
+	if got := csvValue(column(first, "gear_verified_at")); got != "2024-12-20" {
+		t.Errorf("gear_verified_at = %q, want 2024-12-20", got)
+	}
+	if got := column(second, "ap"); got != nil {
+		t.Errorf("missing ap = %v, want nil", got)
+	}
+	if got := column(second, "gear_verified_at"); got != nil {
+		t.Errorf("unverified gear_verified_at = %v, want nil", got)
+	}
+	if got := column(second, "is_mercenary"); got != true {
+		t.Errorf("is_mercenary = %v, want true", got)
+	}
+}
+
+func TestWarLinesExport(t *testing.T) {
+	war := db.War{ID: 7, WarDate: time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC), Result: "win"}
+	lines := []db.ExportWarLine{
+		{War: war, Line: db.WarLine{ID: 1, OCRName: "Kethrya", Kills: 10, Deaths: 2, MemberID: 3, FamilyName: "Kethrya"}, Class: "Warrior", Spec: "awakening"},
+		{War: war, Line: db.WarLine{ID: 2, OCRName: "Unknown", Kills: 1, Deaths: 5}},
+	}
+
+	table := warLinesExport(lines)
+	csvOut, err := table.writeCSV()
+	if err != nil {
+		t.Fatalf("writeCSV() error = %v", err)
+	}
+
+	got := strings.Split(strings.TrimSpace(string(csvOut)), "\n")
+	want := []string{
+		"war_id,war_date,label,result,war_type,tier,excluded,line_id,name,member_id,family_name,class,spec,kills,deaths",
+		"7,2024-12-25,,win,,,false,1,Kethrya,3,Kethrya,Warrior,awakening,10,2",
+		"7,2024-12-25,,win,,,false,2,Unknown,,,,,1,5",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("warLinesExport() CSV =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
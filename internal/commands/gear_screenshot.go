@@ -0,0 +1,283 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal"
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/discord"
+	"PanickedBot/internal/extract"
+)
+
+// gearScreenshotPrefix prefixes the custom IDs of the /gear screenshot confirmation buttons.
+// Custom IDs have the form gearshot:<screenshot id>:<action>.
+const gearScreenshotPrefix = "gearshot"
+
+// Gear screenshot actions
+const (
+	gearScreenshotConfirm = "confirm" // save the stats read from the screenshot
+	gearScreenshotCancel  = "cancel"  // discard them
+)
+
+// gearScreenshotCustomID builds the custom ID of a confirmation button
+func gearScreenshotCustomID(screenshotID int64, action string) string {
+	return fmt.Sprintf("%s:%d:%s", gearScreenshotPrefix, screenshotID, action)
+}
+
+// parseGearScreenshotCustomID parses a custom ID built by gearScreenshotCustomID
+func parseGearScreenshotCustomID(customID string) (screenshotID int64, action string, ok bool) {
+	parts := strings.Split(customID, ":")
+	if len(parts) != 3 || parts[0] != gearScreenshotPrefix {
+		return 0, "", false
+	}
+
+	screenshotID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || screenshotID <= 0 {
+		return 0, "", false
+	}
+
+	switch parts[2] {
+	case gearScreenshotConfirm, gearScreenshotCancel:
+		return screenshotID, parts[2], true
+	}
+	return 0, "", false
+}
+
+// gearScreenshotFields converts the stats read from a screenshot to member update fields,
+// checking each against the same limits as /stats
+func gearScreenshotFields(stats *extract.GearStats) (internal.UpdateFields, error) {
+	fields := internal.UpdateFields{
+		AP:       stats.AP,
+		AAP:      stats.AAP,
+		DP:       stats.DP,
+		Evasion:  stats.Evasion,
+		DR:       stats.DR,
+		DRR:      stats.DRR,
+		Accuracy: stats.Accuracy,
+		HP:       stats.HP,
+		TotalAP:  stats.TotalAP,
+		TotalAAP: stats.TotalAAP,
+	}
+
+	ints := []struct {
+		option string
+		value  *int
+	}{
+		{"ap", fields.AP}, {"aap", fields.AAP}, {"dp", fields.DP},
+		{"evasion", fields.Evasion}, {"dr", fields.DR}, {"accuracy", fields.Accuracy},
+		{"hp", fields.HP}, {"total_ap", fields.TotalAP}, {"total_aap", fields.TotalAAP},
+	}
+	for _, stat := range ints {
+		if stat.value == nil {
+			continue
+		}
+		if err := validateGearStat(stat.option, float64(*stat.value)); err != nil {
+			return fields, err
+		}
+	}
+	if fields.DRR != nil {
+		if err := validateGearStat("drr", *fields.DRR); err != nil {
+			return fields, err
+		}
+	}
+
+	return fields, nil
+}
+
+// gearScreenshotProfile describes the stats read from a screenshot like a gear profile
+func gearScreenshotProfile(stats internal.UpdateFields) string {
+	return formatGearProfile(&internal.Member{
+		AP:       stats.AP,
+		AAP:      stats.AAP,
+		DP:       stats.DP,
+		Evasion:  stats.Evasion,
+		DR:       stats.DR,
+		DRR:      stats.DRR,
+		Accuracy: stats.Accuracy,
+		HP:       stats.HP,
+		TotalAP:  stats.TotalAP,
+		TotalAAP: stats.TotalAAP,
+	})
+}
+
+// handleGearScreenshot reads the gear stats from a stat window screenshot attached to /gear
+// and asks the user to confirm them before they are saved
+func handleGearScreenshot(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, jobs *WarJobQueue, userID, username string, attachment *discordgo.MessageAttachment) {
+	if !isImageFile(attachment.Filename) {
+		discord.RespondEphemeral(s, i, "The screenshot must be an image file (.png, .jpg, .jpeg, .webp).")
+		return
+	}
+	if attachment.Size > maxAttachmentSize(attachment.Filename) {
+		discord.RespondEphemeral(s, i, "Image file size exceeds 5MB limit.")
+		return
+	}
+	if !isDiscordCDNURL(attachment.URL) {
+		log.Printf("gear screenshot: suspicious attachment URL: %s", attachment.URL)
+		discord.RespondEphemeral(s, i, "Invalid attachment source.")
+		return
+	}
+
+	// Reading the screenshot takes longer than Discord waits for a response
+	if err := discord.DeferEphemeral(s, i); err != nil {
+		log.Printf("gear screenshot defer error: %v", err)
+		return
+	}
+
+	reply := func(msg string) {
+		_, _ = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg})
+	}
+
+	content, err := downloadAttachment(attachment.URL, maxAttachmentSize(attachment.Filename))
+	if err != nil {
+		log.Printf("gear screenshot download error: %v", err)
+		reply("Failed to download the screenshot. Please try again.")
+		return
+	}
+
+	extractor, err := jobs.gearExtractor(cfg)
+	if err != nil {
+		log.Printf("gear screenshot extractor error: %v", err)
+		reply("Screenshot reading is not configured. Please enter AP, AAP and DP instead.")
+		return
+	}
+
+	stats, err := extractor.ExtractGear(context.Background(), content, attachment.ContentType)
+	var moderationErr *extract.ModerationError
+	if errors.As(err, &moderationErr) {
+		reply(fmt.Sprintf("⚠️ **Image Moderation Failed**\n\n"+
+			"The uploaded image was flagged for potentially unsafe content.\n\n"+
+			"**Flagged categories:** %s\n\n"+
+			"Please upload a different image that complies with content policies.", strings.Join(moderationErr.Categories, ", ")))
+		return
+	} else if err != nil {
+		log.Printf("gear screenshot extract error: %v", err)
+		reply("Could not read AP, AAP and DP from the screenshot. Please upload a clearer screenshot of the stat window or enter the values instead.")
+		return
+	}
+
+	fields, err := gearScreenshotFields(stats)
+	if err != nil {
+		reply(fmt.Sprintf("The screenshot shows an invalid value: %v. Please enter the values instead.", err))
+		return
+	}
+
+	m, err := getOrCreateMember(dbx, i.GuildID, userID, username, "gear screenshot")
+	if err != nil {
+		reply("Failed to update gear stats. Please try again.")
+		return
+	}
+
+	savedPath, err := saveUpload(content, i.Member.User.ID, fmt.Sprintf("gear%d", m.ID), attachment.Filename)
+	if err != nil {
+		log.Printf("gear screenshot save error: %v", err)
+		reply("Failed to save the screenshot. Please try again.")
+		return
+	}
+	log.Printf("Image saved to: %s", savedPath)
+
+	screenshotID, err := db.CreateGearScreenshot(dbx, i.GuildID, m.ID, i.Member.User.ID, savedPath, fields)
+	if err != nil {
+		log.Printf("gear screenshot create error: %v", err)
+		reply("Failed to update gear stats. Please try again.")
+		return
+	}
+
+	msg := fmt.Sprintf("**Stats read for %s**\n%s\n\nPlease check the values against your stat window before confirming.", m.FamilyName, gearScreenshotProfile(fields))
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Confirm stats",
+					Style:    discordgo.SuccessButton,
+					CustomID: gearScreenshotCustomID(screenshotID, gearScreenshotConfirm),
+				},
+				discordgo.Button{
+					Label:    "Cancel",
+					Style:    discordgo.DangerButton,
+					CustomID: gearScreenshotCustomID(screenshotID, gearScreenshotCancel),
+				},
+			},
+		},
+	}
+	_, _ = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    &msg,
+		Components: &components,
+	})
+}
+
+// handleGearScreenshotComponent handles the confirmation buttons of /gear screenshots
+func handleGearScreenshotComponent(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	screenshotID, action, ok := parseGearScreenshotCustomID(i.MessageComponentData().CustomID)
+	if !ok {
+		discord.RespondEphemeral(s, i, "Unknown action.")
+		return
+	}
+
+	screenshot, err := db.GetGearScreenshot(dbx, screenshotID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && screenshot.DiscordGuildID != i.GuildID) {
+		discord.RespondEphemeral(s, i, "Screenshot not found.")
+		return
+	}
+	if err != nil {
+		log.Printf("gear screenshot error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to load the screenshot. Please try again.")
+		return
+	}
+
+	if screenshot.SubmittedByUserID != i.Member.User.ID && !hasOfficerPermission(s, i, cfg) {
+		discord.RespondEphemeral(s, i, "Only the member who uploaded the screenshot can confirm it.")
+		return
+	}
+
+	status := db.GearScreenshotConfirmed
+	if action == gearScreenshotCancel {
+		status = db.GearScreenshotCanceled
+	}
+
+	// Claim the screenshot so a double click saves the stats only once
+	resolved, err := db.ResolveGearScreenshot(dbx, screenshot.ID, status)
+	if err != nil {
+		log.Printf("gear screenshot error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to update the screenshot. Please try again.")
+		return
+	}
+	if !resolved {
+		discord.RespondEphemeral(s, i, "This screenshot was already confirmed or canceled.")
+		return
+	}
+
+	update := func(msg string) {
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:    msg,
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+	}
+
+	if action == gearScreenshotCancel {
+		update("Screenshot discarded. Your gear stats were not changed.")
+		return
+	}
+
+	fields := screenshot.Stats
+	fields.GearVerified = true
+	fields.UpdatedByUserID = i.Member.User.ID
+
+	if err := internal.UpdateMember(dbx, screenshot.MemberID, fields); err != nil {
+		log.Printf("gear screenshot update error: %v", err)
+		update("Failed to update gear stats. Please upload the screenshot again.")
+		return
+	}
+
+	update(fmt.Sprintf("✅ Gear stats updated and verified from the screenshot.\n%s", gearScreenshotProfile(fields)))
+}
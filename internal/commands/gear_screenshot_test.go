@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"testing"
+
+	"PanickedBot/internal/extract"
+)
+
+func TestGearScreenshotCustomIDRoundTrip(t *testing.T) {
+	for _, action := range []string{gearScreenshotConfirm, gearScreenshotCancel} {
+		customID := gearScreenshotCustomID(42, action)
+		id, parsedAction, ok := parseGearScreenshotCustomID(customID)
+		if !ok || id != 42 || parsedAction != action {
+			t.Errorf("parseGearScreenshotCustomID(%q) = %d, %q, %v", customID, id, parsedAction, ok)
+		}
+	}
+}
+
+func TestParseGearScreenshotCustomIDInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"gearshot",
+		"gearshot:12",
+		"warreview:12:confirm",
+		"gearshot:abc:confirm",
+		"gearshot:0:confirm",
+		"gearshot:12:delete",
+		"gearshot:12:confirm:1",
+	}
+
+	for _, customID := range invalid {
+		if _, _, ok := parseGearScreenshotCustomID(customID); ok {
+			t.Errorf("parseGearScreenshotCustomID(%q) should fail", customID)
+		}
+	}
+}
+
+func TestGearScreenshotFields(t *testing.T) {
+	drr := 25.5
+	stats := &extract.GearStats{AP: intPtr(310), AAP: intPtr(312), DP: intPtr(420), DRR: &drr, HP: intPtr(5600)}
+
+	fields, err := gearScreenshotFields(stats)
+	if err != nil {
+		t.Fatalf("gearScreenshotFields() unexpected error: %v", err)
+	}
+	if *fields.AP != 310 || *fields.DP != 420 || *fields.DRR != 25.5 || *fields.HP != 5600 || fields.Evasion != nil {
+		t.Errorf("gearScreenshotFields() = %+v", fields)
+	}
+	if fields.GearVerified {
+		t.Error("gearScreenshotFields() should leave GearVerified to the confirmation")
+	}
+
+	tooHigh := []*extract.GearStats{
+		{AP: intPtr(3100), AAP: intPtr(312), DP: intPtr(420)},
+		{AP: intPtr(310), AAP: intPtr(312), DP: intPtr(420), Evasion: intPtr(99999)},
+	}
+	for _, stats := range tooHigh {
+		if _, err := gearScreenshotFields(stats); err == nil {
+			t.Errorf("gearScreenshotFields(%+v) expected error", stats)
+		}
+	}
+
+	drr = 250
+	if _, err := gearScreenshotFields(&extract.GearStats{AP: intPtr(310), AAP: intPtr(312), DP: intPtr(420), DRR: &drr}); err == nil {
+		t.Error("gearScreenshotFields() expected error for DRR over 100")
+	}
+}
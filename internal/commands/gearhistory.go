@@ -0,0 +1,275 @@
+package commands
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal"
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/discord"
+)
+
+// defaultGearHistoryEntries is how many gear updates /gearhistory shows by default
+const defaultGearHistoryEntries = 10
+
+func gearHistoryCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "gearhistory",
+		Description: "Show how a member's gear score changed over time",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "member",
+				Description: "Discord member to show (officers only, leave empty for yourself)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "family_name",
+				Description: "Family name of the member to show (officers only)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "entries",
+				Description: "Number of gear updates to show (default: 10)",
+				Required:    false,
+				MinValue:    float64Ptr(1),
+				MaxValue:    25,
+			},
+		},
+	}
+}
+
+func gearReportCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "gearreport",
+		Description: "List members whose gear score stayed the same or went down (officer role required)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "weeks",
+				Description: "Number of weeks to compare against (default: 4)",
+				Required:    false,
+				MinValue:    float64Ptr(1),
+				MaxValue:    52,
+			},
+		},
+	}
+}
+
+// nullIntPtr converts a nullable stat column to an optional stat
+func nullIntPtr(v sql.NullInt32) *int {
+	if !v.Valid {
+		return nil
+	}
+	value := int(v.Int32)
+	return &value
+}
+
+// historyGS calculates the gear score of a gear history entry
+func historyGS(ap, aap, dp sql.NullInt32) int {
+	return calculateGS(nullIntPtr(ap), nullIntPtr(aap), nullIntPtr(dp))
+}
+
+// formatGSChange describes the difference between two gear scores, e.g. "+12"
+func formatGSChange(before, after int) string {
+	switch {
+	case after > before:
+		return fmt.Sprintf("+%d", after-before)
+	case after < before:
+		return fmt.Sprintf("%d", after-before)
+	default:
+		return "±0"
+	}
+}
+
+// formatGearHistory lists up to limit gear updates with the GS change against the update before each.
+// Entries are newest first; passing one more than limit shows the change of the oldest update listed.
+func formatGearHistory(entries []db.GearHistoryEntry, limit int, loc *time.Location) string {
+	stat := func(v sql.NullInt32) string {
+		if !v.Valid {
+			return "-"
+		}
+		return fmt.Sprintf("%d", v.Int32)
+	}
+
+	var b strings.Builder
+	for idx, entry := range entries {
+		if idx == limit {
+			break
+		}
+
+		gs := historyGS(entry.AP, entry.AAP, entry.DP)
+		b.WriteString(fmt.Sprintf("• %s: AP %s | AAP %s | DP %s | GS %d",
+			entry.CreatedAt.In(loc).Format("02-01-06"), stat(entry.AP), stat(entry.AAP), stat(entry.DP), gs))
+
+		if idx+1 < len(entries) {
+			previous := entries[idx+1]
+			b.WriteString(fmt.Sprintf(" (%s)", formatGSChange(historyGS(previous.AP, previous.AAP, previous.DP), gs)))
+		} else {
+			b.WriteString(" (first update)")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func handleGearHistory(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	isOfficer := hasOfficerPermission(s, i, cfg)
+	if !hasGuildMemberPermission(i, cfg) && !isOfficer {
+		discord.RespondEphemeral(s, i, "You need guild member role to use this command.")
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+
+	limit := defaultGearHistoryEntries
+	if entries := intOption(options, "entries"); entries != nil {
+		limit = *entries
+	}
+
+	// Members see their own history, officers can look up anyone
+	m, err := findMemberOption(s, i, dbx, options)
+	if err == nil && m == nil {
+		m, err = internal.GetMemberByDiscordUserIDIncludingInactive(dbx, i.GuildID, i.Member.User.ID)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, "Member not found.")
+		return
+	} else if err != nil {
+		log.Printf("gearhistory lookup error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve member information. Please try again.")
+		return
+	}
+
+	isSelf := m.DiscordUserID != nil && *m.DiscordUserID == i.Member.User.ID
+	if !isSelf && !isOfficer {
+		discord.RespondEphemeral(s, i, "Only officers can view another member's gear history.")
+		return
+	}
+
+	// Fetch one extra entry to show the change of the oldest entry listed
+	entries, err := db.GetGearHistory(dbx, m.ID, limit+1)
+	if err != nil {
+		log.Printf("gearhistory error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve gear history. Please try again.")
+		return
+	}
+
+	if len(entries) == 0 {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("No gear updates recorded for %s yet.", m.FamilyName))
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("**Gear history of %s**\n", m.FamilyName))
+	b.WriteString(formatGearHistory(entries, limit, cfg.Location()))
+
+	// Keep within Discord's 2000 character message limit
+	discord.RespondEphemeral(s, i, truncateString(b.String(), 2000))
+}
+
+// gearChange is a member's gear score at the start and end of a report period
+type gearChange struct {
+	FamilyName string
+	Before     int
+	After      int
+}
+
+// compareGear finds the members whose gear score is the same as or lower than in their last
+// gear update before the report period. Members without an update before then are unchanged
+// when they did not update their gear during the period either, e.g. gear entered before
+// gear history was recorded; members whose first update falls in the period are left out.
+func compareGear(members []internal.Member, before map[int64]db.GearSnapshot, updated map[int64]bool) (unchanged, decreased []gearChange) {
+	for _, m := range members {
+		after := calculateGS(m.AP, m.AAP, m.DP)
+		snapshot, ok := before[m.ID]
+		if !ok {
+			if !updated[m.ID] && (m.AP != nil || m.AAP != nil || m.DP != nil) {
+				unchanged = append(unchanged, gearChange{FamilyName: m.FamilyName, Before: after, After: after})
+			}
+			continue
+		}
+
+		change := gearChange{
+			FamilyName: m.FamilyName,
+			Before:     historyGS(snapshot.AP, snapshot.AAP, snapshot.DP),
+			After:      after,
+		}
+		switch {
+		case change.After < change.Before:
+			decreased = append(decreased, change)
+		case change.After == change.Before:
+			unchanged = append(unchanged, change)
+		}
+	}
+	return unchanged, decreased
+}
+
+func handleGearReport(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	if !hasOfficerPermission(s, i, cfg) {
+		discord.RespondEphemeral(s, i, "You need officer role or admin permission to use this command.")
+		return
+	}
+
+	weeks := 4
+	if value := intOption(i.ApplicationCommandData().Options, "weeks"); value != nil {
+		weeks = *value
+	}
+
+	members, err := internal.GetAllRosterMembers(dbx, i.GuildID)
+	if err != nil {
+		log.Printf("gearreport roster error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve roster members. Please try again.")
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -7*weeks)
+	before, err := db.GetGearAsOf(dbx, i.GuildID, since)
+	if err != nil {
+		log.Printf("gearreport error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve gear history. Please try again.")
+		return
+	}
+
+	updated, err := db.GetGearUpdatedSince(dbx, i.GuildID, since)
+	if err != nil {
+		log.Printf("gearreport error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve gear history. Please try again.")
+		return
+	}
+
+	unchanged, decreased := compareGear(members, before, updated)
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("**Gear Report (Last %d weeks)**\n", weeks))
+	b.WriteString(fmt.Sprintf("Compared with each member's gear on %s. Members who first entered their gear since then are not listed.\n\n",
+		since.In(cfg.Location()).Format("02-01-06")))
+
+	if len(unchanged) == 0 && len(decreased) == 0 {
+		b.WriteString("✅ No members with an unchanged or lower gear score!")
+	}
+	if len(decreased) > 0 {
+		b.WriteString(fmt.Sprintf("📉 **%d members with a lower gear score:**\n", len(decreased)))
+		for _, change := range decreased {
+			b.WriteString(fmt.Sprintf("• %s: %d → %d (%s)\n", change.FamilyName, change.Before, change.After, formatGSChange(change.Before, change.After)))
+		}
+		b.WriteString("\n")
+	}
+	if len(unchanged) > 0 {
+		b.WriteString(fmt.Sprintf("⏸️ **%d members with an unchanged gear score:**\n", len(unchanged)))
+		for _, change := range unchanged {
+			b.WriteString(fmt.Sprintf("• %s: %d\n", change.FamilyName, change.After))
+		}
+	}
+
+	// Keep within Discord's 2000 character message limit
+	discord.RespondText(s, i, truncateString(b.String(), 2000))
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"PanickedBot/internal"
+	"PanickedBot/internal/db"
+)
+
+func gearEntry(ap, aap, dp int32, day int) db.GearHistoryEntry {
+	return db.GearHistoryEntry{
+		AP:        sql.NullInt32{Int32: ap, Valid: true},
+		AAP:       sql.NullInt32{Int32: aap, Valid: true},
+		DP:        sql.NullInt32{Int32: dp, Valid: true},
+		CreatedAt: time.Date(2024, time.March, day, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestFormatGSChange(t *testing.T) {
+	tests := []struct {
+		before, after int
+		expected      string
+	}{
+		{before: 700, after: 712, expected: "+12"},
+		{before: 712, after: 700, expected: "-12"},
+		{before: 700, after: 700, expected: "±0"},
+	}
+
+	for _, tt := range tests {
+		if got := formatGSChange(tt.before, tt.after); got != tt.expected {
+			t.Errorf("formatGSChange(%d, %d) = %q, expected %q", tt.before, tt.after, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatGearHistory(t *testing.T) {
+	entries := []db.GearHistoryEntry{
+		gearEntry(320, 322, 420, 20),
+		gearEntry(310, 312, 420, 10),
+		gearEntry(310, 312, 425, 1),
+	}
+
+	t.Run("all entries", func(t *testing.T) {
+		expected := "• 20-03-24: AP 320 | AAP 322 | DP 420 | GS 741 (+10)\n" +
+			"• 10-03-24: AP 310 | AAP 312 | DP 420 | GS 731 (-5)\n" +
+			"• 01-03-24: AP 310 | AAP 312 | DP 425 | GS 736 (first update)\n"
+		if got := formatGearHistory(entries, 3, time.UTC); got != expected {
+			t.Errorf("formatGearHistory() = %q, expected %q", got, expected)
+		}
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		got := formatGearHistory(entries, 2, time.UTC)
+		if strings.Count(got, "\n") != 2 {
+			t.Fatalf("expected 2 lines, got %q", got)
+		}
+		if !strings.HasSuffix(got, "GS 731 (-5)\n") {
+			t.Errorf("expected the oldest listed entry to show its change, got %q", got)
+		}
+	})
+
+	t.Run("missing stats", func(t *testing.T) {
+		got := formatGearHistory([]db.GearHistoryEntry{{CreatedAt: entries[0].CreatedAt}}, 1, time.UTC)
+		if got != "• 20-03-24: AP - | AAP - | DP - | GS 0 (first update)\n" {
+			t.Errorf("unexpected line for missing stats: %q", got)
+		}
+	})
+}
+
+func TestCompareGear(t *testing.T) {
+	members := []internal.Member{
+		{ID: 1, FamilyName: "Improved", AP: intPtr(320), AAP: intPtr(322), DP: intPtr(420)},
+		{ID: 2, FamilyName: "Unchanged", AP: intPtr(310), AAP: intPtr(312), DP: intPtr(420)},
+		{ID: 3, FamilyName: "Decreased", AP: intPtr(310), AAP: intPtr(312), DP: intPtr(410)},
+		{ID: 4, FamilyName: "New", AP: intPtr(280), AAP: intPtr(280), DP: intPtr(350)},
+		{ID: 5, FamilyName: "NoHistory", AP: intPtr(300), AAP: intPtr(300), DP: intPtr(400)},
+		{ID: 6, FamilyName: "NoGear"},
+	}
+	before := map[int64]db.GearSnapshot{
+		1: {MemberID: 1, AP: sql.NullInt32{Int32: 310, Valid: true}, AAP: sql.NullInt32{Int32: 312, Valid: true}, DP: sql.NullInt32{Int32: 420, Valid: true}},
+		2: {MemberID: 2, AP: sql.NullInt32{Int32: 310, Valid: true}, AAP: sql.NullInt32{Int32: 312, Valid: true}, DP: sql.NullInt32{Int32: 420, Valid: true}},
+		3: {MemberID: 3, AP: sql.NullInt32{Int32: 310, Valid: true}, AAP: sql.NullInt32{Int32: 312, Valid: true}, DP: sql.NullInt32{Int32: 420, Valid: true}},
+	}
+
+	updated := map[int64]bool{1: true, 4: true}
+
+	unchanged, decreased := compareGear(members, before, updated)
+
+	if len(unchanged) != 2 || unchanged[0].FamilyName != "Unchanged" || unchanged[0].After != 731 {
+		t.Fatalf("unexpected unchanged members: %+v", unchanged)
+	}
+	if unchanged[1].FamilyName != "NoHistory" || unchanged[1].Before != 700 || unchanged[1].After != 700 {
+		t.Errorf("expected the member without gear history to be unchanged at their current GS, got %+v", unchanged[1])
+	}
+	if len(decreased) != 1 || decreased[0].FamilyName != "Decreased" || decreased[0].Before != 731 || decreased[0].After != 721 {
+		t.Errorf("unexpected decreased members: %+v", decreased)
+	}
+}
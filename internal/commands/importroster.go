@@ -0,0 +1,432 @@
+package commands
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal"
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/discord"
+)
+
+// maxRosterImportRows limits the members a single /importroster file can hold
+const maxRosterImportRows = 500
+
+// rosterImportColumns maps the accepted CSV headers to their column.
+// The /export roster headers are accepted so an export can be imported again.
+var rosterImportColumns = map[string]string{
+	"family_name":     "family_name",
+	"family":          "family_name",
+	"discord_user":    "discord_user",
+	"discord_user_id": "discord_user",
+	"discord":         "discord_user",
+	"class":           "class",
+	"spec":            "spec",
+	"ap":              "ap",
+	"aap":             "aap",
+	"dp":              "dp",
+	"teams":           "teams",
+	"mercenary":       "mercenary",
+	"is_mercenary":    "mercenary",
+}
+
+func importRosterCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "importroster",
+		Description: "Create or update roster members from a CSV file (officer role required)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionAttachment,
+				Name:        "file",
+				Description: "CSV file with a header row; family_name is required, other columns are optional",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// rosterImportRow is one member row of an /importroster file
+type rosterImportRow struct {
+	Line    int // line of the row in the file
+	Member  internal.RosterImportMember
+	Teams   []string // team names, resolved to Member.TeamIDs before importing
+	Problem string   // why the row is rejected, empty for valid rows
+}
+
+// parseDiscordUserID accepts a Discord user ID or a mention like <@123> or <@!123>
+func parseDiscordUserID(value string) (string, bool) {
+	id := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(value, "<@"), "!"), ">")
+	if len(id) < 15 || len(id) > 20 {
+		return "", false
+	}
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	return id, true
+}
+
+// parseImportBool parses a yes/no cell
+func parseImportBool(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "yes", "y", "true", "1":
+		return true, true
+	case "no", "n", "false", "0":
+		return false, true
+	}
+	return false, false
+}
+
+// parseRosterImportRecord validates the cells of one row, keyed by column.
+// Returns the member, the names of its teams and a problem when the row is invalid.
+func parseRosterImportRecord(cells map[string]string) (internal.RosterImportMember, []string, string) {
+	m := internal.RosterImportMember{FamilyName: cells["family_name"]}
+	if m.FamilyName == "" {
+		return m, nil, "family name is missing"
+	}
+
+	if value := cells["discord_user"]; value != "" {
+		id, ok := parseDiscordUserID(value)
+		if !ok {
+			return m, nil, fmt.Sprintf("invalid Discord user '%s'", value)
+		}
+		m.DiscordUserID = id
+	}
+
+	if value := cells["class"]; value != "" {
+		class, ok := validateClassName(value)
+		if !ok {
+			return m, nil, fmt.Sprintf("unknown class '%s'", value)
+		}
+		m.Class = class
+	}
+
+	if value := cells["spec"]; value != "" {
+		for _, choice := range getSpecChoices() {
+			if strings.EqualFold(value, choice.Name) {
+				m.Spec = choice.Value.(string)
+			}
+		}
+		if m.Spec == "" {
+			return m, nil, fmt.Sprintf("unknown spec '%s'", value)
+		}
+	}
+
+	stats := []struct {
+		column string
+		target **int
+	}{
+		{"ap", &m.AP}, {"aap", &m.AAP}, {"dp", &m.DP},
+	}
+	for _, stat := range stats {
+		value := cells[stat.column]
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return m, nil, fmt.Sprintf("invalid %s '%s'", strings.ToUpper(stat.column), value)
+		}
+		if err := validateGearStat(stat.column, float64(n)); err != nil {
+			return m, nil, err.Error()
+		}
+		*stat.target = &n
+	}
+
+	var teams []string
+	if value := cells["teams"]; value != "" {
+		for _, name := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == ',' }) {
+			if name = strings.TrimSpace(name); name != "" {
+				teams = append(teams, name)
+			}
+		}
+	}
+
+	if value := cells["mercenary"]; value != "" {
+		mercenary, ok := parseImportBool(value)
+		if !ok {
+			return m, nil, fmt.Sprintf("invalid mercenary flag '%s', use yes or no", value)
+		}
+		m.IsMercenary = &mercenary
+	}
+
+	return m, teams, ""
+}
+
+// parseRosterImport reads an /importroster file. The first row holds the column headers;
+// unknown columns are ignored. Rows with invalid values or repeating a member of an earlier
+// row are returned with a problem. An error is returned when the file itself can't be used.
+func parseRosterImport(content []byte) ([]rosterImportRow, error) {
+	// Spreadsheet programs often start UTF-8 files with a byte order mark
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("the file is empty")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read the header row: %w", err)
+	}
+
+	columns := make(map[int]string)
+	seen := make(map[string]bool)
+	for idx, name := range header {
+		key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+		column, ok := rosterImportColumns[key]
+		if !ok {
+			continue
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("the %s column appears more than once", column)
+		}
+		seen[column] = true
+		columns[idx] = column
+	}
+	if !seen["family_name"] {
+		return nil, fmt.Errorf("the header row has no family_name column")
+	}
+
+	var rows []rosterImportRow
+	familyLines := make(map[string]int)
+	userLines := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the file: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		cells := make(map[string]string)
+		empty := true
+		for idx, value := range record {
+			value = strings.TrimSpace(value)
+			if column, ok := columns[idx]; ok {
+				cells[column] = value
+			}
+			if value != "" {
+				empty = false
+			}
+		}
+		if empty {
+			continue
+		}
+
+		if len(rows) == maxRosterImportRows {
+			return nil, fmt.Errorf("the file has more than %d members", maxRosterImportRows)
+		}
+
+		member, teams, problem := parseRosterImportRecord(cells)
+		if problem == "" {
+			key := strings.ToLower(member.FamilyName)
+			if earlier, ok := familyLines[key]; ok {
+				problem = fmt.Sprintf("family name already imported on line %d", earlier)
+			} else if earlier, ok := userLines[member.DiscordUserID]; ok && member.DiscordUserID != "" {
+				problem = fmt.Sprintf("Discord user already imported on line %d", earlier)
+			} else {
+				familyLines[key] = line
+				if member.DiscordUserID != "" {
+					userLines[member.DiscordUserID] = line
+				}
+			}
+		}
+
+		rows = append(rows, rosterImportRow{Line: line, Member: member, Teams: teams, Problem: problem})
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("the file has no members")
+	}
+	return rows, nil
+}
+
+// resolveRosterImportTeams looks up the teams of every valid row, rejecting rows with
+// unknown or inactive teams. Each team name is looked up once.
+func resolveRosterImportTeams(rows []rosterImportRow, lookup func(name string) (*db.Team, error)) error {
+	teams := make(map[string]*db.Team)
+	for idx := range rows {
+		row := &rows[idx]
+		if row.Problem != "" || row.Teams == nil {
+			continue
+		}
+
+		teamIDs := []int64{}
+		for _, name := range row.Teams {
+			key := strings.ToLower(name)
+			team, ok := teams[key]
+			if !ok {
+				var err error
+				team, err = lookup(name)
+				if errors.Is(err, sql.ErrNoRows) {
+					team = nil
+				} else if err != nil {
+					return err
+				}
+				teams[key] = team
+			}
+
+			if team == nil {
+				row.Problem = fmt.Sprintf("team '%s' not found", name)
+				break
+			}
+			if !team.IsActive {
+				row.Problem = fmt.Sprintf("team '%s' is not active", name)
+				break
+			}
+			teamIDs = append(teamIDs, team.ID)
+		}
+
+		if row.Problem == "" {
+			row.Member.TeamIDs = teamIDs
+		}
+	}
+	return nil
+}
+
+// formatRosterImport summarizes an import with one line per row.
+// results holds the outcome of the valid rows, in order.
+func formatRosterImport(rows []rosterImportRow, results []internal.RosterImportResult) (summary string, details string) {
+	counts := make(map[string]int)
+	var b strings.Builder
+
+	next := 0
+	for _, row := range rows {
+		action, problem := db.RosterImportRejected, row.Problem
+		if problem == "" {
+			action, problem = results[next].Action, results[next].Problem
+			next++
+		}
+		counts[action]++
+
+		name := row.Member.FamilyName
+		if name == "" {
+			name = "(no family name)"
+		}
+		if problem != "" {
+			b.WriteString(fmt.Sprintf("Line %d: %s - %s: %s\n", row.Line, name, action, problem))
+		} else {
+			b.WriteString(fmt.Sprintf("Line %d: %s - %s\n", row.Line, name, action))
+		}
+	}
+
+	summary = fmt.Sprintf("**Roster import: %d created, %d updated, %d rejected**",
+		counts[db.RosterImportCreated], counts[db.RosterImportUpdated], counts[db.RosterImportRejected])
+	return summary, b.String()
+}
+
+func handleImportRoster(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	if !hasOfficerPermission(s, i, cfg) {
+		discord.RespondEphemeral(s, i, "You need officer role or admin permission to use this command.")
+		return
+	}
+
+	data := i.ApplicationCommandData()
+	var attachment *discordgo.MessageAttachment
+	if opt := findOption(data.Options, "file"); opt != nil {
+		if id, ok := opt.Value.(string); ok && data.Resolved != nil {
+			attachment = data.Resolved.Attachments[id]
+		}
+	}
+	if attachment == nil {
+		discord.RespondEphemeral(s, i, "Please attach a CSV file with the roster.")
+		return
+	}
+
+	if !isCSVFile(attachment.Filename) {
+		discord.RespondEphemeral(s, i, "The roster must be a CSV file (.csv).")
+		return
+	}
+	if attachment.Size > maxAttachmentSize(attachment.Filename) {
+		discord.RespondEphemeral(s, i, "CSV file size exceeds 10MB limit.")
+		return
+	}
+	if !isDiscordCDNURL(attachment.URL) {
+		log.Printf("importroster: suspicious attachment URL: %s", attachment.URL)
+		discord.RespondEphemeral(s, i, "Invalid attachment source.")
+		return
+	}
+
+	// Downloading and importing a large roster takes longer than Discord waits for a response
+	if err := discord.DeferEphemeral(s, i); err != nil {
+		log.Printf("importroster defer error: %v", err)
+		return
+	}
+
+	content, err := downloadAttachment(attachment.URL, maxAttachmentSize(attachment.Filename))
+	if err != nil {
+		log.Printf("importroster download error: %v", err)
+		_ = discord.FollowUpEphemeral(s, i, "Failed to download the file. Please try again.")
+		return
+	}
+
+	rows, err := parseRosterImport(content)
+	if err != nil {
+		_ = discord.FollowUpEphemeral(s, i, fmt.Sprintf("Could not read the roster: %v.", err))
+		return
+	}
+
+	err = resolveRosterImportTeams(rows, func(name string) (*db.Team, error) {
+		return db.GetTeamByName(dbx, i.GuildID, name)
+	})
+	if err != nil {
+		log.Printf("importroster team lookup error: %v", err)
+		_ = discord.FollowUpEphemeral(s, i, "Failed to look up teams. Please try again.")
+		return
+	}
+
+	var members []internal.RosterImportMember
+	for idx := range rows {
+		if rows[idx].Problem != "" {
+			continue
+		}
+		if userID := rows[idx].Member.DiscordUserID; userID != "" {
+			// The fallback to the user ID means the user is not in the server
+			if displayName := getDiscordDisplayName(s, i.GuildID, userID); displayName != userID {
+				rows[idx].Member.DisplayName = displayName
+			}
+		}
+		members = append(members, rows[idx].Member)
+	}
+
+	var results []internal.RosterImportResult
+	note := ""
+	if len(members) > 0 {
+		results, err = internal.ImportRosterMembers(dbx, i.GuildID, members, i.Member.User.ID)
+		if err != nil && results == nil {
+			log.Printf("importroster error: %v", err)
+			_ = discord.FollowUpEphemeral(s, i, "Failed to import the roster. No members were changed. Please try again.")
+			return
+		} else if err != nil {
+			log.Printf("importroster meets_cap error: %v", err)
+			note = "\n⚠️ The members were imported, but meets_cap could not be updated for all of them."
+		}
+	}
+
+	summary, details := formatRosterImport(rows, results)
+	msg := summary + note + "\n```\n" + details + "```"
+	if len(msg) <= 2000 {
+		_ = discord.FollowUpEphemeral(s, i, msg)
+		return
+	}
+
+	// Attach the per-row summary when it doesn't fit in a message
+	if err := discord.FollowUpFile(s, i, summary+note+"\nThe result of every row is in the attached file.", "roster_import.txt", "text/plain", []byte(details)); err != nil {
+		log.Printf("importroster summary upload error: %v", err)
+		_ = discord.FollowUpEphemeral(s, i, summary+note)
+	}
+}
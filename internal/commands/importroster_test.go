@@ -0,0 +1,252 @@
+package commands
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"PanickedBot/internal"
+	"PanickedBot/internal/db"
+)
+
+func TestParseDiscordUserID(t *testing.T) {
+	tests := []struct {
+		value  string
+		wantID string
+		wantOK bool
+	}{
+		{"123456789012345678", "123456789012345678", true},
+		{"<@123456789012345678>", "123456789012345678", true},
+		{"<@!123456789012345678>", "123456789012345678", true},
+		{"Kethrya", "", false},
+		{"12345", "", false},
+		{"<@12345678901234567x>", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			id, ok := parseDiscordUserID(tt.value)
+			if id != tt.wantID || ok != tt.wantOK {
+				t.Errorf("parseDiscordUserID(%q) = %q, %v, want %q, %v", tt.value, id, ok, tt.wantID, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseRosterImportRecord(t *testing.T) {
+	tests := []struct {
+		name        string
+		cells       map[string]string
+		wantProblem string
+		check       func(t *testing.T, m internal.RosterImportMember, teams []string)
+	}{
+		{
+			name: "all columns",
+			cells: map[string]string{
+				"family_name": "Kethrya", "discord_user": "<@123456789012345678>", "class": "dark knight",
+				"spec": "Awakening", "ap": "300", "aap": "310", "dp": "400", "teams": "Alpha; Bravo", "mercenary": "yes",
+			},
+			check: func(t *testing.T, m internal.RosterImportMember, teams []string) {
+				if m.DiscordUserID != "123456789012345678" || m.Class != "Dark Knight" || m.Spec != "awakening" {
+					t.Errorf("member = %+v", m)
+				}
+				if m.AP == nil || *m.AP != 300 || m.AAP == nil || *m.AAP != 310 || m.DP == nil || *m.DP != 400 {
+					t.Errorf("gear = %v/%v/%v, want 300/310/400", m.AP, m.AAP, m.DP)
+				}
+				if m.IsMercenary == nil || !*m.IsMercenary {
+					t.Errorf("IsMercenary = %v, want true", m.IsMercenary)
+				}
+				if strings.Join(teams, "|") != "Alpha|Bravo" {
+					t.Errorf("teams = %v, want [Alpha Bravo]", teams)
+				}
+			},
+		},
+		{
+			name:  "family name only",
+			cells: map[string]string{"family_name": "Kethrya"},
+			check: func(t *testing.T, m internal.RosterImportMember, teams []string) {
+				if m.DiscordUserID != "" || m.Class != "" || m.AP != nil || m.IsMercenary != nil || teams != nil {
+					t.Errorf("member = %+v, teams = %v, want only the family name", m, teams)
+				}
+			},
+		},
+		{
+			name:  "comma separated teams",
+			cells: map[string]string{"family_name": "Kethrya", "teams": "Alpha, Bravo,"},
+			check: func(t *testing.T, m internal.RosterImportMember, teams []string) {
+				if strings.Join(teams, "|") != "Alpha|Bravo" {
+					t.Errorf("teams = %v, want [Alpha Bravo]", teams)
+				}
+			},
+		},
+		{name: "missing family name", cells: map[string]string{"class": "Warrior"}, wantProblem: "family name is missing"},
+		{name: "invalid discord user", cells: map[string]string{"family_name": "Kethrya", "discord_user": "Kethrya#1234"}, wantProblem: "invalid Discord user"},
+		{name: "unknown class", cells: map[string]string{"family_name": "Kethrya", "class": "Wizzard"}, wantProblem: "unknown class 'Wizzard'"},
+		{name: "unknown spec", cells: map[string]string{"family_name": "Kethrya", "spec": "Prime"}, wantProblem: "unknown spec 'Prime'"},
+		{name: "non-numeric AP", cells: map[string]string{"family_name": "Kethrya", "ap": "high"}, wantProblem: "invalid AP 'high'"},
+		{name: "AP out of range", cells: map[string]string{"family_name": "Kethrya", "ap": "5000"}, wantProblem: "must be between"},
+		{name: "invalid mercenary", cells: map[string]string{"family_name": "Kethrya", "mercenary": "maybe"}, wantProblem: "invalid mercenary flag"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, teams, problem := parseRosterImportRecord(tt.cells)
+			if tt.wantProblem != "" {
+				if !strings.Contains(problem, tt.wantProblem) {
+					t.Errorf("problem = %q, want it to contain %q", problem, tt.wantProblem)
+				}
+				return
+			}
+			if problem != "" {
+				t.Fatalf("unexpected problem %q", problem)
+			}
+			tt.check(t, m, teams)
+		})
+	}
+}
+
+func TestParseRosterImport(t *testing.T) {
+	t.Run("rows and duplicates", func(t *testing.T) {
+		content := "\xef\xbb\xbfFamily Name,Discord User ID,Class,notes\n" +
+			"Kethrya,123456789012345678,Warrior,officer\n" +
+			"\n" +
+			"Panicked,,Wizzard,\n" +
+			"kethrya,,,\n" +
+			"Other,123456789012345678,,\n"
+
+		rows, err := parseRosterImport([]byte(content))
+		if err != nil {
+			t.Fatalf("parseRosterImport() error = %v", err)
+		}
+		if len(rows) != 4 {
+			t.Fatalf("parseRosterImport() returned %d rows, want 4", len(rows))
+		}
+
+		wantLines := []int{2, 4, 5, 6}
+		wantProblems := []string{"", "unknown class", "family name already imported on line 2", "Discord user already imported on line 2"}
+		for idx, row := range rows {
+			if row.Line != wantLines[idx] {
+				t.Errorf("row %d line = %d, want %d", idx, row.Line, wantLines[idx])
+			}
+			if wantProblems[idx] == "" && row.Problem != "" || !strings.Contains(row.Problem, wantProblems[idx]) {
+				t.Errorf("row %d problem = %q, want %q", idx, row.Problem, wantProblems[idx])
+			}
+		}
+		if rows[0].Member.Class != "Warrior" || rows[0].Member.DiscordUserID != "123456789012345678" {
+			t.Errorf("first member = %+v", rows[0].Member)
+		}
+	})
+
+	errorTests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"empty file", "", "empty"},
+		{"no family name column", "discord_user,class\n123456789012345678,Warrior\n", "no family_name column"},
+		{"repeated column", "family_name,ap,ap\nKethrya,1,2\n", "more than once"},
+		{"header only", "family_name,class\n", "no members"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRosterImport([]byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseRosterImport() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("too many rows", func(t *testing.T) {
+		var b strings.Builder
+		b.WriteString("family_name\n")
+		for n := 0; n <= maxRosterImportRows; n++ {
+			b.WriteString("Member" + strings.Repeat("x", n%5) + "\n")
+		}
+		_, err := parseRosterImport([]byte(b.String()))
+		if err == nil || !strings.Contains(err.Error(), "more than") {
+			t.Errorf("parseRosterImport() error = %v, want too many members", err)
+		}
+	})
+}
+
+func TestResolveRosterImportTeams(t *testing.T) {
+	teams := map[string]*db.Team{
+		"alpha": {ID: 1, Name: "Alpha", IsActive: true},
+		"bravo": {ID: 2, Name: "Bravo", IsActive: true},
+		"old":   {ID: 3, Name: "Old", IsActive: false},
+	}
+	lookups := 0
+	lookup := func(name string) (*db.Team, error) {
+		lookups++
+		if team, ok := teams[strings.ToLower(name)]; ok {
+			return team, nil
+		}
+		return nil, sql.ErrNoRows
+	}
+
+	rows := []rosterImportRow{
+		{Line: 2, Teams: []string{"Alpha", "Bravo"}},
+		{Line: 3, Teams: []string{"alpha", "Missing"}},
+		{Line: 4, Teams: []string{"Old"}},
+		{Line: 5},
+		{Line: 6, Teams: []string{"Alpha"}, Problem: "unknown class 'Wizzard'"},
+	}
+
+	if err := resolveRosterImportTeams(rows, lookup); err != nil {
+		t.Fatalf("resolveRosterImportTeams() error = %v", err)
+	}
+
+	if ids := rows[0].Member.TeamIDs; len(ids) != 2 || ids[0] != 1 || ids[1] != 2 || rows[0].Problem != "" {
+		t.Errorf("row 2 = %+v, want teams [1 2]", rows[0])
+	}
+	if rows[1].Problem != "team 'Missing' not found" || rows[1].Member.TeamIDs != nil {
+		t.Errorf("row 3 = %+v, want team not found", rows[1])
+	}
+	if rows[2].Problem != "team 'Old' is not active" {
+		t.Errorf("row 4 problem = %q, want team not active", rows[2].Problem)
+	}
+	if rows[3].Member.TeamIDs != nil || rows[3].Problem != "" {
+		t.Errorf("row 5 = %+v, want teams left unchanged", rows[3])
+	}
+	if rows[4].Problem != "unknown class 'Wizzard'" {
+		t.Errorf("row 6 problem = %q, want the earlier problem kept", rows[4].Problem)
+	}
+	if lookups != 4 {
+		t.Errorf("lookups = %d, want each team looked up once", lookups)
+	}
+
+	failing := func(string) (*db.Team, error) { return nil, errors.New("connection lost") }
+	if err := resolveRosterImportTeams([]rosterImportRow{{Teams: []string{"Alpha"}}}, failing); err == nil {
+		t.Error("resolveRosterImportTeams() error = nil, want the lookup error")
+	}
+}
+
+func TestFormatRosterImport(t *testing.T) {
+	rows := []rosterImportRow{
+		{Line: 2, Member: internal.RosterImportMember{FamilyName: "Kethrya"}},
+		{Line: 3, Member: internal.RosterImportMember{FamilyName: "Panicked"}, Problem: "unknown class 'Wizzard'"},
+		{Line: 4, Member: internal.RosterImportMember{FamilyName: "Other"}},
+		{Line: 5, Problem: "family name is missing"},
+		{Line: 6, Member: internal.RosterImportMember{FamilyName: "Taken"}},
+	}
+	results := []internal.RosterImportResult{
+		{MemberID: 1, Action: db.RosterImportCreated},
+		{MemberID: 2, Action: db.RosterImportUpdated},
+		{Action: db.RosterImportRejected, Problem: "the family name is linked to another Discord user"},
+	}
+
+	summary, details := formatRosterImport(rows, results)
+
+	if summary != "**Roster import: 1 created, 1 updated, 3 rejected**" {
+		t.Errorf("summary = %q", summary)
+	}
+	want := "Line 2: Kethrya - created\n" +
+		"Line 3: Panicked - rejected: unknown class 'Wizzard'\n" +
+		"Line 4: Other - updated\n" +
+		"Line 5: (no family name) - rejected: family name is missing\n" +
+		"Line 6: Taken - rejected: the family name is linked to another Discord user\n"
+	if details != want {
+		t.Errorf("details =\n%s\nwant\n%s", details, want)
+	}
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal"
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/discord"
+)
+
+func handleKDException(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	if !hasOfficerPermission(s, i, cfg) {
+		discord.RespondEphemeral(s, i, "You need officer role or admin permission to use this command.")
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	opt := findOption(options, "is_exception")
+	if opt == nil {
+		discord.RespondEphemeral(s, i, "The is_exception option is required.")
+		return
+	}
+	isException := opt.BoolValue()
+
+	// Get member from database
+	member, err := findMemberOption(s, i, dbx, options)
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, "Member not found. Add them to the roster first.")
+		return
+	} else if err != nil {
+		log.Printf("kdexception error: failed to get member: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve member information. Please try again.")
+		return
+	}
+	if member == nil {
+		discord.RespondEphemeral(s, i, "Please provide either a Discord member or family name.")
+		return
+	}
+
+	err = internal.SetMemberException(dbx, member.ID, isException)
+	if err != nil {
+		log.Printf("kdexception error: failed to update exception status: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to update K/D exception status. Please try again.")
+		return
+	}
+
+	// Send success message
+	statusText := "now counted in guild K/D totals"
+	if isException {
+		statusText = "no longer counted in guild K/D totals; their personal stats are still listed"
+	}
+	discord.RespondText(s, i, fmt.Sprintf("%s is %s.", member.FamilyName, statusText))
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 
@@ -49,7 +50,10 @@ func handleLink(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB
 	if err == sql.ErrNoRows {
 		// Member doesn't exist, create new one with the provided family name
 		memberID, err := internal.CreateMember(dbx, i.GuildID, targetUser.ID, familyName)
-		if err != nil {
+		if errors.Is(err, db.ErrAliasTaken) {
+			discord.RespondEphemeral(s, i, fmt.Sprintf("'%s' is already an alias of another member.", familyName))
+			return
+		} else if err != nil {
 			log.Printf("link create error: %v", err)
 			discord.RespondEphemeral(s, i, "Failed to link member. Please try again.")
 			return
@@ -80,7 +84,10 @@ func handleLink(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB
 	}
 
 	err = internal.UpdateMember(dbx, m.ID, fields)
-	if err != nil {
+	if errors.Is(err, db.ErrAliasTaken) {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("'%s' is already an alias of another member.", familyName))
+		return
+	} else if err != nil {
 		log.Printf("link update error: %v", err)
 		discord.RespondEphemeral(s, i, "Failed to link member. Please try again.")
 		return
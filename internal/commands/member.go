@@ -2,6 +2,7 @@ package commands
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -98,7 +99,10 @@ func handleUpdateSelf(s *discordgo.Session, i *discordgo.InteractionCreate, dbx
 	}
 
 	err = internal.UpdateMember(dbx, m.ID, fields)
-	if err != nil {
+	if errors.Is(err, db.ErrAliasTaken) {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("'%s' is already an alias of another member.", familyName))
+		return
+	} else if err != nil {
 		log.Printf("updateself error: %v", err)
 		discord.RespondEphemeral(s, i, "Failed to update your information. Please try again.")
 		return
@@ -107,7 +111,7 @@ func handleUpdateSelf(s *discordgo.Session, i *discordgo.InteractionCreate, dbx
 	discord.RespondText(s, i, "Your information has been updated successfully.")
 }
 
-func handleGear(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+func handleGear(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, jobs *WarJobQueue) {
 	if !hasGuildMemberPermission(i, cfg) {
 		discord.RespondEphemeral(s, i, "You need guild member role to use this command.")
 		return
@@ -115,21 +119,40 @@ func handleGear(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB
 
 	// Parse options
 	var targetUser *discordgo.User
+	var screenshot *discordgo.MessageAttachment
 	var ap, aap, dp int64
+	statCount := 0
 
-	for _, opt := range i.ApplicationCommandData().Options {
+	data := i.ApplicationCommandData()
+	for _, opt := range data.Options {
 		switch opt.Name {
 		case "member":
 			targetUser = opt.UserValue(s)
 		case "ap":
 			ap = opt.IntValue()
+			statCount++
 		case "aap":
 			aap = opt.IntValue()
+			statCount++
 		case "dp":
 			dp = opt.IntValue()
+			statCount++
+		case "screenshot":
+			if id, ok := opt.Value.(string); ok && data.Resolved != nil {
+				screenshot = data.Resolved.Attachments[id]
+			}
 		}
 	}
 
+	if screenshot != nil && statCount > 0 {
+		discord.RespondEphemeral(s, i, "Please either enter AP, AAP and DP or attach a screenshot, not both.")
+		return
+	}
+	if screenshot == nil && statCount < 3 {
+		discord.RespondEphemeral(s, i, "Please enter AP, AAP and DP, or attach a screenshot of your in-game stat window.")
+		return
+	}
+
 	// Determine if user is updating themselves or another member
 	isOfficer := hasOfficerPermission(s, i, cfg)
 	var userIDToUpdate string
@@ -150,6 +173,11 @@ func handleGear(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB
 		usernameToUpdate = i.Member.User.Username
 	}
 
+	if screenshot != nil {
+		handleGearScreenshot(s, i, dbx, cfg, jobs, userIDToUpdate, usernameToUpdate, screenshot)
+		return
+	}
+
 	// Validate non-negative values
 	if ap < 0 {
 		discord.RespondEphemeral(s, i, "AP cannot be negative.")
@@ -185,6 +213,8 @@ func handleGear(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB
 		AAP:         &aapInt,
 		DP:          &dpInt,
 		DisplayName: &displayName,
+
+		UpdatedByUserID: i.Member.User.ID,
 	}
 
 	err = internal.UpdateMember(dbx, m.ID, fields)
@@ -218,8 +248,7 @@ func handleUpdateMember(s *discordgo.Session, i *discordgo.InteractionCreate, db
 
 	// Parse options
 	var targetUser *discordgo.User
-	var familyName, class, spec, teamsStr string
-	var meetsCap *bool
+	var familyName, class, spec, teamsStr, meetsCapMode string
 	hasUpdates := false
 
 	for _, opt := range i.ApplicationCommandData().Options {
@@ -239,8 +268,7 @@ func handleUpdateMember(s *discordgo.Session, i *discordgo.InteractionCreate, db
 			teamsStr = opt.StringValue()
 			hasUpdates = true
 		case "meets_cap":
-			val := opt.BoolValue()
-			meetsCap = &val
+			meetsCapMode = opt.StringValue()
 			hasUpdates = true
 		}
 	}
@@ -325,17 +353,30 @@ func handleUpdateMember(s *discordgo.Session, i *discordgo.InteractionCreate, db
 	if len(teamIDs) > 0 {
 		fields.TeamIDs = teamIDs
 	}
-	if meetsCap != nil {
-		fields.MeetsCap = meetsCap
-	}
-
 	err = internal.UpdateMember(dbx, m.ID, fields)
-	if err != nil {
+	if errors.Is(err, db.ErrAliasTaken) {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("'%s' is already an alias of another member.", familyName))
+		return
+	} else if err != nil {
 		log.Printf("updatemember error: %v", err)
 		discord.RespondEphemeral(s, i, "Failed to update member information. Please try again.")
 		return
 	}
 
+	// "auto" drops the override and computes meets_cap from the stat caps again
+	if meetsCapMode != "" {
+		var override *bool
+		if meetsCapMode != "auto" {
+			value := meetsCapMode == "yes"
+			override = &value
+		}
+		if err := internal.SetMeetsCapOverride(dbx, m.ID, override); err != nil {
+			log.Printf("updatemember meets_cap error: %v", err)
+			discord.RespondEphemeral(s, i, "Failed to update meets_cap. Please try again.")
+			return
+		}
+	}
+
 	// Update team assignments if provided
 	if len(teamIDs) > 0 {
 		err = internal.AssignMemberToTeams(dbx, m.ID, teamIDs)
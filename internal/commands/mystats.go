@@ -0,0 +1,237 @@
+package commands
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal"
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/discord"
+)
+
+// Defaults and limits of the /mystats wars and weeks options
+const (
+	defaultMyStatsWars  = 5
+	maxMyStatsWars      = 15
+	defaultMyStatsWeeks = 4
+	maxMyStatsWeeks     = 12
+)
+
+func myStatsCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "mystats",
+		Description: "Show your war stats, attendance, vacations and gear (officers can check any member)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "wars",
+				Description: fmt.Sprintf("Number of recent wars to list (default: %d)", defaultMyStatsWars),
+				Required:    false,
+				MinValue:    float64Ptr(1),
+				MaxValue:    maxMyStatsWars,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "weeks",
+				Description: fmt.Sprintf("Number of weeks of attendance to check (default: %d)", defaultMyStatsWeeks),
+				Required:    false,
+				MinValue:    float64Ptr(1),
+				MaxValue:    maxMyStatsWeeks,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "member",
+				Description: "Discord member to show (officers only, leave empty to show yourself)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "family_name",
+				Description: "Family name of the member to show (officers only)",
+				Required:    false,
+			},
+		},
+	}
+}
+
+// memberStatsReport is everything /mystats shows about a member
+type memberStatsReport struct {
+	Member     *internal.Member
+	Totals     db.MemberWarTotals
+	Wars       []db.MemberWar
+	Attendance *internal.MemberAttendance
+	Weeks      int
+	Policy     internal.AttendancePolicy
+	Vacations  []db.Vacation // current and upcoming vacations
+	Location   *time.Location
+}
+
+// formatMemberWarLine formats one war of the recent wars table, marking wars that do not count toward the totals
+func formatMemberWarLine(w db.MemberWar) string {
+	warNumber := fmt.Sprintf("#%d", w.War.ID)
+	if !w.CountsTowardStats() {
+		warNumber += "*"
+	}
+	return fmt.Sprintf("%-6s %-9s %-16s %6d %6d %5.2f\n",
+		warNumber, w.War.WarDate.Format("02-01-06"), truncateString(w.War.Label, 16), w.Kills, w.Deaths, classStatKD(w.Kills, w.Deaths))
+}
+
+// format builds the /mystats message
+func (r memberStatsReport) format() string {
+	m := r.Member
+	var b strings.Builder
+
+	title := fmt.Sprintf("**Stats for %s**", m.FamilyName)
+	if m.Class != nil && *m.Class != "" {
+		title += " - " + *m.Class
+		if m.Spec != nil && *m.Spec != "" {
+			title += " " + strings.Title(*m.Spec)
+		}
+	}
+	b.WriteString(title + "\n\n")
+
+	b.WriteString("**Gear**\n")
+	b.WriteString(formatGearProfile(m) + "\n")
+	verified := "entered by hand"
+	if m.GearVerifiedAt != nil {
+		verified = "verified " + m.GearVerifiedAt.In(r.Location).Format("02-01-06")
+	}
+	b.WriteString(fmt.Sprintf("Meets cap: %s | Gear %s\n\n", formatMeetsCap(m, nil), verified))
+
+	b.WriteString("**War totals**\n")
+	if r.Totals.TotalWars == 0 {
+		b.WriteString("No wars counted yet.\n")
+	} else {
+		b.WriteString(fmt.Sprintf("Wars: %d | Kills: %d | Deaths: %d | K/D: %.2f\n",
+			r.Totals.TotalWars, r.Totals.TotalKills, r.Totals.TotalDeaths, classStatKD(r.Totals.TotalKills, r.Totals.TotalDeaths)))
+	}
+
+	if len(r.Wars) > 0 {
+		b.WriteString(fmt.Sprintf("\n**Last %d wars**\n```\n", len(r.Wars)))
+		b.WriteString(fmt.Sprintf("%-6s %-9s %-16s %6s %6s %5s\n", "War", "Date", "Label", "Kills", "Deaths", "K/D"))
+		uncounted := false
+		for _, w := range r.Wars {
+			b.WriteString(formatMemberWarLine(w))
+			uncounted = uncounted || !w.CountsTowardStats()
+		}
+		b.WriteString("```\n")
+		if uncounted {
+			b.WriteString("\\* Not counted in the totals (excluded war, or excluded from war stats on this date)\n")
+		}
+	}
+
+	b.WriteString(fmt.Sprintf("\n**Attendance (last %d weeks)**\n", r.Weeks))
+	b.WriteString(fmt.Sprintf("Policy: %s\n", r.Policy))
+	if a := r.Attendance; a != nil {
+		b.WriteString(fmt.Sprintf("Weeks: %d | Attended: %d | Missed: %d | Excused: %d\n",
+			a.TotalWeeks, a.AttendedWeeks, len(a.MissedWeeks), a.ExcusedWeeks))
+		for _, week := range a.MissedWeeks {
+			b.WriteString(fmt.Sprintf("• Missed week of %s\n", formatMissedWeek(week)))
+		}
+	}
+
+	b.WriteString("\n**Vacations**\n")
+	if len(r.Vacations) == 0 {
+		b.WriteString("No current or upcoming vacations.\n")
+	}
+	for _, v := range r.Vacations {
+		b.WriteString("• " + formatVacation(v) + "\n")
+	}
+
+	return b.String()
+}
+
+func handleMyStats(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	isOfficer := hasOfficerPermission(s, i, cfg)
+	if !hasGuildMemberPermission(i, cfg) && !isOfficer {
+		discord.RespondEphemeral(s, i, "You need guild member role to use this command.")
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	familyName := stringOption(options, "family_name")
+	var targetUser *discordgo.User
+	if opt := findOption(options, "member"); opt != nil {
+		targetUser = opt.UserValue(s)
+	}
+	isSelf := familyName == "" && (targetUser == nil || targetUser.ID == i.Member.User.ID)
+	if !isSelf && !isOfficer {
+		discord.RespondEphemeral(s, i, "Only officers can see another member's stats.")
+		return
+	}
+
+	warCount := defaultMyStatsWars
+	if v := intOption(options, "wars"); v != nil {
+		warCount = *v
+	}
+	weeks := defaultMyStatsWeeks
+	if v := intOption(options, "weeks"); v != nil {
+		weeks = *v
+	}
+
+	// Get member record
+	var m *internal.Member
+	var err error
+	switch {
+	case familyName != "":
+		m, err = internal.GetMemberByFamilyNameIncludingInactive(dbx, i.GuildID, familyName)
+	case targetUser != nil:
+		m, err = internal.GetMemberByDiscordUserIDIncludingInactive(dbx, i.GuildID, targetUser.ID)
+	default:
+		m, err = internal.GetMemberByDiscordUserIDIncludingInactive(dbx, i.GuildID, i.Member.User.ID)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		if isSelf {
+			discord.RespondEphemeral(s, i, "You are not in the roster yet. Set your gear with /gear or ask an officer to add you.")
+		} else {
+			discord.RespondEphemeral(s, i, "Member not found.")
+		}
+		return
+	} else if err != nil {
+		log.Printf("mystats lookup error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve member information. Please try again.")
+		return
+	}
+
+	report := memberStatsReport{Member: m, Weeks: weeks, Location: cfg.Location()}
+
+	report.Totals, err = db.GetMemberWarTotals(dbx, i.GuildID, m.ID)
+	if err != nil {
+		log.Printf("mystats totals error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve war statistics. Please try again.")
+		return
+	}
+
+	report.Wars, err = db.GetMemberRecentWars(dbx, i.GuildID, m.ID, warCount)
+	if err != nil {
+		log.Printf("mystats wars error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve war statistics. Please try again.")
+		return
+	}
+
+	checker := internal.NewAttendanceChecker(dbx, cfg)
+	report.Policy = checker.Policy()
+	report.Attendance, err = checker.CheckMemberAttendance(i.GuildID, m.ID, weeks)
+	if err != nil {
+		log.Printf("mystats attendance error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to check attendance. Please try again.")
+		return
+	}
+
+	vacations, err := db.GetMemberVacations(dbx, m.ID)
+	if err != nil {
+		log.Printf("mystats vacations error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve vacations. Please try again.")
+		return
+	}
+	report.Vacations = upcomingVacations(vacations, time.Now().In(cfg.Location()))
+
+	// Keep within Discord's 2000 character message limit
+	discord.RespondEphemeral(s, i, truncateString(report.format(), 2000))
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"PanickedBot/internal"
+	"PanickedBot/internal/db"
+)
+
+func TestFormatMemberWarLine(t *testing.T) {
+	date := time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		war  db.MemberWar
+		want string
+	}{
+		{
+			name: "counted war",
+			war:  db.MemberWar{War: db.War{ID: 12, WarDate: date, Label: "Calpheon"}, Kills: 10, Deaths: 4},
+			want: "#12    25-12-24  Calpheon             10      4  2.50\n",
+		},
+		{
+			name: "excluded war",
+			war:  db.MemberWar{War: db.War{ID: 13, WarDate: date, IsExcluded: true}, Kills: 3, Deaths: 0},
+			want: "#13*   25-12-24                        3      0  3.00\n",
+		},
+		{
+			name: "member excluded from war stats",
+			war:  db.MemberWar{War: db.War{ID: 14, WarDate: date}, Kills: 0, Deaths: 2, IsExcluded: true},
+			want: "#14*   25-12-24                        0      2  0.00\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatMemberWarLine(tt.war); got != tt.want {
+				t.Errorf("formatMemberWarLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemberStatsReportFormat(t *testing.T) {
+	class, spec := "Warrior", "awakening"
+	verified := time.Date(2024, 12, 20, 18, 0, 0, 0, time.UTC)
+	date := time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)
+
+	report := memberStatsReport{
+		Member: &internal.Member{
+			FamilyName: "Kethrya", Class: &class, Spec: &spec,
+			AP: intPtr(300), AAP: intPtr(310), DP: intPtr(400), GearVerifiedAt: &verified, MeetsCap: true,
+		},
+		Totals: db.MemberWarTotals{TotalWars: 3, TotalKills: 30, TotalDeaths: 12},
+		Wars: []db.MemberWar{
+			{War: db.War{ID: 12, WarDate: date}, Kills: 10, Deaths: 4},
+			{War: db.War{ID: 11, WarDate: date.AddDate(0, 0, -2), IsExcluded: true}, Kills: 5, Deaths: 5},
+		},
+		Attendance: &internal.MemberAttendance{
+			TotalWeeks: 4, AttendedWeeks: 2, ExcusedWeeks: 1,
+			MissedWeeks: []internal.MissedWeek{{
+				WeekPeriod: internal.WeekPeriod{StartDate: date.AddDate(0, 0, -14)},
+				Attended:   1, Required: 2,
+			}},
+		},
+		Weeks: 4,
+		Vacations: []db.Vacation{
+			{ID: 7, StartDate: date, EndDate: date.AddDate(0, 0, 3), Reason: "Travel"},
+		},
+		Location: time.UTC,
+	}
+
+	got := report.format()
+	for _, want := range []string{
+		"**Stats for Kethrya** - Warrior Awakening",
+		"AP: 300 | AAP: 310 | DP: 400",
+		"Meets cap: yes | Gear verified 20-12-24",
+		"Wars: 3 | Kills: 30 | Deaths: 12 | K/D: 2.50",
+		"**Last 2 wars**",
+		"#11*",
+		"\\* Not counted in the totals",
+		"Weeks: 4 | Attended: 2 | Missed: 1 | Excused: 1",
+		"• Missed week of 11-12-24 (1 of 2 war nights)",
+		"• #7 25-12-24 to 28-12-24 - Travel",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("format() is missing %q in\n%s", want, got)
+		}
+	}
+
+	empty := memberStatsReport{Member: &internal.Member{FamilyName: "Panicked"}, Attendance: &internal.MemberAttendance{}, Weeks: 4, Location: time.UTC}
+	got = empty.format()
+	for _, want := range []string{"**Stats for Panicked**\n", "Gear entered by hand", "No wars counted yet.", "No current or upcoming vacations."} {
+		if !strings.Contains(got, want) {
+			t.Errorf("format() is missing %q in\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "**Last") || strings.Contains(got, "Not counted") {
+		t.Errorf("format() lists wars for a member without wars:\n%s", got)
+	}
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/discord"
+	"PanickedBot/internal/extract"
+)
+
+func setOCRCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "setocr",
+		Description: "Choose the backend used to read war screenshots (admin only)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "backend",
+				Description: "Extraction backend",
+				Required:    true,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "OpenAI", Value: extract.BackendOpenAI},
+					{Name: "OpenAI-compatible endpoint", Value: extract.BackendOpenAICompatible},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "model",
+				Description: "Vision model name (OpenAI default: " + extract.DefaultOpenAIModel + ", required for compatible endpoints)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "base_url",
+				Description: "API base URL, e.g. http://localhost:11434/v1 (required for compatible endpoints)",
+				Required:    false,
+			},
+		},
+	}
+}
+
+// validateOCRBaseURL checks that a base URL is an absolute http(s) URL
+func validateOCRBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("base_url must be an http or https URL")
+	}
+	return nil
+}
+
+func handleSetOCR(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	// Same requirement as /setup, the backend receives the guild's screenshots
+	perms, err := s.UserChannelPermissions(i.Member.User.ID, i.ChannelID)
+	if err != nil {
+		discord.RespondEphemeral(s, i, "Could not verify permissions.")
+		return
+	}
+	if (perms&discordgo.PermissionManageGuild) == 0 && (perms&discordgo.PermissionAdministrator) == 0 {
+		discord.RespondEphemeral(s, i, "You need Manage Server or Administrator permission to run /setocr.")
+		return
+	}
+
+	var backend, model, baseURL string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "backend":
+			backend = opt.StringValue()
+		case "model":
+			model = strings.TrimSpace(opt.StringValue())
+		case "base_url":
+			baseURL = strings.TrimSpace(opt.StringValue())
+		}
+	}
+
+	switch backend {
+	case extract.BackendOpenAI:
+		if baseURL != "" {
+			discord.RespondEphemeral(s, i, "base_url is only used with the OpenAI-compatible backend.")
+			return
+		}
+	case extract.BackendOpenAICompatible:
+		if baseURL == "" || model == "" {
+			discord.RespondEphemeral(s, i, "The OpenAI-compatible backend requires both model and base_url.")
+			return
+		}
+		if err := validateOCRBaseURL(baseURL); err != nil {
+			discord.RespondEphemeral(s, i, err.Error()+".")
+			return
+		}
+	default:
+		discord.RespondEphemeral(s, i, "Unknown backend.")
+		return
+	}
+
+	if err := db.UpdateOCRSettings(dbx, i.GuildID, backend, model, baseURL); err != nil {
+		log.Printf("setocr error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to save OCR settings. Please try again.")
+		return
+	}
+
+	msg := "Screenshot extraction backend: " + backend
+	if model != "" {
+		msg += "\nModel: " + model
+	} else {
+		msg += "\nModel: " + extract.DefaultOpenAIModel + " (default)"
+	}
+	if baseURL != "" {
+		msg += "\nBase URL: " + baseURL
+	}
+
+	discord.RespondEphemeral(s, i, msg)
+}
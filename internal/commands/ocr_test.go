@@ -0,0 +1,26 @@
+package commands
+
+import "testing"
+
+func TestValidateOCRBaseURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		valid   bool
+	}{
+		{"http://localhost:11434/v1", true},
+		{"https://vision.example.com/v1", true},
+		{"localhost:11434", false},
+		{"ftp://example.com", false},
+		{"not a url", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.baseURL, func(t *testing.T) {
+			err := validateOCRBaseURL(tt.baseURL)
+			if (err == nil) != tt.valid {
+				t.Errorf("validateOCRBaseURL(%q) error = %v, expected valid = %v", tt.baseURL, err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal/discord"
+)
+
+// pagePrefix prefixes the custom IDs of the Previous/Next buttons of paginated output.
+// Custom IDs have the form page:<key>:<page>, where page is the page the button shows.
+const pagePrefix = "page"
+
+// maxPageLength keeps each page within Discord's 2000 character message limit
+const maxPageLength = 1990
+
+// pagedMessageTTL is how long the buttons of paginated output keep working
+const pagedMessageTTL = time.Hour
+
+// pagedTable is a code block table split over as many messages as needed.
+// The header, footer and note are repeated on every page.
+type pagedTable struct {
+	Title  string   // bold title above the table
+	Header string   // column headers and separator
+	Lines  []string // one row per line, each ending in a newline
+	Footer string   // rows below the table on every page, e.g. totals
+	Note   string   // text after the code block
+}
+
+// pages splits the table into pages of at most maxPageLength characters.
+// Pages are titled "(page n/N)" when there is more than one.
+func (t pagedTable) pages() []string {
+	// Leave room for the page numbers in the title
+	titleLen := len(t.Title) + len(" (page 999/999)")
+	budget := maxPageLength - titleLen - len("****\n```\n") - len(t.Header) - len(t.Footer) - len("```") - len(t.Note)
+
+	var bodies []string
+	var body strings.Builder
+	for _, line := range t.Lines {
+		if body.Len() > 0 && body.Len()+len(line) > budget {
+			bodies = append(bodies, body.String())
+			body.Reset()
+		}
+		body.WriteString(line)
+	}
+	bodies = append(bodies, body.String())
+
+	pages := make([]string, len(bodies))
+	for idx, lines := range bodies {
+		title := t.Title
+		if len(bodies) > 1 {
+			title = fmt.Sprintf("%s (page %d/%d)", t.Title, idx+1, len(bodies))
+		}
+
+		var b strings.Builder
+		b.WriteString("**" + title + "**\n```\n")
+		b.WriteString(t.Header)
+		b.WriteString(lines)
+		b.WriteString(t.Footer)
+		b.WriteString("```")
+		b.WriteString(t.Note)
+
+		// Guard against single rows longer than a page
+		pages[idx] = truncateString(b.String(), 2000)
+	}
+	return pages
+}
+
+// pagedMessage holds the pages of a message with Previous/Next buttons
+type pagedMessage struct {
+	pages   []string
+	expires time.Time
+}
+
+// pagedMessages keeps the pages of recent paginated output, keyed by the ID of the
+// interaction that created it. Pages are kept in memory only: after a restart or once
+// they expire, the buttons ask to run the command again.
+var pagedMessages = struct {
+	sync.Mutex
+	messages map[string]*pagedMessage
+}{messages: make(map[string]*pagedMessage)}
+
+// pageCustomID builds the custom ID of a button showing the given page
+func pageCustomID(key string, page int) string {
+	return fmt.Sprintf("%s:%s:%d", pagePrefix, key, page)
+}
+
+// parsePageCustomID parses a custom ID built by pageCustomID
+func parsePageCustomID(customID string) (key string, page int, ok bool) {
+	parts := strings.Split(customID, ":")
+	if len(parts) != 3 || parts[0] != pagePrefix || parts[1] == "" {
+		return "", 0, false
+	}
+
+	page, err := strconv.Atoi(parts[2])
+	if err != nil || page < 0 {
+		return "", 0, false
+	}
+	return parts[1], page, true
+}
+
+// pageComponents returns the Previous/Next buttons of a page
+func pageComponents(key string, page, total int) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Previous",
+					Style:    discordgo.SecondaryButton,
+					CustomID: pageCustomID(key, page-1),
+					Disabled: page == 0,
+				},
+				discordgo.Button{
+					Label:    "Next",
+					Style:    discordgo.SecondaryButton,
+					CustomID: pageCustomID(key, page+1),
+					Disabled: page >= total-1,
+				},
+			},
+		},
+	}
+}
+
+// respondPaged responds with the first page and, when there are more, Previous/Next buttons
+func respondPaged(s *discordgo.Session, i *discordgo.InteractionCreate, pages []string) {
+	if len(pages) <= 1 {
+		discord.RespondText(s, i, strings.Join(pages, ""))
+		return
+	}
+
+	key := i.ID
+	now := time.Now()
+
+	pagedMessages.Lock()
+	for k, m := range pagedMessages.messages {
+		if now.After(m.expires) {
+			delete(pagedMessages.messages, k)
+		}
+	}
+	pagedMessages.messages[key] = &pagedMessage{pages: pages, expires: now.Add(pagedMessageTTL)}
+	pagedMessages.Unlock()
+
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:    pages[0],
+			Components: pageComponents(key, 0, len(pages)),
+		},
+	})
+}
+
+// handlePageComponent shows the page requested by a Previous/Next button
+func handlePageComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	key, page, ok := parsePageCustomID(i.MessageComponentData().CustomID)
+	if !ok {
+		discord.RespondEphemeral(s, i, "Unknown action.")
+		return
+	}
+
+	pagedMessages.Lock()
+	message, found := pagedMessages.messages[key]
+	var pages []string
+	if found && time.Now().Before(message.expires) {
+		pages = message.pages
+	}
+	pagedMessages.Unlock()
+
+	if pages == nil {
+		discord.RespondEphemeral(s, i, "These results have expired. Please run the command again.")
+		return
+	}
+	if page >= len(pages) {
+		page = len(pages) - 1
+	}
+
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    pages[page],
+			Components: pageComponents(key, page, len(pages)),
+		},
+	})
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPagedTablePages(t *testing.T) {
+	t.Run("single page", func(t *testing.T) {
+		table := pagedTable{
+			Title:  "War Results",
+			Header: "War Kills\n---------\n",
+			Lines:  []string{"#1      10\n", "#2      12\n"},
+			Footer: "---------\nTOTAL   22\n",
+			Note:   "\nnote",
+		}
+
+		pages := table.pages()
+		expected := "**War Results**\n```\nWar Kills\n---------\n#1      10\n#2      12\n---------\nTOTAL   22\n```\nnote"
+		if len(pages) != 1 || pages[0] != expected {
+			t.Errorf("pages() = %q, expected %q", pages, expected)
+		}
+	})
+
+	t.Run("several pages", func(t *testing.T) {
+		table := pagedTable{
+			Title:  "Guild Roster Members: 200",
+			Header: strings.Repeat("h", 70) + "\n",
+			Footer: "TOTAL\n",
+			Note:   "\nnote",
+		}
+		for n := 0; n < 200; n++ {
+			table.Lines = append(table.Lines, fmt.Sprintf("%-69d\n", n))
+		}
+
+		pages := table.pages()
+		if len(pages) < 2 {
+			t.Fatalf("expected several pages, got %d", len(pages))
+		}
+
+		rows := 0
+		for idx, page := range pages {
+			if len(page) > maxPageLength {
+				t.Errorf("page %d is %d characters, more than %d", idx+1, len(page), maxPageLength)
+			}
+			if !strings.HasPrefix(page, fmt.Sprintf("**Guild Roster Members: 200 (page %d/%d)**\n```\n%s", idx+1, len(pages), table.Header)) {
+				t.Errorf("page %d does not start with its title and header: %q", idx+1, page[:80])
+			}
+			if !strings.HasSuffix(page, "TOTAL\n```\nnote") {
+				t.Errorf("page %d does not end with the footer and note", idx+1)
+			}
+			rows += strings.Count(page, "\n") - 5 // title, code fence, header, footer and the newline before the note
+		}
+		if rows != len(table.Lines) {
+			t.Errorf("pages hold %d rows, expected %d", rows, len(table.Lines))
+		}
+	})
+
+	t.Run("no lines", func(t *testing.T) {
+		if pages := (pagedTable{Title: "Empty"}).pages(); len(pages) != 1 {
+			t.Errorf("expected a single page, got %d", len(pages))
+		}
+	})
+}
+
+func TestPageCustomIDRoundTrip(t *testing.T) {
+	key, page, ok := parsePageCustomID(pageCustomID("1234567890", 3))
+	if !ok || key != "1234567890" || page != 3 {
+		t.Errorf("parsePageCustomID() = %q, %d, %v", key, page, ok)
+	}
+}
+
+func TestParsePageCustomIDInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"page",
+		"page:123",
+		"page::1",
+		"page:123:abc",
+		"page:123:-1",
+		"gearshot:123:1",
+		"page:123:1:2",
+	}
+
+	for _, customID := range invalid {
+		if _, _, ok := parsePageCustomID(customID); ok {
+			t.Errorf("parsePageCustomID(%q) should fail", customID)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 
@@ -16,19 +17,7 @@ import (
 // calculateGS calculates Gear Score as (AP+AAP)/2+DP
 // Assumes 0 for any nil values
 func calculateGS(ap, aap, dp *int) int {
-	apVal := 0
-	if ap != nil {
-		apVal = *ap
-	}
-	aapVal := 0
-	if aap != nil {
-		aapVal = *aap
-	}
-	dpVal := 0
-	if dp != nil {
-		dpVal = *dp
-	}
-	return (apVal+aapVal)/2 + dpVal
+	return internal.GearScore(ap, aap, dp)
 }
 
 // getDisplayNameForRoster returns the display name for a roster member
@@ -77,22 +66,129 @@ func truncateString(s string, maxLen int) string {
 	return s
 }
 
+// Roster sort orders
+const (
+	rosterSortGS         = "gs"
+	rosterSortFamilyName = "family_name"
+	rosterSortClass      = "class"
+	rosterSortJoined     = "joined"
+)
+
+// sortRoster orders members for /roster: by GS (highest first, the default), family name,
+// class (then GS, members without a class last) or join date (oldest first)
+func sortRoster(members []internal.Member, sortBy string) {
+	gs := func(m internal.Member) int {
+		return calculateGS(m.AP, m.AAP, m.DP)
+	}
+	class := func(m internal.Member) string {
+		if m.Class == nil {
+			return ""
+		}
+		return strings.ToLower(*m.Class)
+	}
+
+	sort.SliceStable(members, func(a, b int) bool {
+		switch sortBy {
+		case rosterSortFamilyName:
+			return strings.ToLower(members[a].FamilyName) < strings.ToLower(members[b].FamilyName)
+		case rosterSortClass:
+			classA, classB := class(members[a]), class(members[b])
+			if classA != classB {
+				if classA == "" || classB == "" {
+					return classB == ""
+				}
+				return classA < classB
+			}
+			return gs(members[a]) > gs(members[b])
+		case rosterSortJoined:
+			return members[a].CreatedAt.Before(members[b].CreatedAt)
+		default:
+			return gs(members[a]) > gs(members[b])
+		}
+	})
+}
+
+// rosterFilter holds the /roster filters applied after loading the members
+type rosterFilter struct {
+	Class    string // case-insensitive, all classes when empty
+	Spec     string // all specs when empty
+	MeetsCap *bool  // both when nil
+}
+
+// filterRoster returns the members matching the filter
+func filterRoster(members []internal.Member, filter rosterFilter) []internal.Member {
+	var filtered []internal.Member
+	for _, m := range members {
+		if filter.Class != "" && (m.Class == nil || !strings.EqualFold(*m.Class, filter.Class)) {
+			continue
+		}
+		if filter.Spec != "" && (m.Spec == nil || !strings.EqualFold(*m.Spec, filter.Spec)) {
+			continue
+		}
+		if filter.MeetsCap != nil && m.MeetsCap != *filter.MeetsCap {
+			continue
+		}
+		filtered = append(filtered, m)
+	}
+	return filtered
+}
+
 func handleGetRoster(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
 	if !hasOfficerPermission(s, i, cfg) {
 		discord.RespondEphemeral(s, i, "You need officer role or admin permission to use this command.")
 		return
 	}
 
-	// Get all roster members
-	members, err := internal.GetAllRosterMembers(dbx, i.GuildID)
+	options := i.ApplicationCommandData().Options
+
+	var query internal.RosterFilter
+	var filter rosterFilter
+	view, sortBy := "overview", rosterSortGS
+	for _, opt := range options {
+		switch opt.Name {
+		case "view":
+			view = opt.StringValue()
+		case "sort":
+			sortBy = opt.StringValue()
+		case "team":
+			query.TeamName = opt.StringValue()
+		case "include_mercs":
+			query.IncludeMercs = opt.BoolValue()
+		case "include_inactive":
+			query.IncludeInactive = opt.BoolValue()
+		case "class":
+			filter.Class = strings.TrimSpace(opt.StringValue())
+		case "spec":
+			filter.Spec = opt.StringValue()
+		case "meets_cap":
+			meetsCap := opt.BoolValue()
+			filter.MeetsCap = &meetsCap
+		}
+	}
+
+	// Mercenaries, inactive members and teams are filtered by the query, the rest here
+	members, err := internal.GetRosterMembers(dbx, i.GuildID, query)
 	if err != nil {
 		log.Printf("getroster error: %v", err)
 		discord.RespondEphemeral(s, i, "Failed to retrieve roster members. Please try again.")
 		return
 	}
+	members = filterRoster(members, filter)
 
 	if len(members) == 0 {
-		discord.RespondEphemeral(s, i, "No active roster members found.")
+		if query.TeamName != "" || filter != (rosterFilter{}) {
+			discord.RespondEphemeral(s, i, "No roster members match these filters.")
+		} else {
+			discord.RespondEphemeral(s, i, "No active roster members found.")
+		}
+		return
+	}
+
+	// The caps deciding meets_cap show which stat is over or under them
+	statCap, err := internal.GetCapTierStatCap(dbx, i.GuildID, cfg.CapTier)
+	if err != nil {
+		log.Printf("getroster stat caps error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve stat caps. Please try again.")
 		return
 	}
 
@@ -128,104 +224,109 @@ func handleGetRoster(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *
 		}
 	}
 
-	// Sort members by GS (higher first)
-	sort.Slice(members, func(i, j int) bool {
-		gsI := calculateGS(members[i].AP, members[i].AAP, members[i].DP)
-		gsJ := calculateGS(members[j].AP, members[j].AAP, members[j].DP)
-		return gsI > gsJ
-	})
-
-	// Build response message with aligned columns
-	var response strings.Builder
-	response.WriteString("**Guild Roster Members**\n```\n")
+	sortRoster(members, sortBy)
 
-	// Header
-	header := fmt.Sprintf("%-20s %-20s %-15s %-12s %6s %-9s\n", "Name", "Family Name", "Class", "Spec", "GS", "Meets Cap")
-	response.WriteString(header)
-	response.WriteString(strings.Repeat("-", 90) + "\n")
-
-	// Data rows
-	for _, member := range members {
-		discordName := truncateString(getDisplayNameForRoster(guildMembersMap, &member), 20)
-
-		familyName := truncateString(member.FamilyName, 20)
-
-		class := ""
-		if member.Class != nil && *member.Class != "" {
-			class = truncateString(*member.Class, 15)
-		}
-
-		spec := ""
-		if member.Spec != nil && *member.Spec != "" {
-			spec = truncateString(*member.Spec, 12)
+	// The stats view shows the full gear profile instead of class and cap
+	header := rosterHeader
+	formatLine := func(member *internal.Member) string {
+		return formatRosterLine(getDisplayNameForRoster(guildMembersMap, member), member, statCap)
+	}
+	if view == "stats" {
+		header = rosterStatsHeader
+		formatLine = func(member *internal.Member) string {
+			return formatRosterStatsLine(member, cfg.Location())
 		}
+	}
 
-		gs := calculateGS(member.AP, member.AAP, member.DP)
-		gsStr := ""
-		if gs > 0 {
-			gsStr = fmt.Sprintf("%d", gs)
-		}
+	table := pagedTable{
+		Title:  fmt.Sprintf("Guild Roster Members: %d", len(members)),
+		Header: header + strings.Repeat("-", len(header)-1) + "\n",
+		Lines:  make([]string, len(members)),
+	}
+	for idx := range members {
+		table.Lines[idx] = formatLine(&members[idx])
+	}
 
-		meetsCapStr := "false"
-		if member.MeetsCap {
-			meetsCapStr = "true"
-		}
+	respondPaged(s, i, table.pages())
+}
 
-		response.WriteString(fmt.Sprintf("%-20s %-20s %-15s %-12s %6s %-9s\n", discordName, familyName, class, spec, gsStr, meetsCapStr))
-	}
+var (
+	rosterHeader      = fmt.Sprintf("%-20s %-20s %-15s %-12s %6s %-9s\n", "Name", "Family Name", "Class", "Spec", "GS", "Meets Cap")
+	rosterStatsHeader = fmt.Sprintf("%-16s %4s %4s %4s %5s %5s %6s %5s %5s %5s %-8s\n", "Family Name", "AP", "AAP", "DP", "Eva", "DR", "DRR", "Acc", "HP", "GS", "Verified")
+)
 
-	response.WriteString("```")
+// formatRosterLine formats a member's row of the roster overview
+func formatRosterLine(discordName string, member *internal.Member, statCap *internal.StatCap) string {
+	familyName := truncateString(member.FamilyName, 20)
 
-	// Discord has a 2000 character limit for messages
-	responseText := response.String()
-	if len(responseText) > 2000 {
-		// If too long, show fewer rows
-		var truncatedResponse strings.Builder
-		truncatedResponse.WriteString("**Guild Roster Members** (showing first entries)\n```\n")
-		truncatedResponse.WriteString(header)
-		truncatedResponse.WriteString(strings.Repeat("-", 90) + "\n")
+	class := ""
+	if member.Class != nil && *member.Class != "" {
+		class = truncateString(*member.Class, 15)
+	}
 
-		currentLen := truncatedResponse.Len()
-		const closingLen = 3 // length of "```"
+	spec := ""
+	if member.Spec != nil && *member.Spec != "" {
+		spec = truncateString(*member.Spec, 12)
+	}
 
-		for _, member := range members {
-			discordName := truncateString(getDisplayNameForRoster(guildMembersMap, &member), 20)
+	gs := calculateGS(member.AP, member.AAP, member.DP)
+	gsStr := ""
+	if gs > 0 {
+		gsStr = fmt.Sprintf("%d", gs)
+	}
 
-			familyName := truncateString(member.FamilyName, 20)
+	return fmt.Sprintf("%-20s %-20s %-15s %-12s %6s %s\n", truncateString(discordName, 20), familyName, class, spec, gsStr, formatMeetsCap(member, statCap))
+}
 
-			class := ""
-			if member.Class != nil && *member.Class != "" {
-				class = truncateString(*member.Class, 15)
-			}
+// formatMeetsCap describes whether a member meets the stat caps, listing the stats over or under
+// the caps when statCap decides meets_cap. statCap is nil when meets_cap is set by hand.
+func formatMeetsCap(member *internal.Member, statCap *internal.StatCap) string {
+	yesNo := func(v bool) string {
+		if v {
+			return "yes"
+		}
+		return "no"
+	}
 
-			spec := ""
-			if member.Spec != nil && *member.Spec != "" {
-				spec = truncateString(*member.Spec, 12)
-			}
+	switch {
+	case member.CapOverride != nil:
+		return yesNo(*member.CapOverride) + " (override)"
+	case statCap != nil:
+		if violations := statCap.Violations(member.AP, member.AAP, member.DP); len(violations) > 0 {
+			return strings.Join(violations, ", ")
+		}
+		return "yes"
+	default:
+		return yesNo(member.MeetsCap)
+	}
+}
 
-			gs := calculateGS(member.AP, member.AAP, member.DP)
-			gsStr := ""
-			if gs > 0 {
-				gsStr = fmt.Sprintf("%d", gs)
-			}
+// formatRosterStatsLine formats a member's row of the roster stats view, leaving unset stats blank.
+// Verified shows when the gear was last confirmed from a stat window screenshot.
+func formatRosterStatsLine(member *internal.Member, loc *time.Location) string {
+	stat := func(v *int) string {
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprintf("%d", *v)
+	}
 
-			meetsCapStr := "false"
-			if member.MeetsCap {
-				meetsCapStr = "true"
-			}
+	drr := ""
+	if member.DRR != nil {
+		drr = fmt.Sprintf("%.1f%%", *member.DRR)
+	}
 
-			line := fmt.Sprintf("%-20s %-20s %-15s %-12s %6s %-9s\n", discordName, familyName, class, spec, gsStr, meetsCapStr)
+	gs := ""
+	if value := calculateGS(member.AP, member.AAP, member.DP); value > 0 {
+		gs = fmt.Sprintf("%d", value)
+	}
 
-			// Check if adding this line would exceed the limit
-			if currentLen+len(line)+closingLen > 1990 {
-				break
-			}
-			truncatedResponse.WriteString(line)
-			currentLen += len(line)
-		}
-		truncatedResponse.WriteString("```")
-		discord.RespondText(s, i, truncatedResponse.String())
-	} else {
-		discord.RespondText(s, i, responseText)
+	verified := ""
+	if member.GearVerifiedAt != nil {
+		verified = member.GearVerifiedAt.In(loc).Format("02-01-06")
 	}
+
+	return fmt.Sprintf("%-16s %4s %4s %4s %5s %5s %6s %5s %5s %5s %-8s\n", truncateString(member.FamilyName, 16),
+		stat(member.AP), stat(member.AAP), stat(member.DP), stat(member.Evasion), stat(member.DR), drr,
+		stat(member.Accuracy), stat(member.HP), gs, verified)
 }
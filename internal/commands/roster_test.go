@@ -2,6 +2,9 @@ package commands
 
 import (
 	"testing"
+	"time"
+
+	"PanickedBot/internal"
 )
 
 func TestCalculateGS(t *testing.T) {
@@ -154,6 +157,80 @@ func TestTruncateString(t *testing.T) {
 }
 
 // Helper function for tests
+func TestSortRoster(t *testing.T) {
+	class := func(name string) *string { return &name }
+	joined := func(day int) time.Time { return time.Date(2024, time.March, day, 0, 0, 0, 0, time.UTC) }
+	members := func() []internal.Member {
+		return []internal.Member{
+			{FamilyName: "bravo", Class: class("Warrior"), AP: intPtr(300), AAP: intPtr(300), DP: intPtr(400), CreatedAt: joined(3)},
+			{FamilyName: "Alpha", AP: intPtr(310), AAP: intPtr(310), DP: intPtr(420), CreatedAt: joined(2)},
+			{FamilyName: "Charlie", Class: class("archer"), AP: intPtr(320), AAP: intPtr(320), DP: intPtr(430), CreatedAt: joined(1)},
+			{FamilyName: "Delta", Class: class("Warrior"), AP: intPtr(315), AAP: intPtr(315), DP: intPtr(425), CreatedAt: joined(4)},
+		}
+	}
+
+	tests := []struct {
+		sortBy   string
+		expected []string
+	}{
+		{sortBy: rosterSortGS, expected: []string{"Charlie", "Delta", "Alpha", "bravo"}},
+		{sortBy: "", expected: []string{"Charlie", "Delta", "Alpha", "bravo"}},
+		{sortBy: rosterSortFamilyName, expected: []string{"Alpha", "bravo", "Charlie", "Delta"}},
+		{sortBy: rosterSortClass, expected: []string{"Charlie", "Delta", "bravo", "Alpha"}},
+		{sortBy: rosterSortJoined, expected: []string{"Charlie", "Alpha", "bravo", "Delta"}},
+	}
+
+	for _, tt := range tests {
+		sorted := members()
+		sortRoster(sorted, tt.sortBy)
+
+		var got []string
+		for _, m := range sorted {
+			got = append(got, m.FamilyName)
+		}
+		if len(got) != len(tt.expected) {
+			t.Fatalf("sortRoster(%q) returned %d members", tt.sortBy, len(got))
+		}
+		for idx := range got {
+			if got[idx] != tt.expected[idx] {
+				t.Errorf("sortRoster(%q) = %v, expected %v", tt.sortBy, got, tt.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestFilterRoster(t *testing.T) {
+	warrior, archer, awakening := "Warrior", "Archer", "awakening"
+	yes, no := true, false
+	members := []internal.Member{
+		{FamilyName: "Alpha", Class: &warrior, Spec: &awakening, MeetsCap: true},
+		{FamilyName: "Bravo", Class: &archer, MeetsCap: false},
+		{FamilyName: "Charlie"},
+	}
+
+	tests := []struct {
+		name     string
+		filter   rosterFilter
+		expected int
+	}{
+		{name: "no filter", filter: rosterFilter{}, expected: 3},
+		{name: "class ignores case", filter: rosterFilter{Class: "warrior"}, expected: 1},
+		{name: "spec", filter: rosterFilter{Spec: "awakening"}, expected: 1},
+		{name: "meets cap", filter: rosterFilter{MeetsCap: &yes}, expected: 1},
+		{name: "misses cap", filter: rosterFilter{MeetsCap: &no}, expected: 2},
+		{name: "combined", filter: rosterFilter{Class: "Archer", MeetsCap: &yes}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterRoster(members, tt.filter); len(got) != tt.expected {
+				t.Errorf("filterRoster() returned %d members, expected %d", len(got), tt.expected)
+			}
+		})
+	}
+}
+
 func intPtr(i int) *int {
 	return &i
 }
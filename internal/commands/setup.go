@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"fmt"
+
 	"github.com/bwmarrin/discordgo"
 
 	"PanickedBot/internal"
@@ -41,6 +43,21 @@ func setupCommand() *discordgo.ApplicationCommand {
 				Description: "Role for mercenary members",
 				Required:    false,
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionNumber,
+				Name:        "match_threshold",
+				Description: "Minimum name match confidence (0.5-1.0) for linking imported war lines (default 0.80)",
+				Required:    false,
+				MinValue:    float64Ptr(0.5),
+				MaxValue:    1.0,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "timezone",
+				Description: "IANA timezone for war dates and attendance, e.g. Europe/Berlin (default America/New_York)",
+				Required:    false,
+				MaxLength:   64,
+			},
 		},
 	}
 }
@@ -68,6 +85,8 @@ func handleSetup(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.D
 	var officerRoleID string
 	var guildMemberRoleID string
 	var mercenaryRoleID string
+	var matchThreshold *float64
+	var timezone *string
 
 	for _, opt := range i.ApplicationCommandData().Options {
 		switch opt.Name {
@@ -79,6 +98,17 @@ func handleSetup(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.D
 			guildMemberRoleID = opt.RoleValue(nil, i.GuildID).ID
 		case "mercenary_role":
 			mercenaryRoleID = opt.RoleValue(nil, i.GuildID).ID
+		case "match_threshold":
+			threshold := opt.FloatValue()
+			matchThreshold = &threshold
+		case "timezone":
+			loc, err := internal.LoadTimezone(opt.StringValue())
+			if err != nil {
+				discord.RespondEphemeral(s, i, fmt.Sprintf("Unknown timezone '%s'. Use an IANA name such as Europe/Berlin or America/Los_Angeles.", opt.StringValue()))
+				return
+			}
+			name := loc.String()
+			timezone = &name
 		}
 	}
 
@@ -104,6 +134,8 @@ func handleSetup(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.D
 		internal.NullIfEmptyPtr(officerRoleID),
 		internal.NullIfEmptyPtr(guildMemberRoleID),
 		internal.NullIfEmptyPtr(mercenaryRoleID),
+		matchThreshold,
+		timezone,
 	)
 	if err != nil {
 		discord.RespondEphemeral(s, i, "Failed to save configuration. Please try again.")
@@ -128,5 +160,13 @@ func handleSetup(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.D
 		msg += "\nMercenary role: <@&" + mercenaryRoleID + ">"
 	}
 
+	if matchThreshold != nil {
+		msg += fmt.Sprintf("\nName match threshold: %.2f", *matchThreshold)
+	}
+
+	if timezone != nil {
+		msg += "\nTimezone: " + *timezone
+	}
+
 	discord.RespondEphemeral(s, i, msg)
 }
@@ -0,0 +1,266 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal"
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/discord"
+)
+
+// warTierChoices are the war tiers stat caps can be set for, matching wars.tier
+var warTierChoices = []*discordgo.ApplicationCommandOptionChoice{
+	{Name: "Tier 1", Value: "1"},
+	{Name: "Tier 2", Value: "2"},
+	{Name: "Uncapped", Value: "uncapped"},
+}
+
+func statCapCommand() *discordgo.ApplicationCommand {
+	limitOption := func(name, description string) *discordgo.ApplicationCommandOption {
+		return &discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        name,
+			Description: description,
+			Required:    false,
+			MinValue:    float64Ptr(0),
+			MaxValue:    3000,
+		}
+	}
+
+	return &discordgo.ApplicationCommand{
+		Name:        "statcap",
+		Description: "Manage the stat caps that decide meets_cap (officer role required)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "set",
+				Description: "Set the stat caps of a war tier, replacing the previous ones",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "tier",
+						Description: "War tier",
+						Required:    true,
+						Choices:     warTierChoices,
+					},
+					limitOption("max_ap", "Highest AP allowed"),
+					limitOption("max_aap", "Highest AAP allowed"),
+					limitOption("max_dp", "Highest DP allowed"),
+					limitOption("min_gs", "Lowest gear score required"),
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Remove the stat caps of a war tier",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "tier",
+						Description: "War tier",
+						Required:    true,
+						Choices:     warTierChoices,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List the stat caps of every war tier",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "use",
+				Description: "Choose the war tier whose caps decide meets_cap",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "tier",
+						Description: "War tier, or manual to set meets_cap by hand with /updatemember",
+						Required:    true,
+						Choices: append(append([]*discordgo.ApplicationCommandOptionChoice{}, warTierChoices...),
+							&discordgo.ApplicationCommandOptionChoice{Name: "Manual", Value: "manual"}),
+					},
+				},
+			},
+		},
+	}
+}
+
+func handleStatCap(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	if !hasOfficerPermission(s, i, cfg) {
+		discord.RespondEphemeral(s, i, "You need officer role or admin permission to use this command.")
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		discord.RespondEphemeral(s, i, "Please choose set, remove, list or use.")
+		return
+	}
+
+	subcommand := options[0]
+	switch subcommand.Name {
+	case "set":
+		handleStatCapSet(s, i, dbx, cfg, subcommand.Options)
+	case "remove":
+		handleStatCapRemove(s, i, dbx, cfg, subcommand.Options)
+	case "list":
+		handleStatCapList(s, i, dbx, cfg)
+	case "use":
+		handleStatCapUse(s, i, dbx, subcommand.Options)
+	default:
+		discord.RespondEphemeral(s, i, "Unknown subcommand.")
+	}
+}
+
+// formatTier names a war tier the way the tier choices do
+func formatTier(tier string) string {
+	switch tier {
+	case "uncapped":
+		return "Uncapped"
+	case "":
+		return "Manual"
+	default:
+		return "Tier " + tier
+	}
+}
+
+// formatStatCap describes the limits of a war tier, e.g. "Tier 1: max AP 310, min GS 700"
+func formatStatCap(c internal.StatCap) string {
+	var limits []string
+	limit := func(label string, v *int) {
+		if v != nil {
+			limits = append(limits, fmt.Sprintf("%s %d", label, *v))
+		}
+	}
+	limit("max AP", c.MaxAP)
+	limit("max AAP", c.MaxAAP)
+	limit("max DP", c.MaxDP)
+	limit("min GS", c.MinGS)
+
+	if len(limits) == 0 {
+		return formatTier(c.Tier) + ": no limits"
+	}
+	return formatTier(c.Tier) + ": " + strings.Join(limits, ", ")
+}
+
+// refreshMeetsCapMessage re-evaluates meets_cap after the caps changed and describes the outcome
+func refreshMeetsCapMessage(dbx *db.DB, guildID, capTier string) string {
+	changed, err := internal.RefreshMeetsCap(dbx, guildID, capTier)
+	if err != nil {
+		log.Printf("statcap refresh error: %v", err)
+		return "Failed to re-evaluate meets_cap. It is updated the next time a member's gear changes."
+	}
+	return fmt.Sprintf("meets_cap re-evaluated: %d members changed.", changed)
+}
+
+func handleStatCapSet(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	statCap := internal.StatCap{
+		Tier:   stringOption(options, "tier"),
+		MaxAP:  intOption(options, "max_ap"),
+		MaxAAP: intOption(options, "max_aap"),
+		MaxDP:  intOption(options, "max_dp"),
+		MinGS:  intOption(options, "min_gs"),
+	}
+	if statCap.MaxAP == nil && statCap.MaxAAP == nil && statCap.MaxDP == nil && statCap.MinGS == nil {
+		discord.RespondEphemeral(s, i, "Please provide at least one of max_ap, max_aap, max_dp or min_gs.")
+		return
+	}
+
+	err := db.SetStatCap(dbx, i.GuildID, statCap.Tier, statCap.MaxAP, statCap.MaxAAP, statCap.MaxDP, statCap.MinGS, i.Member.User.ID)
+	if err != nil {
+		log.Printf("statcap set error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to save stat caps. Please try again.")
+		return
+	}
+
+	message := "Stat caps saved. " + formatStatCap(statCap)
+	if statCap.Tier == cfg.CapTier {
+		message += "\n" + refreshMeetsCapMessage(dbx, i.GuildID, cfg.CapTier)
+	} else {
+		message += fmt.Sprintf("\nUse `/statcap use tier:%s` to decide meets_cap with these caps.", formatTier(statCap.Tier))
+	}
+	discord.RespondText(s, i, message)
+}
+
+func handleStatCapRemove(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	tier := stringOption(options, "tier")
+
+	removed, err := db.DeleteStatCap(dbx, i.GuildID, tier)
+	if err != nil {
+		log.Printf("statcap remove error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to remove stat caps. Please try again.")
+		return
+	}
+	if !removed {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("%s has no stat caps.", formatTier(tier)))
+		return
+	}
+
+	message := fmt.Sprintf("Stat caps of %s removed.", formatTier(tier))
+	if tier == cfg.CapTier {
+		message += " meets_cap keeps its current values until caps are set again."
+	}
+	discord.RespondText(s, i, message)
+}
+
+func handleStatCapList(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	caps, err := internal.GetStatCaps(dbx, i.GuildID)
+	if err != nil {
+		log.Printf("statcap list error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve stat caps. Please try again.")
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("**Stat caps**\n")
+	if len(caps) == 0 {
+		b.WriteString("No stat caps set.\n")
+	}
+	for _, c := range caps {
+		b.WriteString("• " + formatStatCap(c) + "\n")
+	}
+	if cfg.CapTier == "" {
+		b.WriteString("meets_cap is set by hand with /updatemember.")
+	} else {
+		b.WriteString(fmt.Sprintf("meets_cap is decided by the caps of %s.", formatTier(cfg.CapTier)))
+	}
+
+	discord.RespondEphemeral(s, i, b.String())
+}
+
+func handleStatCapUse(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	tier := stringOption(options, "tier")
+	if tier == "manual" {
+		tier = ""
+	}
+
+	if err := db.UpdateCapTier(dbx, i.GuildID, tier); err != nil {
+		log.Printf("statcap use error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to save the cap tier. Please try again.")
+		return
+	}
+
+	if tier == "" {
+		discord.RespondText(s, i, "meets_cap is now set by hand with /updatemember.")
+		return
+	}
+
+	statCap, err := internal.GetCapTierStatCap(dbx, i.GuildID, tier)
+	if err != nil {
+		log.Printf("statcap use lookup error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve stat caps. Please try again.")
+		return
+	}
+	if statCap == nil {
+		discord.RespondText(s, i, fmt.Sprintf("meets_cap will be decided by the caps of %s once they are set with /statcap set.", formatTier(tier)))
+		return
+	}
+
+	discord.RespondText(s, i, fmt.Sprintf("meets_cap is now decided by the caps of %s.\n%s", formatStatCap(*statCap), refreshMeetsCapMessage(dbx, i.GuildID, tier)))
+}
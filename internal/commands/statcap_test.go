@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"testing"
+
+	"PanickedBot/internal"
+)
+
+func TestFormatTier(t *testing.T) {
+	tests := map[string]string{
+		"1":        "Tier 1",
+		"2":        "Tier 2",
+		"uncapped": "Uncapped",
+		"":         "Manual",
+	}
+
+	for tier, expected := range tests {
+		if got := formatTier(tier); got != expected {
+			t.Errorf("formatTier(%q) = %q, expected %q", tier, got, expected)
+		}
+	}
+}
+
+func TestFormatStatCap(t *testing.T) {
+	tests := []struct {
+		name     string
+		statCap  internal.StatCap
+		expected string
+	}{
+		{
+			name:     "all limits",
+			statCap:  internal.StatCap{Tier: "1", MaxAP: intPtr(310), MaxAAP: intPtr(310), MaxDP: intPtr(420), MinGS: intPtr(720)},
+			expected: "Tier 1: max AP 310, max AAP 310, max DP 420, min GS 720",
+		},
+		{
+			name:     "minimum only",
+			statCap:  internal.StatCap{Tier: "uncapped", MinGS: intPtr(750)},
+			expected: "Uncapped: min GS 750",
+		},
+		{
+			name:     "no limits",
+			statCap:  internal.StatCap{Tier: "2"},
+			expected: "Tier 2: no limits",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatStatCap(tt.statCap); got != tt.expected {
+				t.Errorf("formatStatCap() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatMeetsCap(t *testing.T) {
+	statCap := &internal.StatCap{Tier: "1", MaxAP: intPtr(310), MinGS: intPtr(720)}
+	yes, no := true, false
+
+	tests := []struct {
+		name     string
+		member   internal.Member
+		statCap  *internal.StatCap
+		expected string
+	}{
+		{
+			name:     "manual",
+			member:   internal.Member{MeetsCap: true},
+			expected: "yes",
+		},
+		{
+			name:     "manual not met",
+			member:   internal.Member{},
+			expected: "no",
+		},
+		{
+			name:     "within caps",
+			member:   internal.Member{AP: intPtr(310), AAP: intPtr(312), DP: intPtr(420)},
+			statCap:  statCap,
+			expected: "yes",
+		},
+		{
+			name:     "over and under caps",
+			member:   internal.Member{AP: intPtr(320), AAP: intPtr(250), DP: intPtr(400)},
+			statCap:  statCap,
+			expected: "AP 320 > 310, GS 685 < 720",
+		},
+		{
+			name:     "override",
+			member:   internal.Member{AP: intPtr(320), CapOverride: &yes},
+			statCap:  statCap,
+			expected: "yes (override)",
+		},
+		{
+			name:     "override not met",
+			member:   internal.Member{MeetsCap: true, CapOverride: &no},
+			expected: "no (override)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatMeetsCap(&tt.member, tt.statCap); got != tt.expected {
+				t.Errorf("formatMeetsCap() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package commands
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal"
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/discord"
+)
+
+// gearStat describes a stat of the gear profile and the values accepted for it
+type gearStat struct {
+	option      string
+	label       string
+	description string
+	max         float64
+}
+
+// gearStats lists the stats /stats can set, in display order.
+// The limits are generous upper bounds meant to catch typos, not game caps.
+var gearStats = []gearStat{
+	{option: "ap", label: "AP", description: "Attack Power (AP)", max: 1000},
+	{option: "aap", label: "AAP", description: "Awakening Attack Power (AAP)", max: 1000},
+	{option: "dp", label: "DP", description: "Defense Power (DP)", max: 1000},
+	{option: "evasion", label: "Evasion", description: "Evasion", max: 5000},
+	{option: "dr", label: "DR", description: "Damage Reduction (DR)", max: 5000},
+	{option: "drr", label: "DRR", description: "Damage Reduction Rate in percent (DRR), e.g. 25.5", max: 100},
+	{option: "accuracy", label: "Accuracy", description: "Accuracy", max: 5000},
+	{option: "hp", label: "HP", description: "Maximum HP", max: 50000},
+	{option: "total_ap", label: "Total AP", description: "Total AP including bonus AP against monsters and players", max: 3000},
+	{option: "total_aap", label: "Total AAP", description: "Total AAP including bonus AP against monsters and players", max: 3000},
+}
+
+func statsCommand() *discordgo.ApplicationCommand {
+	options := make([]*discordgo.ApplicationCommandOption, 0, len(gearStats)+1)
+	for _, stat := range gearStats {
+		optionType := discordgo.ApplicationCommandOptionInteger
+		if stat.option == "drr" {
+			optionType = discordgo.ApplicationCommandOptionNumber
+		}
+		options = append(options, &discordgo.ApplicationCommandOption{
+			Type:        optionType,
+			Name:        stat.option,
+			Description: stat.description,
+			Required:    false,
+			MinValue:    float64Ptr(0),
+			MaxValue:    stat.max,
+		})
+	}
+	options = append(options, &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionUser,
+		Name:        "member",
+		Description: "Discord member to update (officers only, leave empty to update yourself)",
+		Required:    false,
+	})
+
+	return &discordgo.ApplicationCommand{
+		Name:        "stats",
+		Description: "Set or show the full gear profile (your own or another member's if you're an officer)",
+		Options:     options,
+	}
+}
+
+// validateGearStat checks a stat value against the limits of its gearStats entry
+func validateGearStat(option string, value float64) error {
+	for _, stat := range gearStats {
+		if stat.option != option {
+			continue
+		}
+		if value < 0 || value > stat.max {
+			return fmt.Errorf("%s must be between 0 and %g", stat.label, stat.max)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown stat '%s'", option)
+}
+
+// gearStatFields converts /stats options to member update fields, validating each stat.
+// Options that are not stats are ignored.
+func gearStatFields(options []*discordgo.ApplicationCommandInteractionDataOption) (internal.UpdateFields, int, error) {
+	var fields internal.UpdateFields
+	count := 0
+
+	for _, opt := range options {
+		var target **int
+		switch opt.Name {
+		case "ap":
+			target = &fields.AP
+		case "aap":
+			target = &fields.AAP
+		case "dp":
+			target = &fields.DP
+		case "evasion":
+			target = &fields.Evasion
+		case "dr":
+			target = &fields.DR
+		case "accuracy":
+			target = &fields.Accuracy
+		case "hp":
+			target = &fields.HP
+		case "total_ap":
+			target = &fields.TotalAP
+		case "total_aap":
+			target = &fields.TotalAAP
+		case "drr":
+			value := opt.FloatValue()
+			if err := validateGearStat(opt.Name, value); err != nil {
+				return fields, 0, err
+			}
+			fields.DRR = &value
+			count++
+			continue
+		default:
+			continue
+		}
+
+		value := int(opt.IntValue())
+		if err := validateGearStat(opt.Name, float64(value)); err != nil {
+			return fields, 0, err
+		}
+		*target = &value
+		count++
+	}
+
+	return fields, count, nil
+}
+
+// formatGearProfile describes a member's full gear profile, with "-" for stats that were never set
+func formatGearProfile(m *internal.Member) string {
+	stat := func(v *int) string {
+		if v == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%d", *v)
+	}
+
+	drr := "-"
+	if m.DRR != nil {
+		drr = fmt.Sprintf("%.2f%%", *m.DRR)
+	}
+
+	lines := []string{
+		fmt.Sprintf("AP: %s | AAP: %s | DP: %s | GS: %d", stat(m.AP), stat(m.AAP), stat(m.DP), calculateGS(m.AP, m.AAP, m.DP)),
+		fmt.Sprintf("Evasion: %s | DR: %s | DRR: %s | Accuracy: %s | HP: %s", stat(m.Evasion), stat(m.DR), drr, stat(m.Accuracy), stat(m.HP)),
+		fmt.Sprintf("Total AP: %s | Total AAP: %s", stat(m.TotalAP), stat(m.TotalAAP)),
+	}
+	return strings.Join(lines, "\n")
+}
+
+func handleStats(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	isOfficer := hasOfficerPermission(s, i, cfg)
+	if !hasGuildMemberPermission(i, cfg) && !isOfficer {
+		discord.RespondEphemeral(s, i, "You need guild member role to use this command.")
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+
+	// Determine if user is updating themselves or another member
+	targetUser := i.Member.User
+	if opt := findOption(options, "member"); opt != nil {
+		if user := opt.UserValue(s); user != nil {
+			targetUser = user
+		}
+	}
+	isSelf := targetUser.ID == i.Member.User.ID
+	if !isSelf && !isOfficer {
+		discord.RespondEphemeral(s, i, "Only officers can update another member's gear stats.")
+		return
+	}
+
+	fields, count, err := gearStatFields(options)
+	if err != nil {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("Invalid stats: %v.", err))
+		return
+	}
+
+	// Without stats, show the current profile
+	if count == 0 {
+		m, err := internal.GetMemberByDiscordUserIDIncludingInactive(dbx, i.GuildID, targetUser.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			discord.RespondEphemeral(s, i, fmt.Sprintf("%s is not in the roster yet. Set some stats first.", targetUser.Mention()))
+			return
+		} else if err != nil {
+			log.Printf("stats lookup error: %v", err)
+			discord.RespondEphemeral(s, i, "Failed to retrieve gear stats. Please try again.")
+			return
+		}
+
+		discord.RespondEphemeral(s, i, fmt.Sprintf("**Gear profile of %s**\n%s", m.FamilyName, formatGearProfile(m)))
+		return
+	}
+
+	// Get display name from Discord
+	displayName := getDiscordDisplayName(s, i.GuildID, targetUser.ID)
+	fields.DisplayName = &displayName
+	fields.UpdatedByUserID = i.Member.User.ID
+
+	// Get or create member record
+	m, err := getOrCreateMember(dbx, i.GuildID, targetUser.ID, targetUser.Username, "stats")
+	if err != nil {
+		discord.RespondEphemeral(s, i, "Failed to update gear stats. Please try again.")
+		return
+	}
+
+	err = internal.UpdateMember(dbx, m.ID, fields)
+	if err != nil {
+		log.Printf("stats update error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to update gear stats. Please try again.")
+		return
+	}
+
+	// Reload to show the stats that were not changed too
+	updated, err := internal.GetMemberByDiscordUserIDIncludingInactive(dbx, i.GuildID, targetUser.ID)
+	if err != nil {
+		log.Printf("stats reload error: %v", err)
+		discord.RespondText(s, i, "Gear stats updated successfully.")
+		return
+	}
+
+	if isSelf {
+		discord.RespondText(s, i, "Your gear stats have been updated successfully.\n"+formatGearProfile(updated))
+	} else {
+		discord.RespondText(s, i, fmt.Sprintf("Gear stats updated successfully for %s.\n%s", displayName, formatGearProfile(updated)))
+	}
+}
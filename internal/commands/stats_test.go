@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal"
+)
+
+func TestValidateGearStat(t *testing.T) {
+	tests := []struct {
+		option    string
+		value     float64
+		expectErr bool
+	}{
+		{option: "ap", value: 310},
+		{option: "ap", value: 0},
+		{option: "ap", value: 1001, expectErr: true},
+		{option: "drr", value: 25.5},
+		{option: "drr", value: 100.5, expectErr: true},
+		{option: "accuracy", value: -1, expectErr: true},
+		{option: "hp", value: 5600},
+		{option: "speed", value: 1, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.option, func(t *testing.T) {
+			err := validateGearStat(tt.option, tt.value)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("validateGearStat(%s, %g) error = %v, expectErr %v", tt.option, tt.value, err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestGearStatFields(t *testing.T) {
+	options := []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "accuracy", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(1020)},
+		{Name: "drr", Type: discordgo.ApplicationCommandOptionNumber, Value: 25.5},
+		{Name: "member", Type: discordgo.ApplicationCommandOptionUser, Value: "1234"},
+	}
+
+	fields, count, err := gearStatFields(options)
+	if err != nil {
+		t.Fatalf("gearStatFields() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("gearStatFields() count = %d, expected 2", count)
+	}
+	if fields.Accuracy == nil || *fields.Accuracy != 1020 {
+		t.Errorf("gearStatFields() Accuracy = %v, expected 1020", fields.Accuracy)
+	}
+	if fields.DRR == nil || *fields.DRR != 25.5 {
+		t.Errorf("gearStatFields() DRR = %v, expected 25.5", fields.DRR)
+	}
+	if fields.AP != nil || fields.DR != nil {
+		t.Errorf("gearStatFields() set stats that were not provided: AP %v, DR %v", fields.AP, fields.DR)
+	}
+
+	options = append(options, &discordgo.ApplicationCommandInteractionDataOption{
+		Name: "hp", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(60000),
+	})
+	if _, _, err := gearStatFields(options); err == nil {
+		t.Error("gearStatFields() expected an error for HP out of range")
+	}
+}
+
+func TestFormatGearProfile(t *testing.T) {
+	drr := 25.5
+	m := &internal.Member{
+		AP:       intPtr(310),
+		AAP:      intPtr(312),
+		DP:       intPtr(420),
+		DR:       intPtr(800),
+		DRR:      &drr,
+		Accuracy: intPtr(1020),
+	}
+
+	expected := "AP: 310 | AAP: 312 | DP: 420 | GS: 731\n" +
+		"Evasion: - | DR: 800 | DRR: 25.50% | Accuracy: 1020 | HP: -\n" +
+		"Total AP: - | Total AAP: -"
+	if got := formatGearProfile(m); got != expected {
+		t.Errorf("formatGearProfile() = %q, expected %q", got, expected)
+	}
+}
+
+func TestFormatRosterStatsLine(t *testing.T) {
+	drr := 25.5
+	m := &internal.Member{FamilyName: "Hammity", AP: intPtr(310), AAP: intPtr(312), DP: intPtr(420), DRR: &drr}
+
+	line := formatRosterStatsLine(m, time.UTC)
+	if len(line) != len(rosterStatsHeader) {
+		t.Errorf("formatRosterStatsLine() is %d characters, expected %d to align with the header", len(line), len(rosterStatsHeader))
+	}
+
+	expected := "Hammity           310  312  420              25.5%               731         \n"
+	if line != expected {
+		t.Errorf("formatRosterStatsLine() = %q, expected %q", line, expected)
+	}
+
+	// The verification date is shown in the guild timezone
+	verifiedAt := time.Date(2024, time.March, 9, 23, 30, 0, 0, time.UTC)
+	m.GearVerifiedAt = &verifiedAt
+	line = formatRosterStatsLine(m, time.FixedZone("UTC+2", 2*60*60))
+	if len(line) != len(rosterStatsHeader) {
+		t.Errorf("formatRosterStatsLine() is %d characters, expected %d to align with the header", len(line), len(rosterStatsHeader))
+	}
+	if !strings.HasSuffix(line, " 731 10-03-24\n") {
+		t.Errorf("formatRosterStatsLine() = %q, expected the verification date 10-03-24", line)
+	}
+}
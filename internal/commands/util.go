@@ -15,12 +15,6 @@ import (
 // GuildConfig is a type alias for internal.GuildConfig for convenience
 type GuildConfig = internal.GuildConfig
 
-// getEasternLocation returns the Eastern timezone location (America/New_York)
-// This is a convenience wrapper around the internal.GetEasternLocation function
-func getEasternLocation() *time.Location {
-	return internal.GetEasternLocation()
-}
-
 // validClasses is the list of valid Black Desert Online classes
 var validClasses = map[string]bool{
 	"Warrior":     true,
@@ -140,3 +134,30 @@ func getOrCreateMember(dbx *db.DB, guildID, userID, username, contextName string
 
 	return m, nil
 }
+
+// parseOpenDateRange parses optional from and to dates in the guild's timezone, e.g. of a report.
+// Missing dates leave the range open on that side. problem describes invalid input for the user.
+func parseOpenDateRange(fromStr, toStr string, loc *time.Location) (from, to time.Time, problem string) {
+	from = time.Date(1970, 1, 1, 0, 0, 0, 0, loc)
+	to = time.Date(9999, 12, 31, 0, 0, 0, 0, loc)
+
+	if fromStr != "" {
+		date, err := time.ParseInLocation("02-01-06", fromStr, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, "Invalid from date format. Use DD-MM-YY (e.g., 01-12-24)."
+		}
+		from = date
+	}
+	if toStr != "" {
+		date, err := time.ParseInLocation("02-01-06", toStr, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, "Invalid to date format. Use DD-MM-YY (e.g., 31-12-24)."
+		}
+		to = date
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, "The to date must be on or after the from date."
+	}
+	return from, to, ""
+}
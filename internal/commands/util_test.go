@@ -2,6 +2,7 @@ package commands
 
 import (
 	"testing"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -360,3 +361,41 @@ func TestHasGuildMemberPermission(t *testing.T) {
 		})
 	}
 }
+
+func TestParseOpenDateRange(t *testing.T) {
+	loc := time.UTC
+	tests := []struct {
+		name        string
+		from        string
+		to          string
+		wantFrom    string
+		wantTo      string
+		wantProblem bool
+	}{
+		{"open range", "", "", "1970-01-01", "9999-12-31", false},
+		{"both dates", "01-12-24", "31-12-24", "2024-12-01", "2024-12-31", false},
+		{"from only", "01-12-24", "", "2024-12-01", "9999-12-31", false},
+		{"to only", "", "31-12-24", "1970-01-01", "2024-12-31", false},
+		{"invalid from", "2024-12-01", "", "", "", true},
+		{"invalid to", "", "31/12/24", "", "", true},
+		{"to before from", "31-12-24", "01-12-24", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, problem := parseOpenDateRange(tt.from, tt.to, loc)
+			if (problem != "") != tt.wantProblem {
+				t.Fatalf("parseOpenDateRange() problem = %q, wantProblem %v", problem, tt.wantProblem)
+			}
+			if tt.wantProblem {
+				return
+			}
+			if got := from.Format("2006-01-02"); got != tt.wantFrom {
+				t.Errorf("from = %s, want %s", got, tt.wantFrom)
+			}
+			if got := to.Format("2006-01-02"); got != tt.wantTo {
+				t.Errorf("to = %s, want %s", got, tt.wantTo)
+			}
+		})
+	}
+}
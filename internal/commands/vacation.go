@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -14,62 +16,357 @@ import (
 	"PanickedBot/internal/discord"
 )
 
-func handleVacation(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
-	if !hasOfficerPermission(s, i, cfg) {
-		discord.RespondEphemeral(s, i, "You need officer role or admin permission to use this command.")
-		return
+// maxVacationReasonLength matches the member_exceptions.reason column
+const maxVacationReasonLength = 255
+
+func vacationCommand() *discordgo.ApplicationCommand {
+	dateOptions := func(required bool) []*discordgo.ApplicationCommandOption {
+		return []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "start_date",
+				Description: "Vacation start date (DD-MM-YY)",
+				Required:    required,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "end_date",
+				Description: "Vacation end date (DD-MM-YY)",
+				Required:    required,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "reason",
+				Description: "Optional reason for vacation",
+				Required:    false,
+				MaxLength:   maxVacationReasonLength,
+			},
+		}
 	}
 
+	vacationOption := func(description string) *discordgo.ApplicationCommandOption {
+		return &discordgo.ApplicationCommandOption{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "vacation",
+			Description:  description,
+			Required:     true,
+			Autocomplete: true,
+		}
+	}
+
+	return &discordgo.ApplicationCommand{
+		Name:        "vacation",
+		Description: "Request, list and manage vacations that excuse war attendance",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "request",
+				Description: "Request a vacation for yourself",
+				Options:     dateOptions(true),
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "mine",
+				Description: "Show your current, upcoming and pending vacations",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "cancel",
+				Description: "Cancel one of your vacations (officers can cancel any vacation)",
+				Options: []*discordgo.ApplicationCommandOption{
+					vacationOption("Vacation to cancel"),
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Add a vacation period for a member (officer role required)",
+				Options: append([]*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Name:        "member",
+						Description: "Discord member going on vacation",
+						Required:    true,
+					},
+				}, dateOptions(true)...),
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List the guild's current, upcoming and pending vacations (officer role required)",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "edit",
+				Description: "Change the dates or reason of a vacation (officer role required)",
+				Options: append([]*discordgo.ApplicationCommandOption{
+					vacationOption("Vacation to edit"),
+				}, dateOptions(false)...),
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "approve",
+				Description: "Approve a vacation requested by a member (officer role required)",
+				Options: []*discordgo.ApplicationCommandOption{
+					vacationOption("Pending vacation to approve"),
+				},
+			},
+		},
+	}
+}
+
+func handleVacation(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
 	options := i.ApplicationCommandData().Options
-	if len(options) < 3 {
-		discord.RespondEphemeral(s, i, "Member, start_date, and end_date are required.")
+	if len(options) == 0 {
+		discord.RespondEphemeral(s, i, "Please choose a vacation subcommand.")
 		return
 	}
 
-	// Parse options
-	var targetUser *discordgo.User
-	var startDateStr, endDateStr, reason string
-	
-	for _, opt := range options {
-		switch opt.Name {
-		case "member":
-			targetUser = opt.UserValue(s)
-		case "start_date":
-			startDateStr = opt.StringValue()
-		case "end_date":
-			endDateStr = opt.StringValue()
-		case "reason":
-			reason = opt.StringValue()
+	subcommand := options[0]
+	switch subcommand.Name {
+	case "request", "mine", "cancel":
+		// Members manage their own vacations; officers may use these too
+		if !hasGuildMemberPermission(i, cfg) && !hasOfficerPermission(s, i, cfg) {
+			discord.RespondEphemeral(s, i, "You need guild member role to use this command.")
+			return
+		}
+	default:
+		if !hasOfficerPermission(s, i, cfg) {
+			discord.RespondEphemeral(s, i, "You need officer role or admin permission to use this command.")
+			return
 		}
 	}
 
-	if targetUser == nil {
-		discord.RespondEphemeral(s, i, "Member is required.")
+	switch subcommand.Name {
+	case "request":
+		handleVacationRequest(s, i, dbx, cfg, subcommand.Options)
+	case "mine":
+		handleVacationMine(s, i, dbx, cfg)
+	case "cancel":
+		handleVacationCancel(s, i, dbx, cfg, subcommand.Options)
+	case "add":
+		handleVacationAdd(s, i, dbx, cfg, subcommand.Options)
+	case "list":
+		handleVacationList(s, i, dbx, cfg)
+	case "edit":
+		handleVacationEdit(s, i, dbx, cfg, subcommand.Options)
+	case "approve":
+		handleVacationApprove(s, i, dbx, subcommand.Options)
+	default:
+		discord.RespondEphemeral(s, i, "Unknown subcommand.")
+	}
+}
+
+// parseDateRange parses the start and end dates of a vacation or exclusion in the guild's timezone
+// and checks that the end is not before the start. problem describes invalid input for the user.
+func parseDateRange(startDateStr, endDateStr string, loc *time.Location) (startDate, endDate time.Time, problem string) {
+	startDate, err := time.ParseInLocation("02-01-06", startDateStr, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, "Invalid start date format. Use DD-MM-YY (e.g., 25-12-24)."
+	}
+
+	endDate, err = time.ParseInLocation("02-01-06", endDateStr, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, "Invalid end date format. Use DD-MM-YY (e.g., 31-12-24)."
+	}
+
+	if endDate.Before(startDate) {
+		return time.Time{}, time.Time{}, "End date must be on or after start date."
+	}
+
+	return startDate, endDate, ""
+}
+
+// parseIDOption parses an autocompleted option holding a vacation or exclusion ID, with an optional leading '#'
+func parseIDOption(value string) (int64, bool) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(value), "#"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// formatVacation describes a vacation for messages and autocomplete choices
+func formatVacation(v db.Vacation) string {
+	text := fmt.Sprintf("#%d %s: %s to %s", v.ID, v.FamilyName, v.StartDate.Format("02-01-06"), v.EndDate.Format("02-01-06"))
+	if v.FamilyName == "" {
+		text = fmt.Sprintf("#%d %s to %s", v.ID, v.StartDate.Format("02-01-06"), v.EndDate.Format("02-01-06"))
+	}
+	if v.IsPending() {
+		text += " (pending approval)"
+	}
+	if v.Reason != "" {
+		text += " - " + v.Reason
+	}
+	return text
+}
+
+// upcomingVacations returns the vacations that have not ended before today.
+// Vacation dates are calendar days, so today is compared as a calendar day too.
+func upcomingVacations(vacations []db.Vacation, today time.Time) []db.Vacation {
+	day := internal.CalendarDate(today, time.UTC)
+	upcoming := make([]db.Vacation, 0, len(vacations))
+	for _, v := range vacations {
+		if !internal.CalendarDate(v.EndDate, time.UTC).Before(day) {
+			upcoming = append(upcoming, v)
+		}
+	}
+	return upcoming
+}
+
+// startsInPast reports whether a vacation starting on startDate, parsed in the guild's timezone, starts before today
+func startsInPast(startDate, now time.Time) bool {
+	return startDate.Before(internal.CalendarDate(now.In(startDate.Location()), startDate.Location()))
+}
+
+// lookupVacation resolves the vacation option of a subcommand, responding to the user when it cannot be found
+func lookupVacation(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, options []*discordgo.ApplicationCommandInteractionDataOption, contextName string) *db.Vacation {
+	id, ok := parseIDOption(stringOption(options, "vacation"))
+	if !ok {
+		discord.RespondEphemeral(s, i, "Please pick a vacation from the list.")
+		return nil
+	}
+
+	vacation, err := db.GetVacation(dbx, i.GuildID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("Vacation #%d not found.", id))
+		return nil
+	} else if err != nil {
+		log.Printf("%s error: failed to get vacation: %v", contextName, err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve vacation. Please try again.")
+		return nil
+	}
+
+	return vacation
+}
+
+func handleVacationRequest(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	startDate, endDate, problem := parseDateRange(stringOption(options, "start_date"), stringOption(options, "end_date"), cfg.Location())
+	if problem != "" {
+		discord.RespondEphemeral(s, i, problem)
 		return
 	}
+	reason := stringOption(options, "reason")
 
-	// Get Eastern timezone
-	est := getEasternLocation()
+	// Members cannot excuse wars they already missed; officers can still add past vacations
+	isOfficer := hasOfficerPermission(s, i, cfg)
+	if !isOfficer && startsInPast(startDate, time.Now()) {
+		discord.RespondEphemeral(s, i, "Start date cannot be in the past. Ask an officer to add a vacation for days you already missed.")
+		return
+	}
 
-	// Parse dates in Eastern timezone
-	startDate, err := time.ParseInLocation("02-01-06", startDateStr, est)
+	member, err := getOrCreateMember(dbx, i.GuildID, i.Member.User.ID, i.Member.User.Username, "vacation request")
 	if err != nil {
-		discord.RespondEphemeral(s, i, "Invalid start date format. Use DD-MM-YY (e.g., 25-12-24).")
+		discord.RespondEphemeral(s, i, "Failed to retrieve your member information. Please try again.")
 		return
 	}
 
-	endDate, err := time.ParseInLocation("02-01-06", endDateStr, est)
+	// Officers' own requests never wait for approval
+	pending := cfg.VacationApproval && !isOfficer
+
+	id, err := db.CreateVacation(dbx, i.GuildID, member.ID, startDate, endDate, reason, i.Member.User.ID, pending)
 	if err != nil {
-		discord.RespondEphemeral(s, i, "Invalid end date format. Use DD-MM-YY (e.g., 31-12-24).")
+		log.Printf("vacation request error: failed to create vacation: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to create vacation entry. Please try again.")
 		return
 	}
 
-	// Validate date range
-	if endDate.Before(startDate) {
-		discord.RespondEphemeral(s, i, "End date must be on or after start date.")
+	vacation := db.Vacation{ID: id, FamilyName: member.FamilyName, StartDate: startDate, EndDate: endDate, Reason: reason}
+	if pending {
+		vacation.Status = db.VacationPending
+		discord.RespondText(s, i, fmt.Sprintf("Vacation requested by %s: %s. An officer needs to approve it before it excuses attendance.",
+			i.Member.User.Mention(), formatVacation(vacation)))
+		return
+	}
+
+	discord.RespondText(s, i, fmt.Sprintf("Vacation added for %s: %s.", i.Member.User.Mention(), formatVacation(vacation)))
+}
+
+func handleVacationMine(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	member, err := internal.GetMemberByDiscordUserIDIncludingInactive(dbx, i.GuildID, i.Member.User.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, "You have no current or upcoming vacations.")
+		return
+	} else if err != nil {
+		log.Printf("vacation mine error: failed to get member: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve your vacations. Please try again.")
+		return
+	}
+
+	vacations, err := db.GetMemberVacations(dbx, member.ID)
+	if err != nil {
+		log.Printf("vacation mine error: failed to get vacations: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve your vacations. Please try again.")
+		return
+	}
+
+	vacations = upcomingVacations(vacations, time.Now().In(cfg.Location()))
+	if len(vacations) == 0 {
+		discord.RespondEphemeral(s, i, "You have no current or upcoming vacations.")
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("**Your vacations**\n")
+	// Most recent first, as returned by the query
+	for _, v := range vacations {
+		b.WriteString("• " + formatVacation(v) + "\n")
+	}
+
+	discord.RespondEphemeral(s, i, truncateString(b.String(), 2000))
+}
+
+func handleVacationCancel(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	vacation := lookupVacation(s, i, dbx, options, "vacation cancel")
+	if vacation == nil {
+		return
+	}
+
+	// Members can only cancel their own vacations
+	if !hasOfficerPermission(s, i, cfg) {
+		member, err := internal.GetMemberByDiscordUserIDIncludingInactive(dbx, i.GuildID, i.Member.User.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("vacation cancel error: failed to get member: %v", err)
+			discord.RespondEphemeral(s, i, "Failed to cancel vacation. Please try again.")
+			return
+		}
+		if member == nil || member.ID != vacation.RosterMemberID {
+			discord.RespondEphemeral(s, i, "You can only cancel your own vacations.")
+			return
+		}
+	}
+
+	deleted, err := db.DeleteVacation(dbx, i.GuildID, vacation.ID)
+	if err != nil {
+		log.Printf("vacation cancel error: failed to delete vacation: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to cancel vacation. Please try again.")
+		return
+	}
+	if !deleted {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("Vacation #%d not found.", vacation.ID))
 		return
 	}
 
+	discord.RespondText(s, i, fmt.Sprintf("Cancelled vacation %s.", formatVacation(*vacation)))
+}
+
+func handleVacationAdd(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	var targetUser *discordgo.User
+	if opt := findOption(options, "member"); opt != nil {
+		targetUser = opt.UserValue(s)
+	}
+	if targetUser == nil {
+		discord.RespondEphemeral(s, i, "Member is required.")
+		return
+	}
+
+	startDate, endDate, problem := parseDateRange(stringOption(options, "start_date"), stringOption(options, "end_date"), cfg.Location())
+	if problem != "" {
+		discord.RespondEphemeral(s, i, problem)
+		return
+	}
+	reason := stringOption(options, "reason")
+
 	// Get member from database
 	member, err := internal.GetMemberByDiscordUserIDIncludingInactive(dbx, i.GuildID, targetUser.ID)
 	if err != nil {
@@ -82,8 +379,8 @@ func handleVacation(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *d
 		return
 	}
 
-	// Create vacation entry
-	_, err = db.CreateVacation(dbx, i.GuildID, member.ID, startDate, endDate, reason, i.Member.User.ID)
+	// Vacations added by officers are approved right away
+	_, err = db.CreateVacation(dbx, i.GuildID, member.ID, startDate, endDate, reason, i.Member.User.ID, false)
 	if err != nil {
 		log.Printf("vacation error: failed to create vacation: %v", err)
 		discord.RespondEphemeral(s, i, "Failed to create vacation entry. Please try again.")
@@ -102,3 +399,154 @@ func handleVacation(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *d
 		endDate.Format("02-01-06"),
 		reasonText))
 }
+
+func handleVacationList(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	vacations, err := db.GetActiveVacations(dbx, i.GuildID, time.Now().In(cfg.Location()))
+	if err != nil {
+		log.Printf("vacation list error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to retrieve vacations. Please try again.")
+		return
+	}
+
+	if len(vacations) == 0 {
+		discord.RespondEphemeral(s, i, "No current or upcoming vacations.")
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("**Current and upcoming vacations (%d)**\n", len(vacations)))
+	for _, v := range vacations {
+		b.WriteString("• " + formatVacation(v) + "\n")
+	}
+
+	// Keep within Discord's 2000 character message limit
+	discord.RespondEphemeral(s, i, truncateString(b.String(), 2000))
+}
+
+func handleVacationEdit(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	vacation := lookupVacation(s, i, dbx, options, "vacation edit")
+	if vacation == nil {
+		return
+	}
+
+	// Options left out keep their current value
+	startDateStr := vacation.StartDate.Format("02-01-06")
+	endDateStr := vacation.EndDate.Format("02-01-06")
+	reason := vacation.Reason
+	changed := false
+
+	if opt := findOption(options, "start_date"); opt != nil {
+		startDateStr = opt.StringValue()
+		changed = true
+	}
+	if opt := findOption(options, "end_date"); opt != nil {
+		endDateStr = opt.StringValue()
+		changed = true
+	}
+	if opt := findOption(options, "reason"); opt != nil {
+		reason = strings.TrimSpace(opt.StringValue())
+		changed = true
+	}
+
+	if !changed {
+		discord.RespondEphemeral(s, i, "Please provide at least one field to update.")
+		return
+	}
+
+	startDate, endDate, problem := parseDateRange(startDateStr, endDateStr, cfg.Location())
+	if problem != "" {
+		discord.RespondEphemeral(s, i, problem)
+		return
+	}
+
+	err := db.UpdateVacation(dbx, i.GuildID, vacation.ID, startDate, endDate, reason)
+	if err != nil {
+		log.Printf("vacation edit error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to update vacation. Please try again.")
+		return
+	}
+
+	vacation.StartDate = startDate
+	vacation.EndDate = endDate
+	vacation.Reason = reason
+	discord.RespondText(s, i, fmt.Sprintf("Vacation updated: %s.", formatVacation(*vacation)))
+}
+
+func handleVacationApprove(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	vacation := lookupVacation(s, i, dbx, options, "vacation approve")
+	if vacation == nil {
+		return
+	}
+
+	if !vacation.IsPending() {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("Vacation #%d is already approved.", vacation.ID))
+		return
+	}
+
+	approved, err := db.ApproveVacation(dbx, i.GuildID, vacation.ID, i.Member.User.ID)
+	if err != nil {
+		log.Printf("vacation approve error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to approve vacation. Please try again.")
+		return
+	}
+	if !approved {
+		// Approved or cancelled by someone else in the meantime
+		discord.RespondEphemeral(s, i, fmt.Sprintf("Vacation #%d is no longer pending.", vacation.ID))
+		return
+	}
+
+	vacation.Status = db.VacationApproved
+	discord.RespondText(s, i, fmt.Sprintf("Approved vacation %s.", formatVacation(*vacation)))
+}
+
+// handleVacationAutocomplete suggests vacations by ID. Officers get every current and upcoming
+// vacation of the guild, and only pending ones when approving; members only get their own.
+func handleVacationAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, search string) {
+	today := time.Now().In(cfg.Location())
+
+	var vacations []db.Vacation
+	var err error
+	if hasOfficerPermission(s, i, cfg) {
+		vacations, err = db.GetActiveVacations(dbx, i.GuildID, today)
+	} else if hasGuildMemberPermission(i, cfg) {
+		var member *internal.Member
+		member, err = internal.GetMemberByDiscordUserIDIncludingInactive(dbx, i.GuildID, i.Member.User.ID)
+		if err == nil {
+			vacations, err = db.GetMemberVacations(dbx, member.ID)
+			vacations = upcomingVacations(vacations, today)
+		}
+	}
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("vacation autocomplete error: %v", err)
+		}
+		discord.RespondAutocomplete(s, i, nil)
+		return
+	}
+
+	pendingOnly := false
+	if options := i.ApplicationCommandData().Options; len(options) > 0 {
+		pendingOnly = options[0].Name == "approve"
+	}
+
+	search = strings.ToLower(strings.TrimSpace(search))
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, maxAutocompleteChoices)
+	for _, v := range vacations {
+		if pendingOnly && !v.IsPending() {
+			continue
+		}
+		name := formatVacation(v)
+		if search != "" && !strings.Contains(strings.ToLower(name), search) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncateString(name, 100),
+			Value: strconv.FormatInt(v.ID, 10),
+		})
+		if len(choices) == maxAutocompleteChoices {
+			break
+		}
+	}
+
+	discord.RespondAutocomplete(s, i, choices)
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"PanickedBot/internal/db"
+)
+
+func TestParseDateRange(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone database not available: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		start, end    string
+		expectProblem bool
+	}{
+		{name: "valid range", start: "24-12-24", end: "31-12-24"},
+		{name: "single day", start: "25-12-24", end: "25-12-24"},
+		{name: "end before start", start: "31-12-24", end: "24-12-24", expectProblem: true},
+		{name: "invalid start", start: "2024-12-24", end: "31-12-24", expectProblem: true},
+		{name: "invalid end", start: "24-12-24", end: "31/12/24", expectProblem: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, problem := parseDateRange(tt.start, tt.end, loc)
+			if (problem != "") != tt.expectProblem {
+				t.Fatalf("parseDateRange() problem = %q, expectProblem %v", problem, tt.expectProblem)
+			}
+			if tt.expectProblem {
+				return
+			}
+			if start.Location() != loc || start.Format("02-01-06") != tt.start || end.Format("02-01-06") != tt.end {
+				t.Errorf("parseDateRange() = %v, %v, expected %s to %s in %s", start, end, tt.start, tt.end, loc)
+			}
+		})
+	}
+}
+
+func TestParseIDOption(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+		ok       bool
+	}{
+		{value: "12", expected: 12, ok: true},
+		{value: "#7", expected: 7, ok: true},
+		{value: " 3 ", expected: 3, ok: true},
+		{value: "0", ok: false},
+		{value: "Hammity", ok: false},
+		{value: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			id, ok := parseIDOption(tt.value)
+			if id != tt.expected || ok != tt.ok {
+				t.Errorf("parseIDOption(%q) = %d, %v, expected %d, %v", tt.value, id, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFormatVacation(t *testing.T) {
+	start := time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		vacation db.Vacation
+		expected string
+	}{
+		{
+			name:     "approved with member",
+			vacation: db.Vacation{ID: 12, FamilyName: "Hammity", StartDate: start, EndDate: end, Status: db.VacationApproved},
+			expected: "#12 Hammity: 24-12-24 to 31-12-24",
+		},
+		{
+			name:     "pending with reason",
+			vacation: db.Vacation{ID: 3, FamilyName: "Hammity", StartDate: start, EndDate: end, Reason: "Holidays", Status: db.VacationPending},
+			expected: "#3 Hammity: 24-12-24 to 31-12-24 (pending approval) - Holidays",
+		},
+		{
+			name:     "without member",
+			vacation: db.Vacation{ID: 5, StartDate: start, EndDate: end, Status: db.VacationApproved},
+			expected: "#5 24-12-24 to 31-12-24",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatVacation(tt.vacation); got != tt.expected {
+				t.Errorf("formatVacation() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUpcomingVacations(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 12, d, 0, 0, 0, 0, time.UTC) }
+	vacations := []db.Vacation{
+		{ID: 1, StartDate: day(1), EndDate: day(9)},
+		{ID: 2, StartDate: day(5), EndDate: day(10)},
+		{ID: 3, StartDate: day(20), EndDate: day(24)},
+	}
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone database not available: %v", err)
+	}
+
+	// Late evening of the 10th in the guild's timezone is already the 11th in UTC
+	today := time.Date(2024, 12, 10, 22, 0, 0, 0, loc)
+	got := upcomingVacations(vacations, today)
+	if len(got) != 2 || got[0].ID != 2 || got[1].ID != 3 {
+		t.Errorf("upcomingVacations() = %v, expected vacations 2 and 3", got)
+	}
+}
+
+func TestStartsInPast(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone database not available: %v", err)
+	}
+
+	// Late evening of the 10th in the guild's timezone is already the 11th in UTC
+	now := time.Date(2024, 12, 11, 3, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		start string
+		want  bool
+	}{
+		{"yesterday", "09-12-24", true},
+		{"today", "10-12-24", false},
+		{"tomorrow", "11-12-24", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, err := time.ParseInLocation("02-01-06", tt.start, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := startsInPast(start, now); got != tt.want {
+				t.Errorf("startsInPast(%s) = %v, want %v", tt.start, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,417 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal"
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/extract"
+)
+
+// WarJobQueue processes queued /addwar imports in the background.
+// Jobs and their attachments live in war_jobs and war_job_attachments,
+// so work interrupted by a restart is picked up again on the next start.
+type WarJobQueue struct {
+	session *discordgo.Session
+	db      *db.DB
+	wake    chan struct{}
+
+	// newExtractor and newGearExtractor create the screenshot extractors of a guild;
+	// tests replace them to import screenshots through extract.Fake
+	newExtractor     func(cfg *GuildConfig) (extract.Extractor, error)
+	newGearExtractor func(cfg *GuildConfig) (extract.GearExtractor, error)
+}
+
+// NewWarJobQueue creates a new war import queue; credentials are used by the screenshot extraction backends
+func NewWarJobQueue(s *discordgo.Session, database *db.DB, credentials extract.Credentials) *WarJobQueue {
+	return &WarJobQueue{
+		session: s,
+		db:      database,
+		wake:    make(chan struct{}, 1),
+		newExtractor: func(cfg *GuildConfig) (extract.Extractor, error) {
+			return extract.New(extractConfig(cfg), credentials)
+		},
+		newGearExtractor: func(cfg *GuildConfig) (extract.GearExtractor, error) {
+			return extract.NewGearExtractor(extractConfig(cfg), credentials)
+		},
+	}
+}
+
+// Start requeues jobs interrupted by a previous run and processes queued jobs until ctx is canceled
+func (q *WarJobQueue) Start(ctx context.Context) {
+	go q.run(ctx)
+}
+
+// Notify wakes the worker after a new job has been queued
+func (q *WarJobQueue) Notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+		// A wake-up is already pending
+	}
+}
+
+func (q *WarJobQueue) run(ctx context.Context) {
+	requeued, err := db.RequeueInterruptedWarJobs(q.db)
+	if err != nil {
+		log.Printf("war jobs: failed to requeue interrupted jobs: %v", err)
+	} else if requeued > 0 {
+		log.Printf("war jobs: requeued %d interrupted jobs", requeued)
+	}
+
+	// Poll as a fallback in case a wake-up is missed
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		q.processQueued(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// processQueued processes every queued job, oldest first
+func (q *WarJobQueue) processQueued(ctx context.Context) {
+	jobIDs, err := db.GetQueuedWarJobIDs(q.db)
+	if err != nil {
+		log.Printf("war jobs: failed to list queued jobs: %v", err)
+		return
+	}
+
+	for _, jobID := range jobIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		q.processJob(jobID)
+	}
+}
+
+// processJob claims a job, extracts its war data and creates the war
+func (q *WarJobQueue) processJob(jobID int64) {
+	claimed, err := db.ClaimWarJob(q.db, jobID)
+	if err != nil {
+		log.Printf("war jobs: failed to claim job %d: %v", jobID, err)
+		return
+	}
+	if !claimed {
+		// Another worker got to it first, or it was canceled
+		return
+	}
+
+	job, err := db.GetWarJob(q.db, jobID)
+	if err != nil {
+		log.Printf("war jobs: failed to load job %d: %v", jobID, err)
+		if err := db.FailWarJob(q.db, jobID, err.Error()); err != nil {
+			log.Printf("war jobs: failed to mark job %d as failed: %v", jobID, err)
+		}
+		return
+	}
+
+	// Never leave a job stuck in processing because of a panic
+	defer func() {
+		if r := recover(); r != nil {
+			q.fail(job, fmt.Errorf("unexpected error: %v", r))
+		}
+	}()
+
+	cfg, err := internal.LoadGuildConfig(q.db, job.DiscordGuildID)
+	if err != nil {
+		q.fail(job, fmt.Errorf("failed to load guild configuration: %w", err))
+		return
+	}
+
+	warDate, warLines, err := q.extractWarData(job, cfg)
+	if err != nil {
+		q.fail(job, err)
+		return
+	}
+
+	lines, err := db.MatchWarLines(q.db, job.DiscordGuildID, cfg.MatchThreshold, warLines)
+	if err != nil {
+		q.fail(job, err)
+		return
+	}
+
+	// Overlapping screenshots list some members more than once
+	lines = db.MergeWarLines(lines)
+
+	// Hold imports with uncertain or unknown names until an officer reviews them
+	if needsReview(lines) {
+		q.holdForReview(job, warDate, lines)
+		return
+	}
+
+	summary, err := db.CreateWarFromCSV(q.db, job.DiscordGuildID, job.ID, warDate, job.Result, job.WarType, job.Tier, job.Label, lines)
+	if err != nil {
+		q.fail(job, err)
+		return
+	}
+
+	log.Printf("war jobs: job %d imported %d entries for %s", job.ID, len(lines), warDate.Format("02-01-06"))
+
+	q.report(job, warImportedMessage(job, warDate, summary))
+}
+
+// holdForReview stores the matched lines and posts the review message in the request channel
+func (q *WarJobQueue) holdForReview(job *db.WarJob, warDate time.Time, lines []db.MatchedWarLine) {
+	if err := db.HoldWarJobForReview(q.db, job.ID, warDate, lines); err != nil {
+		q.fail(job, err)
+		return
+	}
+	job.WarDate = warDate
+
+	content, components := buildWarReviewMessage(job, lines)
+	msg, err := q.session.ChannelMessageSendComplex(job.RequestChannelID, &discordgo.MessageSend{
+		Content:    content,
+		Components: components,
+	})
+	if err != nil {
+		// Without the review message nobody can confirm the import
+		q.fail(job, fmt.Errorf("failed to post review message: %w", err))
+		return
+	}
+
+	if err := db.SetWarJobReviewMessage(q.db, job.ID, msg.ID); err != nil {
+		log.Printf("war jobs: failed to record review message for job %d: %v", job.ID, err)
+	}
+
+	log.Printf("war jobs: job %d held for review (%d entries for %s)", job.ID, len(lines), warDate.Format("02-01-06"))
+}
+
+// warImportedMessage builds the success report of a finished import
+func warImportedMessage(job *db.WarJob, warDate time.Time, summary *db.WarImportSummary) string {
+	msg := fmt.Sprintf("<@%s> War data imported successfully! (job #%d)\nWar: %s\nResult: %s\nType: %s\nTier: %s\nEntries: %d",
+		job.RequestedByUserID, job.ID, formatWarName(db.War{ID: summary.WarID, WarDate: warDate, Label: job.Label}),
+		strings.Title(job.Result), strings.Title(job.WarType), job.Tier, summary.Linked+len(summary.Created))
+
+	if summary.Dropped > 0 {
+		msg += fmt.Sprintf("\nDropped lines: %d", summary.Dropped)
+	}
+
+	if len(summary.Created) > 0 {
+		// Keep well within Discord's 2000 character message limit
+		msg += truncateString(fmt.Sprintf("\nNew members: %s", strings.Join(summary.Created, ", ")), 1500)
+	}
+
+	return msg
+}
+
+// extractWarData loads the job's attachments and parses them as CSV or screenshots.
+// The lines of several screenshots are returned in upload order; every screenshot must show the same war date.
+func (q *WarJobQueue) extractWarData(job *db.WarJob, cfg *GuildConfig) (time.Time, []db.WarLineData, error) {
+	attachments, err := db.GetWarJobAttachments(q.db, job.ID)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("failed to load attachments: %w", err)
+	}
+
+	return q.extractAttachments(job, cfg, attachments)
+}
+
+// extractAttachments parses the loaded attachments of a job, creating the guild's extractor for the first screenshot
+func (q *WarJobQueue) extractAttachments(job *db.WarJob, cfg *GuildConfig, attachments []db.WarJobAttachment) (time.Time, []db.WarLineData, error) {
+	if len(attachments) == 0 {
+		return time.Time{}, nil, fmt.Errorf("job has no attachments")
+	}
+
+	var extractor extract.Extractor
+	var warDate time.Time
+	var warLines []db.WarLineData
+
+	for _, attachment := range attachments {
+		content, err := q.loadAttachment(job, attachment)
+		if err != nil {
+			return time.Time{}, nil, err
+		}
+
+		var date time.Time
+		var lines []db.WarLineData
+
+		if isImageFile(attachment.Filename) {
+			if extractor == nil {
+				extractor, err = q.extractor(cfg)
+				if err != nil {
+					return time.Time{}, nil, err
+				}
+			}
+			date, lines, err = extractor.Extract(context.Background(), content, attachment.ContentType)
+			if err != nil {
+				if len(attachments) > 1 {
+					return time.Time{}, nil, fmt.Errorf("%s: %w", attachment.Filename, err)
+				}
+				return time.Time{}, nil, err
+			}
+		} else {
+			date, lines, err = extract.ParseWarCSV(bytes.NewReader(content), cfg.Location())
+			if err != nil {
+				return time.Time{}, nil, fmt.Errorf("failed to parse CSV file: %w", err)
+			}
+		}
+
+		if warDate.IsZero() {
+			warDate = date
+		} else if !date.Equal(warDate) {
+			return time.Time{}, nil, fmt.Errorf("screenshots show different war dates (%s and %s)", warDate.Format("02-01-06"), date.Format("02-01-06"))
+		}
+
+		warLines = append(warLines, lines...)
+	}
+
+	return warDate, warLines, nil
+}
+
+// extractConfig returns the screenshot extraction settings of the guild
+func extractConfig(cfg *GuildConfig) extract.Config {
+	return extract.Config{
+		Backend:  cfg.OCRBackend,
+		Model:    cfg.OCRModel,
+		BaseURL:  cfg.OCRBaseURL,
+		Location: cfg.Location(),
+	}
+}
+
+// extractor creates the screenshot extractor configured for the guild
+func (q *WarJobQueue) extractor(cfg *GuildConfig) (extract.Extractor, error) {
+	return q.newExtractor(cfg)
+}
+
+// gearExtractor creates the stat window extractor configured for the guild; /gear
+// screenshots go through the same backend and credentials as war screenshots
+func (q *WarJobQueue) gearExtractor(cfg *GuildConfig) (extract.GearExtractor, error) {
+	return q.newGearExtractor(cfg)
+}
+
+// loadAttachment returns the attachment content from the copy saved when the job was queued.
+// When the copy is missing, e.g. for jobs queued before files were saved, the file is downloaded
+// and saved again so a retried job does not depend on the Discord CDN.
+func (q *WarJobQueue) loadAttachment(job *db.WarJob, attachment db.WarJobAttachment) ([]byte, error) {
+	if attachment.LocalPath != "" {
+		content, err := os.ReadFile(attachment.LocalPath)
+		if err == nil {
+			return content, nil
+		}
+		log.Printf("war jobs: saved copy %s of job %d unavailable, downloading again: %v", attachment.LocalPath, job.ID, err)
+	}
+
+	content, err := downloadAttachment(attachment.URL, maxAttachmentSize(attachment.Filename))
+	if err != nil {
+		return nil, err
+	}
+
+	savedPath, err := saveUpload(content, job.RequestedByUserID, warFileLabel(attachment), attachment.Filename)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("war jobs: attachment of job %d saved to %s", job.ID, savedPath)
+
+	if err := db.SetWarJobAttachmentLocalPath(q.db, attachment.ID, savedPath); err != nil {
+		// Non-fatal, the job can still be processed from memory
+		log.Printf("war jobs: failed to record saved path for job %d: %v", job.ID, err)
+	}
+
+	return content, nil
+}
+
+// fail records the error on the job and reports it in the request channel
+func (q *WarJobQueue) fail(job *db.WarJob, jobErr error) {
+	failWarJob(q.session, q.db, job, jobErr)
+}
+
+// report posts a message in the channel the import was requested from
+func (q *WarJobQueue) report(job *db.WarJob, msg string) {
+	reportWarJob(q.session, job, msg)
+}
+
+// failWarJob records the error on the job and reports it in the request channel
+func failWarJob(s *discordgo.Session, dbx *db.DB, job *db.WarJob, jobErr error) {
+	log.Printf("war jobs: job %d failed: %v", job.ID, jobErr)
+
+	if err := db.FailWarJob(dbx, job.ID, jobErr.Error()); err != nil {
+		log.Printf("war jobs: failed to mark job %d as failed: %v", job.ID, err)
+	}
+
+	// Check if this is a moderation failure
+	var moderationErr *extract.ModerationError
+	if errors.As(jobErr, &moderationErr) {
+		// Build a user-friendly message
+		categoryList := strings.Join(moderationErr.Categories, ", ")
+		reportWarJob(s, job, fmt.Sprintf("<@%s> ⚠️ **Image Moderation Failed** (job #%d)\n\n"+
+			"The uploaded image was flagged for potentially unsafe content.\n\n"+
+			"**Flagged categories:** %s\n\n"+
+			"Please upload a different image that complies with content policies.", job.RequestedByUserID, job.ID, categoryList))
+		return
+	}
+
+	// Truncate error message to avoid exposing too much detail
+	errMsg := jobErr.Error()
+	if len(errMsg) > 200 {
+		errMsg = errMsg[:200] + "..."
+	}
+	reportWarJob(s, job, fmt.Sprintf("<@%s> War import job #%d failed: %s", job.RequestedByUserID, job.ID, errMsg))
+}
+
+// reportWarJob posts a message in the channel the import was requested from
+func reportWarJob(s *discordgo.Session, job *db.WarJob, msg string) {
+	if _, err := s.ChannelMessageSend(job.RequestChannelID, msg); err != nil {
+		log.Printf("war jobs: failed to report job %d: %v", job.ID, err)
+	}
+}
+
+// isDiscordCDNURL checks that an attachment URL points at Discord's CDN
+func isDiscordCDNURL(url string) bool {
+	return strings.HasPrefix(url, "https://cdn.discordapp.com/") ||
+		strings.HasPrefix(url, "https://media.discordapp.net/")
+}
+
+// downloadAttachment downloads an attachment from Discord's CDN, reading at most maxSize bytes
+func downloadAttachment(url string, maxSize int) ([]byte, error) {
+	if !isDiscordCDNURL(url) {
+		return nil, fmt.Errorf("invalid attachment source")
+	}
+
+	// Create HTTP client with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download request: %w", err)
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download the file: status %d", resp.StatusCode)
+	}
+
+	// Limit the response body size as an additional safety measure
+	content, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxSize)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the file: %w", err)
+	}
+
+	return content, nil
+}
@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/extract"
+)
+
+func TestIsDiscordCDNURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected bool
+	}{
+		{
+			name:     "cdn attachment",
+			url:      "https://cdn.discordapp.com/attachments/1/2/war.png",
+			expected: true,
+		},
+		{
+			name:     "media proxy",
+			url:      "https://media.discordapp.net/attachments/1/2/war.png",
+			expected: true,
+		},
+		{
+			name:     "plain http",
+			url:      "http://cdn.discordapp.com/attachments/1/2/war.png",
+			expected: false,
+		},
+		{
+			name:     "lookalike host",
+			url:      "https://cdn.discordapp.com.evil.example/war.png",
+			expected: false,
+		},
+		{
+			name:     "empty",
+			url:      "",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := isDiscordCDNURL(tt.url)
+			if result != tt.expected {
+				t.Errorf("isDiscordCDNURL(%q) = %v, want %v", tt.url, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAttachmentFileKinds(t *testing.T) {
+	tests := []struct {
+		filename string
+		isCSV    bool
+		isImage  bool
+		maxSize  int
+	}{
+		{filename: "war.csv", isCSV: true, isImage: false, maxSize: 10 * 1024 * 1024},
+		{filename: "WAR.CSV", isCSV: true, isImage: false, maxSize: 10 * 1024 * 1024},
+		{filename: "scoreboard.png", isCSV: false, isImage: true, maxSize: 5 * 1024 * 1024},
+		{filename: "scoreboard.JPG", isCSV: false, isImage: true, maxSize: 5 * 1024 * 1024},
+		{filename: "scoreboard.jpeg", isCSV: false, isImage: true, maxSize: 5 * 1024 * 1024},
+		{filename: "scoreboard.webp", isCSV: false, isImage: true, maxSize: 5 * 1024 * 1024},
+		{filename: "notes.txt", isCSV: false, isImage: false, maxSize: 10 * 1024 * 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			if got := isCSVFile(tt.filename); got != tt.isCSV {
+				t.Errorf("isCSVFile(%q) = %v, want %v", tt.filename, got, tt.isCSV)
+			}
+			if got := isImageFile(tt.filename); got != tt.isImage {
+				t.Errorf("isImageFile(%q) = %v, want %v", tt.filename, got, tt.isImage)
+			}
+			if got := maxAttachmentSize(tt.filename); got != tt.maxSize {
+				t.Errorf("maxAttachmentSize(%q) = %d, want %d", tt.filename, got, tt.maxSize)
+			}
+		})
+	}
+}
+
+func TestWarAttachments(t *testing.T) {
+	attachment := func(id string) *discordgo.MessageAttachment {
+		return &discordgo.MessageAttachment{ID: id, Filename: id + ".png"}
+	}
+
+	data := discordgo.ApplicationCommandInteractionData{
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "result", Type: discordgo.ApplicationCommandOptionString, Value: "win"},
+			{Name: "file3", Type: discordgo.ApplicationCommandOptionAttachment, Value: "c"},
+			{Name: "file", Type: discordgo.ApplicationCommandOptionAttachment, Value: "a"},
+			{Name: "file2", Type: discordgo.ApplicationCommandOptionAttachment, Value: "b"},
+		},
+		Resolved: &discordgo.ApplicationCommandInteractionDataResolved{
+			Attachments: map[string]*discordgo.MessageAttachment{
+				"a": attachment("a"),
+				"b": attachment("b"),
+				"c": attachment("c"),
+			},
+		},
+	}
+
+	attachments := warAttachments(data)
+	if len(attachments) != 3 {
+		t.Fatalf("expected 3 attachments, got %d", len(attachments))
+	}
+	for idx, id := range []string{"a", "b", "c"} {
+		if attachments[idx].ID != id {
+			t.Errorf("attachment %d = %q, want %q", idx, attachments[idx].ID, id)
+		}
+	}
+
+	if got := warAttachments(discordgo.ApplicationCommandInteractionData{}); len(got) != 0 {
+		t.Errorf("expected no attachments without resolved data, got %d", len(got))
+	}
+}
+
+func TestWarJobQueueExtractsScreenshotsThroughFake(t *testing.T) {
+	dir := t.TempDir()
+	saved := func(name string) db.WarJobAttachment {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("image"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return db.WarJobAttachment{Filename: name, ContentType: "image/png", LocalPath: path}
+	}
+	attachments := []db.WarJobAttachment{saved("top.png"), saved("bottom.png")}
+
+	warDate := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	fake := &extract.Fake{
+		Date:  warDate,
+		Lines: []db.WarLineData{{FamilyName: "Kethrya", Kills: 12, Deaths: 3}},
+	}
+
+	created := 0
+	q := &WarJobQueue{
+		newExtractor: func(cfg *GuildConfig) (extract.Extractor, error) {
+			created++
+			return fake, nil
+		},
+	}
+
+	job := &db.WarJob{ID: 1, DiscordGuildID: "guild", RequestedByUserID: "42"}
+	date, lines, err := q.extractAttachments(job, &GuildConfig{}, attachments)
+	if err != nil {
+		t.Fatalf("extractAttachments() error = %v", err)
+	}
+	if !date.Equal(warDate) {
+		t.Errorf("war date = %v, want %v", date, warDate)
+	}
+	if len(lines) != 2 || lines[0].FamilyName != "Kethrya" || lines[1].Kills != 12 {
+		t.Errorf("expected the lines of both screenshots, got %+v", lines)
+	}
+	if created != 1 {
+		t.Errorf("expected one extractor for the job, created %d", created)
+	}
+	if fake.Calls != 2 {
+		t.Errorf("expected one extraction per screenshot, got %d", fake.Calls)
+	}
+
+	// Extraction errors name the failing screenshot when there are several
+	fake.Err = errors.New("no scoreboard found")
+	_, _, err = q.extractAttachments(job, &GuildConfig{}, attachments)
+	if err == nil || !strings.Contains(err.Error(), "top.png") {
+		t.Errorf("expected an error naming top.png, got %v", err)
+	}
+
+	// A misconfigured backend fails the job before any screenshot is read
+	q.newExtractor = func(cfg *GuildConfig) (extract.Extractor, error) {
+		return nil, errors.New("OpenAI API key is not set")
+	}
+	if _, _, err := q.extractAttachments(job, &GuildConfig{}, attachments); err == nil {
+		t.Error("expected the extractor error")
+	}
+}
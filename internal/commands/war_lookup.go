@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/discord"
+)
+
+// maxWarLabelLength keeps war labels short enough for autocomplete choices
+const maxWarLabelLength = 64
+
+// maxAutocompleteChoices is Discord's limit on the number of autocomplete choices
+const maxAutocompleteChoices = 25
+
+// warOption builds the autocompleted option used to pick a war by its ID
+func warOption(description string, required bool) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:         discordgo.ApplicationCommandOptionString,
+		Name:         "war",
+		Description:  description,
+		Required:     required,
+		Autocomplete: true,
+	}
+}
+
+// formatWarName names a war for messages: its ID, date and label
+func formatWarName(war db.War) string {
+	name := fmt.Sprintf("#%d %s", war.ID, war.WarDate.Format("02-01-06"))
+	if war.Label != "" {
+		name += " " + war.Label
+	}
+	return name
+}
+
+// formatWarChoice describes a war in an autocomplete list
+func formatWarChoice(war db.War) string {
+	parts := []string{formatWarName(war)}
+	if war.Result != "" {
+		parts = append(parts, strings.Title(war.Result))
+	}
+	switch war.WarType {
+	case "node":
+		parts = append(parts, "Node War")
+	case "siege":
+		parts = append(parts, "Siege")
+	}
+	if war.IsExcluded {
+		parts = append(parts, "excluded")
+	}
+
+	// Discord limits choice names to 100 characters
+	return truncateString(strings.Join(parts, " · "), 100)
+}
+
+// parseWarID parses a war ID as typed by a user, with or without a leading '#'
+func parseWarID(value string) (int64, bool) {
+	warID, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(value), "#"), 10, 64)
+	if err != nil || warID <= 0 {
+		return 0, false
+	}
+	return warID, true
+}
+
+// ambiguousWarsMessage lists the wars sharing a date so the user can pick one
+func ambiguousWarsMessage(dateStr string, wars []db.War) string {
+	names := make([]string, 0, len(wars))
+	for _, war := range wars {
+		names = append(names, formatWarName(war))
+	}
+	return fmt.Sprintf("There are %d wars on %s: %s. Use the war option to choose one.", len(wars), dateStr, strings.Join(names, ", "))
+}
+
+// resolveWar finds the war selected by the war option, or by an unambiguous date.
+// It responds to the interaction and returns false when no single war matches.
+func resolveWar(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, warRef, dateStr string) (*db.War, bool) {
+	warRef = strings.TrimSpace(warRef)
+
+	if warRef != "" {
+		warID, ok := parseWarID(warRef)
+		if !ok {
+			// A date typed without picking a suggestion
+			if _, err := time.Parse("02-01-06", warRef); err != nil {
+				discord.RespondEphemeral(s, i, "Please choose a war from the list.")
+				return nil, false
+			}
+			dateStr = warRef
+		} else {
+			war, err := db.GetWar(dbx, i.GuildID, warID)
+			if errors.Is(err, sql.ErrNoRows) {
+				discord.RespondEphemeral(s, i, fmt.Sprintf("War #%d not found.", warID))
+				return nil, false
+			} else if err != nil {
+				log.Printf("war lookup error: %v", err)
+				discord.RespondEphemeral(s, i, "Failed to look up the war. Please try again.")
+				return nil, false
+			}
+			return war, true
+		}
+	}
+
+	if dateStr == "" {
+		discord.RespondEphemeral(s, i, "Please choose a war.")
+		return nil, false
+	}
+
+	warDate, err := time.ParseInLocation("02-01-06", dateStr, cfg.Location())
+	if err != nil {
+		discord.RespondEphemeral(s, i, "Invalid date format. Please use DD-MM-YY format (e.g., 15-01-25).")
+		return nil, false
+	}
+
+	wars, err := db.GetWarsByDate(dbx, i.GuildID, warDate)
+	if err != nil {
+		log.Printf("war lookup error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to look up the war. Please try again.")
+		return nil, false
+	}
+
+	switch len(wars) {
+	case 0:
+		discord.RespondEphemeral(s, i, fmt.Sprintf("No war found for date %s.", dateStr))
+		return nil, false
+	case 1:
+		return &wars[0], true
+	default:
+		discord.RespondEphemeral(s, i, ambiguousWarsMessage(dateStr, wars))
+		return nil, false
+	}
+}
+
+// handleWarAutocomplete suggests wars matching what the user typed in a war option
+func handleWarAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, search string) {
+	// War data is officer-only, so only officers get suggestions
+	if !hasOfficerPermission(s, i, cfg) {
+		discord.RespondAutocomplete(s, i, nil)
+		return
+	}
+
+	wars, err := db.SearchWars(dbx, i.GuildID, strings.TrimPrefix(strings.TrimSpace(search), "#"), maxAutocompleteChoices)
+	if err != nil {
+		log.Printf("war autocomplete error: %v", err)
+		discord.RespondAutocomplete(s, i, nil)
+		return
+	}
+
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(wars))
+	for _, war := range wars {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  formatWarChoice(war),
+			Value: strconv.FormatInt(war.ID, 10),
+		})
+	}
+
+	discord.RespondAutocomplete(s, i, choices)
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"PanickedBot/internal/db"
+)
+
+func TestParseWarID(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		valid    bool
+	}{
+		{"12", 12, true},
+		{"#12", 12, true},
+		{" 7 ", 7, true},
+		{"0", 0, false},
+		{"-3", 0, false},
+		{"15-01-25", 0, false},
+		{"Calpheon", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			warID, ok := parseWarID(tt.input)
+			if ok != tt.valid || warID != tt.expected {
+				t.Errorf("parseWarID(%q) = %d, %v, expected %d, %v", tt.input, warID, ok, tt.expected, tt.valid)
+			}
+		})
+	}
+}
+
+func TestFormatWarName(t *testing.T) {
+	date := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	if got := formatWarName(db.War{ID: 12, WarDate: date}); got != "#12 15-01-25" {
+		t.Errorf("formatWarName() = %q", got)
+	}
+	if got := formatWarName(db.War{ID: 12, WarDate: date, Label: "Calpheon"}); got != "#12 15-01-25 Calpheon" {
+		t.Errorf("formatWarName() = %q", got)
+	}
+}
+
+func TestFormatWarChoice(t *testing.T) {
+	date := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	got := formatWarChoice(db.War{ID: 12, WarDate: date, Label: "Calpheon", Result: "win", WarType: "node", IsExcluded: true})
+	expected := "#12 15-01-25 Calpheon · Win · Node War · excluded"
+	if got != expected {
+		t.Errorf("formatWarChoice() = %q, expected %q", got, expected)
+	}
+
+	long := formatWarChoice(db.War{ID: 12, WarDate: date, Label: strings.Repeat("x", 150)})
+	if len([]rune(long)) > 100 {
+		t.Errorf("formatWarChoice() should fit Discord's 100 character limit, got %d", len([]rune(long)))
+	}
+}
+
+func TestAmbiguousWarsMessage(t *testing.T) {
+	date := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	msg := ambiguousWarsMessage("15-01-25", []db.War{
+		{ID: 12, WarDate: date, Label: "Calpheon"},
+		{ID: 13, WarDate: date},
+	})
+
+	for _, want := range []string{"2 wars", "#12 15-01-25 Calpheon", "#13 15-01-25"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message %q should contain %q", msg, want)
+		}
+	}
+}
@@ -0,0 +1,684 @@
+package commands
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal"
+	"PanickedBot/internal/db"
+	"PanickedBot/internal/discord"
+	"PanickedBot/internal/namematch"
+)
+
+// warReviewPrefix prefixes the custom IDs of war import review components.
+// Custom IDs have the form warreview:<job id>:<action>[:<line id>].
+const warReviewPrefix = "warreview"
+
+// War import review actions
+const (
+	warReviewSelectLine = "line"    // open the controls of a line
+	warReviewPick       = "pick"    // link a line to a member chosen from the list
+	warReviewByName     = "name"    // link a line to a member typed by family name
+	warReviewAccept     = "accept"  // link a line to its suggested member
+	warReviewCreate     = "create"  // create a new member for a line
+	warReviewDrop       = "drop"    // leave a line out of the import
+	warReviewConfirm    = "confirm" // import the war
+	warReviewCancel     = "cancel"  // discard the import
+)
+
+// maxSelectOptions is Discord's limit on the number of options in a select menu
+const maxSelectOptions = 25
+
+// warReviewFamilyNameInput is the custom ID of the family name field of the link by name modal
+const warReviewFamilyNameInput = "family_name"
+
+// warReviewCustomID builds the custom ID of a review component; lineID is omitted when zero
+func warReviewCustomID(jobID int64, action string, lineID int64) string {
+	if lineID == 0 {
+		return fmt.Sprintf("%s:%d:%s", warReviewPrefix, jobID, action)
+	}
+	return fmt.Sprintf("%s:%d:%s:%d", warReviewPrefix, jobID, action, lineID)
+}
+
+// parseWarReviewCustomID parses a custom ID built by warReviewCustomID
+func parseWarReviewCustomID(customID string) (jobID int64, action string, lineID int64, ok bool) {
+	parts := strings.Split(customID, ":")
+	if len(parts) < 3 || len(parts) > 4 || parts[0] != warReviewPrefix {
+		return 0, "", 0, false
+	}
+
+	jobID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || jobID <= 0 {
+		return 0, "", 0, false
+	}
+
+	if len(parts) == 4 {
+		lineID, err = strconv.ParseInt(parts[3], 10, 64)
+		if err != nil || lineID <= 0 {
+			return 0, "", 0, false
+		}
+	}
+
+	return jobID, parts[2], lineID, true
+}
+
+// needsReview reports whether any line was not confidently linked to a roster member
+func needsReview(lines []db.MatchedWarLine) bool {
+	for _, line := range lines {
+		if line.Action != db.WarLineActionLink {
+			return true
+		}
+	}
+	return false
+}
+
+// isReviewLine reports whether a line is listed for review; exact matches are not unless they conflict
+func isReviewLine(line db.MatchedWarLine) bool {
+	return line.Conflict || line.Action != db.WarLineActionLink || line.Confidence < 1
+}
+
+// conflictMarker flags lines whose screenshots disagree
+func conflictMarker(line db.MatchedWarLine) string {
+	if line.Conflict {
+		return " ⚠️"
+	}
+	return ""
+}
+
+// describeReviewLine describes what will happen to a line when the import is confirmed
+func describeReviewLine(line db.MatchedWarLine) string {
+	switch line.Action {
+	case db.WarLineActionLink:
+		if line.Confidence > 0 {
+			return fmt.Sprintf("%s (%.0f%%)", line.MatchedName, line.Confidence*100)
+		}
+		return line.MatchedName
+	case db.WarLineActionCreate:
+		return "new member"
+	case db.WarLineActionDrop:
+		return "dropped"
+	}
+
+	if line.MemberID != 0 {
+		return fmt.Sprintf("%s? (%.0f%%) — needs a decision", line.MatchedName, line.Confidence*100)
+	}
+	return "no match — needs a decision"
+}
+
+// reviewLinePriority ranks review lines: lines needing a decision first, then conflicts, then the rest
+func reviewLinePriority(line db.MatchedWarLine) int {
+	switch {
+	case !line.Resolved():
+		return 0
+	case line.Conflict:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// sortReviewLines orders review lines by priority so the lines still needing a decision are
+// always within the select menu's limit; lines keep their order within a priority
+func sortReviewLines(lines []db.MatchedWarLine) {
+	sort.SliceStable(lines, func(a, b int) bool {
+		return reviewLinePriority(lines[a]) < reviewLinePriority(lines[b])
+	})
+}
+
+// buildWarReviewMessage builds the review message of a job held for review
+func buildWarReviewMessage(job *db.WarJob, lines []db.MatchedWarLine) (string, []discordgo.MessageComponent) {
+	var reviewLines []db.MatchedWarLine
+	unresolved, conflicts := 0, 0
+	for _, line := range lines {
+		if isReviewLine(line) {
+			reviewLines = append(reviewLines, line)
+		}
+		if !line.Resolved() {
+			unresolved++
+		}
+		if line.Conflict {
+			conflicts++
+		}
+	}
+	sortReviewLines(reviewLines)
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("<@%s> **War import job #%d needs review**\n", job.RequestedByUserID, job.ID))
+	b.WriteString(fmt.Sprintf("Date: %s\n", job.WarDate.Format("02-01-06")))
+	if job.Label != "" {
+		b.WriteString(fmt.Sprintf("Label: %s\n", job.Label))
+	}
+	b.WriteString(fmt.Sprintf("Entries: %d (%d matched exactly)\n\n", len(lines), len(lines)-len(reviewLines)))
+
+	for idx, line := range reviewLines {
+		if idx == maxSelectOptions {
+			b.WriteString(fmt.Sprintf("...and %d more\n", len(reviewLines)-maxSelectOptions))
+			break
+		}
+		b.WriteString(fmt.Sprintf("`%d.` **%s** (%d/%d)%s → %s\n", idx+1, line.FamilyName, line.Kills, line.Deaths, conflictMarker(line), describeReviewLine(line)))
+	}
+
+	if conflicts > 0 {
+		b.WriteString("\n⚠️ These members appear on several screenshots with different kills or deaths. Keep the correct line and drop the others.\n")
+	}
+
+	if unresolved > 0 {
+		b.WriteString(fmt.Sprintf("\n%d line(s) need a decision. Select a line below to review it, then confirm the import.", unresolved))
+	} else {
+		b.WriteString("\nAll lines are resolved. Confirm to import the war.")
+	}
+
+	// Keep well within Discord's 2000 character message limit
+	content := truncateString(b.String(), 1900)
+
+	var components []discordgo.MessageComponent
+
+	if len(reviewLines) > 0 {
+		options := make([]discordgo.SelectMenuOption, 0, maxSelectOptions)
+		for _, line := range reviewLines {
+			if len(options) == maxSelectOptions {
+				break
+			}
+			options = append(options, discordgo.SelectMenuOption{
+				Label:       truncateString(fmt.Sprintf("%s → %s", line.FamilyName, describeReviewLine(line)), 100),
+				Value:       strconv.FormatInt(line.ID, 10),
+				Description: fmt.Sprintf("%d kills / %d deaths%s", line.Kills, line.Deaths, conflictMarker(line)),
+			})
+		}
+
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					CustomID:    warReviewCustomID(job.ID, warReviewSelectLine, 0),
+					Placeholder: "Select a line to review",
+					Options:     options,
+				},
+			},
+		})
+	}
+
+	components = append(components, discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "Confirm import",
+				Style:    discordgo.SuccessButton,
+				CustomID: warReviewCustomID(job.ID, warReviewConfirm, 0),
+				Disabled: unresolved > 0,
+			},
+			discordgo.Button{
+				Label:    "Cancel import",
+				Style:    discordgo.DangerButton,
+				CustomID: warReviewCustomID(job.ID, warReviewCancel, 0),
+			},
+		},
+	})
+
+	return content, components
+}
+
+// buildReviewLineMessage builds the controls of a single line; matches are the closest roster members
+func buildReviewLineMessage(jobID int64, line db.MatchedWarLine, matches []namematch.Match) (string, []discordgo.MessageComponent) {
+	content := fmt.Sprintf("**%s** (%d kills / %d deaths)\nCurrently: %s", line.FamilyName, line.Kills, line.Deaths, describeReviewLine(line))
+	if line.Conflict {
+		content += "\n⚠️ Another screenshot shows different kills or deaths for this member."
+	}
+
+	var components []discordgo.MessageComponent
+
+	if len(matches) > 0 {
+		options := make([]discordgo.SelectMenuOption, 0, len(matches))
+		for _, match := range matches {
+			options = append(options, discordgo.SelectMenuOption{
+				Label:   truncateString(fmt.Sprintf("%s (%.0f%%)", match.FamilyName, match.Confidence*100), 100),
+				Value:   strconv.FormatInt(match.MemberID, 10),
+				Default: line.Action == db.WarLineActionLink && match.MemberID == line.MemberID,
+			})
+		}
+
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					CustomID:    warReviewCustomID(jobID, warReviewPick, line.ID),
+					Placeholder: "Link to a roster member",
+					Options:     options,
+				},
+			},
+		})
+	}
+
+	acceptLabel := "Confirm match"
+	if line.MatchedName != "" && line.MemberID != 0 {
+		acceptLabel = truncateString("Confirm "+line.MatchedName, 80)
+	}
+
+	components = append(components, discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    acceptLabel,
+				Style:    discordgo.SuccessButton,
+				CustomID: warReviewCustomID(jobID, warReviewAccept, line.ID),
+				Disabled: line.MemberID == 0 || line.Action == db.WarLineActionLink,
+			},
+			discordgo.Button{
+				Label:    "Link by family name",
+				Style:    discordgo.SecondaryButton,
+				CustomID: warReviewCustomID(jobID, warReviewByName, line.ID),
+			},
+			discordgo.Button{
+				Label:    "Create new member",
+				Style:    discordgo.PrimaryButton,
+				CustomID: warReviewCustomID(jobID, warReviewCreate, line.ID),
+				Disabled: line.Action == db.WarLineActionCreate,
+			},
+			discordgo.Button{
+				Label:    "Drop line",
+				Style:    discordgo.DangerButton,
+				CustomID: warReviewCustomID(jobID, warReviewDrop, line.ID),
+				Disabled: line.Action == db.WarLineActionDrop,
+			},
+		},
+	})
+
+	return content, components
+}
+
+// handleWarReviewComponent handles the buttons and select menus of war import reviews
+func handleWarReviewComponent(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	jobID, action, lineID, ok := parseWarReviewCustomID(i.MessageComponentData().CustomID)
+	if !ok {
+		discord.RespondEphemeral(s, i, "Unknown action.")
+		return
+	}
+
+	job := loadReviewJob(s, i, dbx, cfg, jobID)
+	if job == nil {
+		return
+	}
+
+	switch action {
+	case warReviewSelectLine:
+		values := i.MessageComponentData().Values
+		if len(values) == 0 {
+			discord.RespondEphemeral(s, i, "No line selected.")
+			return
+		}
+		selectedID, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil {
+			discord.RespondEphemeral(s, i, "Invalid line.")
+			return
+		}
+		showReviewLine(s, i, dbx, job, selectedID)
+
+	case warReviewPick, warReviewAccept, warReviewCreate, warReviewDrop:
+		updateReviewLine(s, i, dbx, job, lineID, action)
+
+	case warReviewByName:
+		showLinkByNameModal(s, i, job.ID, lineID)
+
+	case warReviewConfirm:
+		confirmWarReview(s, i, dbx, job)
+
+	case warReviewCancel:
+		cancelWarReview(s, i, dbx, job)
+
+	default:
+		discord.RespondEphemeral(s, i, "Unknown action.")
+	}
+}
+
+// loadReviewJob loads a job awaiting review for an officer, responding to the user when it cannot be reviewed
+func loadReviewJob(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, jobID int64) *db.WarJob {
+	if !hasOfficerPermission(s, i, cfg) {
+		discord.RespondEphemeral(s, i, "You need officer permissions to review war imports.")
+		return nil
+	}
+
+	job, err := db.GetWarJob(dbx, jobID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && job.DiscordGuildID != i.GuildID) {
+		discord.RespondEphemeral(s, i, "War import not found.")
+		return nil
+	}
+	if err != nil {
+		log.Printf("war review error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to load war import. Please try again.")
+		return nil
+	}
+
+	if job.Status != "review" {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("War import job #%d is no longer awaiting review.", job.ID))
+		return nil
+	}
+
+	return job
+}
+
+// loadReviewLine loads a line of a job held for review and the roster members closest to it
+func loadReviewLine(dbx *db.DB, job *db.WarJob, lineID int64) (*db.MatchedWarLine, []namematch.Match, error) {
+	line, err := db.GetWarJobLine(dbx, job.ID, lineID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	matcher, err := db.LoadNameMatcher(dbx, job.DiscordGuildID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return line, matcher.Rank(line.FamilyName, maxSelectOptions), nil
+}
+
+// showReviewLine shows the controls of a line in an ephemeral message
+func showReviewLine(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, job *db.WarJob, lineID int64) {
+	line, matches, err := loadReviewLine(dbx, job, lineID)
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, "Line not found.")
+		return
+	}
+	if err != nil {
+		log.Printf("war review error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to load line. Please try again.")
+		return
+	}
+
+	content, components := buildReviewLineMessage(job.ID, *line, matches)
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// updateReviewLine applies an officer's decision to a line and refreshes the review message
+func updateReviewLine(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, job *db.WarJob, lineID int64, action string) {
+	line, matches, err := loadReviewLine(dbx, job, lineID)
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, "Line not found.")
+		return
+	}
+	if err != nil {
+		log.Printf("war review error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to load line. Please try again.")
+		return
+	}
+
+	switch action {
+	case warReviewAccept:
+		if line.MemberID == 0 {
+			discord.RespondEphemeral(s, i, "This line has no suggested member. Pick a member, create a new one or drop the line.")
+			return
+		}
+		line.Action = db.WarLineActionLink
+
+	case warReviewPick:
+		values := i.MessageComponentData().Values
+		if len(values) == 0 {
+			discord.RespondEphemeral(s, i, "No member selected.")
+			return
+		}
+		memberID, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil {
+			discord.RespondEphemeral(s, i, "Invalid member.")
+			return
+		}
+
+		found := false
+		for _, match := range matches {
+			if match.MemberID == memberID {
+				line.Action = db.WarLineActionLink
+				line.MemberID = match.MemberID
+				line.MatchedName = match.FamilyName
+				line.Confidence = match.Confidence
+				found = true
+				break
+			}
+		}
+		if !found {
+			discord.RespondEphemeral(s, i, "Member not found on the roster.")
+			return
+		}
+
+	case warReviewCreate:
+		line.Action = db.WarLineActionCreate
+		line.MemberID = 0
+		line.MatchedName = line.FamilyName
+		line.Confidence = 0
+
+	case warReviewDrop:
+		line.Action = db.WarLineActionDrop
+	}
+
+	saveReviewLine(s, i, dbx, job, line, matches)
+}
+
+// saveReviewLine stores an officer's decision on a line, redraws the line controls and refreshes the review message
+func saveReviewLine(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, job *db.WarJob, line *db.MatchedWarLine, matches []namematch.Match) {
+	if err := db.UpdateWarJobLine(dbx, job.ID, *line); err != nil {
+		log.Printf("war review error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to update line. Please try again.")
+		return
+	}
+
+	content, components := buildReviewLineMessage(job.ID, *line, matches)
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Components: components,
+		},
+	})
+
+	refreshWarReviewMessage(s, dbx, job)
+}
+
+// showLinkByNameModal asks for the family name of the member to link a line to, for members
+// the closest matches do not offer, e.g. when OCR garbled the name beyond recognition
+func showLinkByNameModal(s *discordgo.Session, i *discordgo.InteractionCreate, jobID, lineID int64) {
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: warReviewCustomID(jobID, warReviewByName, lineID),
+			Title:    "Link to a roster member",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    warReviewFamilyNameInput,
+							Label:       "Family name or alias",
+							Style:       discordgo.TextInputShort,
+							Required:    true,
+							MaxLength:   128,
+							Placeholder: "Family name of the member",
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// modalTextValue returns the value of a text input of a submitted modal
+func modalTextValue(data discordgo.ModalSubmitInteractionData, customID string) string {
+	for _, row := range data.Components {
+		actionsRow, ok := row.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, component := range actionsRow.Components {
+			if input, ok := component.(*discordgo.TextInput); ok && input.CustomID == customID {
+				return strings.TrimSpace(input.Value)
+			}
+		}
+	}
+	return ""
+}
+
+// handleWarReviewModal links a line to the member named in the link by name modal
+func handleWarReviewModal(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	data := i.ModalSubmitData()
+	jobID, action, lineID, ok := parseWarReviewCustomID(data.CustomID)
+	if !ok || action != warReviewByName || lineID == 0 {
+		discord.RespondEphemeral(s, i, "Unknown action.")
+		return
+	}
+
+	job := loadReviewJob(s, i, dbx, cfg, jobID)
+	if job == nil {
+		return
+	}
+
+	familyName := modalTextValue(data, warReviewFamilyNameInput)
+	if familyName == "" {
+		discord.RespondEphemeral(s, i, "Please enter a family name.")
+		return
+	}
+
+	line, matches, err := loadReviewLine(dbx, job, lineID)
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, "Line not found.")
+		return
+	}
+	if err != nil {
+		log.Printf("war review error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to load line. Please try again.")
+		return
+	}
+
+	member, err := internal.GetMemberByFamilyNameIncludingInactive(dbx, job.DiscordGuildID, familyName)
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("No roster member or alias named '%s'.", familyName))
+		return
+	}
+	if err != nil {
+		log.Printf("war review error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to look up member. Please try again.")
+		return
+	}
+
+	line.Action = db.WarLineActionLink
+	line.MemberID = member.ID
+	line.MatchedName = member.FamilyName
+	line.Confidence = namematch.Similarity(line.FamilyName, member.FamilyName)
+
+	saveReviewLine(s, i, dbx, job, line, matches)
+}
+
+// refreshWarReviewMessage redraws the review message after a line changed
+func refreshWarReviewMessage(s *discordgo.Session, dbx *db.DB, job *db.WarJob) {
+	if job.ReviewMessageID == "" {
+		return
+	}
+
+	lines, err := db.GetWarJobLines(dbx, job.ID)
+	if err != nil {
+		log.Printf("war review: failed to load lines of job %d: %v", job.ID, err)
+		return
+	}
+
+	content, components := buildWarReviewMessage(job, lines)
+	_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:         job.ReviewMessageID,
+		Channel:    job.RequestChannelID,
+		Content:    &content,
+		Components: &components,
+	})
+	if err != nil {
+		log.Printf("war review: failed to update review message of job %d: %v", job.ID, err)
+	}
+}
+
+// confirmWarReview imports a reviewed war once every line is resolved
+func confirmWarReview(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, job *db.WarJob) {
+	lines, err := db.GetWarJobLines(dbx, job.ID)
+	if err != nil {
+		log.Printf("war review error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to load war import. Please try again.")
+		return
+	}
+
+	unresolved := 0
+	for _, line := range lines {
+		if !line.Resolved() {
+			unresolved++
+		}
+	}
+	if unresolved > 0 {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("%d line(s) still need a decision. Select them from the list to confirm a match, pick a member, create a new member or drop the line.", unresolved))
+		return
+	}
+	if name, found := db.DuplicateWarLine(lines); found {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("'%s' is imported by more than one line. Drop the lines you don't want to import.", name))
+		return
+	}
+
+	claimed, err := db.ClaimReviewedWarJob(dbx, job.ID)
+	if err != nil {
+		log.Printf("war review error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to confirm war import. Please try again.")
+		return
+	}
+	if !claimed {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("War import job #%d is no longer awaiting review.", job.ID))
+		return
+	}
+
+	// Acknowledge the click before the import, which can take a while
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+
+	// The review controls stay until the import is saved: a job interrupted by a restart
+	// goes back to review and is confirmed again from the same message
+	summary, err := db.CreateWarFromCSV(dbx, job.DiscordGuildID, job.ID, job.WarDate, job.Result, job.WarType, job.Tier, job.Label, lines)
+	if err != nil {
+		// Nothing was saved, so keep the job and the officers' decisions for another try
+		log.Printf("war review error: job %d import failed: %v", job.ID, err)
+		if err := db.ReturnWarJobToReview(dbx, job.ID); err != nil {
+			log.Printf("war review error: failed to return job %d to review: %v", job.ID, err)
+		}
+		_ = discord.FollowUpEphemeral(s, i, fmt.Sprintf("Failed to import war import job #%d. Nothing was imported and your decisions are kept; press Confirm import to try again.", job.ID))
+		return
+	}
+
+	closed := fmt.Sprintf("War import job #%d confirmed by <@%s>.", job.ID, i.Member.User.ID)
+	noComponents := []discordgo.MessageComponent{}
+	_, _ = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    &closed,
+		Components: &noComponents,
+	})
+
+	log.Printf("war jobs: job %d imported %d entries for %s after review", job.ID, len(lines), job.WarDate.Format("02-01-06"))
+
+	reportWarJob(s, job, warImportedMessage(job, job.WarDate, summary))
+}
+
+// cancelWarReview discards an import held for review
+func cancelWarReview(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, job *db.WarJob) {
+	canceled, err := db.CancelWarJob(dbx, job.ID)
+	if err != nil {
+		log.Printf("war review error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to cancel war import. Please try again.")
+		return
+	}
+	if !canceled {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("War import job #%d is no longer awaiting review.", job.ID))
+		return
+	}
+
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    fmt.Sprintf("War import job #%d was canceled by <@%s>. Nothing was imported.", job.ID, i.Member.User.ID),
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+}
@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal/db"
+)
+
+func TestWarReviewCustomID(t *testing.T) {
+	tests := []struct {
+		name   string
+		jobID  int64
+		action string
+		lineID int64
+		want   string
+	}{
+		{
+			name:   "job action",
+			jobID:  12,
+			action: warReviewConfirm,
+			want:   "warreview:12:confirm",
+		},
+		{
+			name:   "line action",
+			jobID:  12,
+			action: warReviewDrop,
+			lineID: 345,
+			want:   "warreview:12:drop:345",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			customID := warReviewCustomID(tt.jobID, tt.action, tt.lineID)
+			if customID != tt.want {
+				t.Fatalf("warReviewCustomID() = %q, want %q", customID, tt.want)
+			}
+
+			jobID, action, lineID, ok := parseWarReviewCustomID(customID)
+			if !ok || jobID != tt.jobID || action != tt.action || lineID != tt.lineID {
+				t.Errorf("parseWarReviewCustomID(%q) = %d, %q, %d, %v", customID, jobID, action, lineID, ok)
+			}
+		})
+	}
+}
+
+func TestParseWarReviewCustomIDInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"warreview",
+		"warreview:12",
+		"other:12:confirm",
+		"warreview:abc:confirm",
+		"warreview:0:confirm",
+		"warreview:12:drop:abc",
+		"warreview:12:drop:1:2",
+	}
+
+	for _, customID := range invalid {
+		if _, _, _, ok := parseWarReviewCustomID(customID); ok {
+			t.Errorf("parseWarReviewCustomID(%q) should fail", customID)
+		}
+	}
+}
+
+func TestNeedsReview(t *testing.T) {
+	linked := db.MatchedWarLine{Action: db.WarLineActionLink, MemberID: 1, Confidence: 1}
+	pending := db.MatchedWarLine{Action: db.WarLineActionPending, MemberID: 2, Confidence: 0.7}
+	create := db.MatchedWarLine{Action: db.WarLineActionCreate}
+
+	if needsReview([]db.MatchedWarLine{linked, linked}) {
+		t.Error("needsReview should be false when every line is linked")
+	}
+	if !needsReview([]db.MatchedWarLine{linked, pending}) {
+		t.Error("needsReview should be true with a pending line")
+	}
+	if !needsReview([]db.MatchedWarLine{linked, create}) {
+		t.Error("needsReview should be true with a new member")
+	}
+}
+
+func TestDescribeReviewLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line db.MatchedWarLine
+		want string
+	}{
+		{
+			name: "linked",
+			line: db.MatchedWarLine{Action: db.WarLineActionLink, MemberID: 1, MatchedName: "Hammity", Confidence: 0.875},
+			want: "Hammity (88%)",
+		},
+		{
+			name: "suggestion",
+			line: db.MatchedWarLine{Action: db.WarLineActionPending, MemberID: 1, MatchedName: "Hammity", Confidence: 0.7},
+			want: "Hammity? (70%) — needs a decision",
+		},
+		{
+			name: "no suggestion",
+			line: db.MatchedWarLine{Action: db.WarLineActionPending},
+			want: "no match — needs a decision",
+		},
+		{
+			name: "create",
+			line: db.MatchedWarLine{Action: db.WarLineActionCreate},
+			want: "new member",
+		},
+		{
+			name: "drop",
+			line: db.MatchedWarLine{Action: db.WarLineActionDrop},
+			want: "dropped",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeReviewLine(tt.line); got != tt.want {
+				t.Errorf("describeReviewLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildWarReviewMessage(t *testing.T) {
+	job := &db.WarJob{ID: 7, RequestedByUserID: "42"}
+	lines := []db.MatchedWarLine{
+		{ID: 1, WarLineData: db.WarLineData{FamilyName: "Hammity", Kills: 5, Deaths: 2}, Action: db.WarLineActionLink, MemberID: 1, MatchedName: "Hammity", Confidence: 1},
+		{ID: 2, WarLineData: db.WarLineData{FamilyName: "Kethyra", Kills: 3, Deaths: 4}, Action: db.WarLineActionPending, MemberID: 2, MatchedName: "Kethrya", Confidence: 0.71},
+	}
+
+	content, components := buildWarReviewMessage(job, lines)
+
+	if !strings.Contains(content, "job #7") {
+		t.Errorf("content should name the job, got %q", content)
+	}
+	if strings.Contains(content, "**Hammity**") {
+		t.Errorf("exact matches should not be listed for review, got %q", content)
+	}
+	if !strings.Contains(content, "**Kethyra**") {
+		t.Errorf("uncertain matches should be listed for review, got %q", content)
+	}
+	if len(components) != 2 {
+		t.Errorf("expected a line select and a button row, got %d rows", len(components))
+	}
+}
+
+func TestBuildWarReviewMessageConflict(t *testing.T) {
+	job := &db.WarJob{ID: 7, RequestedByUserID: "42"}
+	lines := []db.MatchedWarLine{
+		{ID: 1, WarLineData: db.WarLineData{FamilyName: "Hammity", Kills: 5, Deaths: 2}, Action: db.WarLineActionPending, MemberID: 1, MatchedName: "Hammity", Confidence: 1, Conflict: true},
+		{ID: 2, WarLineData: db.WarLineData{FamilyName: "Hammity", Kills: 6, Deaths: 2}, Action: db.WarLineActionPending, MemberID: 1, MatchedName: "Hammity", Confidence: 1, Conflict: true},
+	}
+
+	content, _ := buildWarReviewMessage(job, lines)
+
+	if strings.Count(content, "**Hammity**") != 2 {
+		t.Errorf("both conflicting lines should be listed, got %q", content)
+	}
+	if !strings.Contains(content, "several screenshots") {
+		t.Errorf("content should explain the conflict, got %q", content)
+	}
+}
+
+func TestBuildWarReviewMessageManyLines(t *testing.T) {
+	job := &db.WarJob{ID: 7, RequestedByUserID: "42"}
+	var lines []db.MatchedWarLine
+	for id := int64(1); id <= maxSelectOptions+5; id++ {
+		lines = append(lines, db.MatchedWarLine{ID: id, WarLineData: db.WarLineData{FamilyName: fmt.Sprintf("Member%d", id)},
+			Action: db.WarLineActionLink, MemberID: id, MatchedName: fmt.Sprintf("Member%d", id), Confidence: 0.9})
+	}
+	// The lines needing attention come last in the job
+	lines = append(lines,
+		db.MatchedWarLine{ID: 100, WarLineData: db.WarLineData{FamilyName: "Unmatched"}, Action: db.WarLineActionPending},
+		db.MatchedWarLine{ID: 101, WarLineData: db.WarLineData{FamilyName: "Conflicted"}, Action: db.WarLineActionLink, MemberID: 1, Confidence: 1, Conflict: true},
+	)
+
+	_, components := buildWarReviewMessage(job, lines)
+
+	menu := components[0].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	if len(menu.Options) != maxSelectOptions {
+		t.Fatalf("expected %d options, got %d", maxSelectOptions, len(menu.Options))
+	}
+	if menu.Options[0].Value != "100" || menu.Options[1].Value != "101" {
+		t.Errorf("expected the unresolved and conflicting lines first, got %q and %q", menu.Options[0].Value, menu.Options[1].Value)
+	}
+}
+
+func TestBuildReviewLineMessageLinkByName(t *testing.T) {
+	line := db.MatchedWarLine{ID: 5, WarLineData: db.WarLineData{FamilyName: "Garbled"}, Action: db.WarLineActionPending}
+
+	// Without close matches there is no select menu, but any member can still be linked by name
+	_, components := buildReviewLineMessage(7, line, nil)
+
+	want := warReviewCustomID(7, warReviewByName, 5)
+	found := false
+	for _, row := range components {
+		for _, component := range row.(discordgo.ActionsRow).Components {
+			if button, ok := component.(discordgo.Button); ok && button.CustomID == want {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected a link by family name button with custom ID %q", want)
+	}
+
+	jobID, action, lineID, ok := parseWarReviewCustomID(want)
+	if !ok || jobID != 7 || action != warReviewByName || lineID != 5 {
+		t.Errorf("parseWarReviewCustomID(%q) = %d, %q, %d, %v", want, jobID, action, lineID, ok)
+	}
+}
+
+func TestModalTextValue(t *testing.T) {
+	data := discordgo.ModalSubmitInteractionData{
+		Components: []discordgo.MessageComponent{
+			&discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					&discordgo.TextInput{CustomID: "other", Value: "ignored"},
+					&discordgo.TextInput{CustomID: warReviewFamilyNameInput, Value: "  Kethrya "},
+				},
+			},
+		},
+	}
+
+	if got := modalTextValue(data, warReviewFamilyNameInput); got != "Kethrya" {
+		t.Errorf("modalTextValue() = %q, want %q", got, "Kethrya")
+	}
+	if got := modalTextValue(data, "missing"); got != "" {
+		t.Errorf("modalTextValue() for a missing input = %q, want empty", got)
+	}
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal/db"
+)
+
+// warTypeChoices are the war types of wars.war_type
+var warTypeChoices = []*discordgo.ApplicationCommandOptionChoice{
+	{Name: "Node War", Value: "node"},
+	{Name: "Siege", Value: "siege"},
+}
+
+// warResultChoices are the results of wars.result
+var warResultChoices = []*discordgo.ApplicationCommandOptionChoice{
+	{Name: "Win", Value: "win"},
+	{Name: "Lose", Value: "lose"},
+}
+
+// warFilterOptions are the options of reports that can be limited to some wars
+func warFilterOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "from",
+			Description: "First war date in DD-MM-YY format (default: first war)",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "to",
+			Description: "Last war date in DD-MM-YY format (default: latest war)",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "war_type",
+			Description: "Only count wars of this type",
+			Required:    false,
+			Choices:     warTypeChoices,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "tier",
+			Description: "Only count wars of this tier",
+			Required:    false,
+			Choices:     warTierChoices,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "result",
+			Description: "Only count won or lost wars",
+			Required:    false,
+			Choices:     warResultChoices,
+		},
+	}
+}
+
+// parseWarFilter reads the options added by warFilterOptions. description describes the
+// chosen wars for report titles and is empty when every war is counted.
+// problem describes invalid input for the user.
+func parseWarFilter(options []*discordgo.ApplicationCommandInteractionDataOption, cfg *GuildConfig) (filter db.WarFilter, description, problem string) {
+	fromStr := stringOption(options, "from")
+	toStr := stringOption(options, "to")
+	filter = db.WarFilter{
+		WarType: stringOption(options, "war_type"),
+		Tier:    stringOption(options, "tier"),
+		Result:  stringOption(options, "result"),
+	}
+
+	if fromStr != "" || toStr != "" {
+		from, to, problem := parseOpenDateRange(fromStr, toStr, cfg.Location())
+		if problem != "" {
+			return db.WarFilter{}, "", problem
+		}
+		if fromStr != "" {
+			filter.Start = from
+		}
+		if toStr != "" {
+			filter.End = to
+		}
+	}
+
+	return filter, describeWarFilter(fromStr, toStr, filter), ""
+}
+
+// describeWarFilter describes the wars a report covers, e.g. "Node War, Tier 1, Win, 01-12-24 to 31-12-24".
+// Open ends of the date range are left out. It returns an empty string when every war is counted.
+func describeWarFilter(fromStr, toStr string, filter db.WarFilter) string {
+	var parts []string
+	for _, name := range []string{
+		choiceName(warTypeChoices, filter.WarType),
+		choiceName(warTierChoices, filter.Tier),
+		choiceName(warResultChoices, filter.Result),
+	} {
+		if name != "" {
+			parts = append(parts, name)
+		}
+	}
+
+	switch {
+	case fromStr != "" && toStr != "":
+		parts = append(parts, fromStr+" to "+toStr)
+	case fromStr != "":
+		parts = append(parts, "since "+fromStr)
+	case toStr != "":
+		parts = append(parts, "until "+toStr)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// reportTitle adds the description of a war filter to a report title, e.g. "War Results: Siege"
+func reportTitle(title, description string) string {
+	if description == "" {
+		return title
+	}
+	return title + ": " + description
+}
+
+// choiceName returns the name of the choice with the given value, empty if there is none
+func choiceName(choices []*discordgo.ApplicationCommandOptionChoice, value string) string {
+	for _, choice := range choices {
+		if choice.Value == value {
+			return choice.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"PanickedBot/internal/db"
+)
+
+func TestParseWarFilter(t *testing.T) {
+	cfg := &GuildConfig{Timezone: "UTC"}
+	option := func(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+		return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value}
+	}
+
+	t.Run("no filters", func(t *testing.T) {
+		filter, description, problem := parseWarFilter(nil, cfg)
+		if filter != (db.WarFilter{}) || description != "" || problem != "" {
+			t.Errorf("parseWarFilter() = %+v, %q, %q, want every war", filter, description, problem)
+		}
+	})
+
+	t.Run("all filters", func(t *testing.T) {
+		options := []*discordgo.ApplicationCommandInteractionDataOption{
+			option("from", "01-12-24"), option("to", "31-12-24"),
+			option("war_type", "siege"), option("tier", "1"), option("result", "win"),
+		}
+		filter, description, problem := parseWarFilter(options, cfg)
+		if problem != "" {
+			t.Fatalf("unexpected problem %q", problem)
+		}
+		want := db.WarFilter{
+			Start:   time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+			End:     time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+			WarType: "siege",
+			Tier:    "1",
+			Result:  "win",
+		}
+		if filter != want {
+			t.Errorf("filter = %+v, want %+v", filter, want)
+		}
+		if description != "Siege, Tier 1, Win, 01-12-24 to 31-12-24" {
+			t.Errorf("description = %q", description)
+		}
+	})
+
+	t.Run("open start", func(t *testing.T) {
+		filter, _, problem := parseWarFilter([]*discordgo.ApplicationCommandInteractionDataOption{option("to", "31-12-24")}, cfg)
+		if problem != "" || !filter.Start.IsZero() || filter.End.IsZero() {
+			t.Errorf("parseWarFilter() = %+v, %q, want only an end date", filter, problem)
+		}
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		_, _, problem := parseWarFilter([]*discordgo.ApplicationCommandInteractionDataOption{option("from", "2024-12-01")}, cfg)
+		if problem == "" {
+			t.Error("parseWarFilter() accepted an invalid from date")
+		}
+	})
+}
+
+func TestDescribeWarFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		to     string
+		filter db.WarFilter
+		want   string
+	}{
+		{"no filters", "", "", db.WarFilter{}, ""},
+		{"date range", "01-12-24", "31-12-24", db.WarFilter{}, "01-12-24 to 31-12-24"},
+		{"from only", "01-12-24", "", db.WarFilter{}, "since 01-12-24"},
+		{"to only", "", "31-12-24", db.WarFilter{}, "until 31-12-24"},
+		{"type and tier", "", "", db.WarFilter{WarType: "node", Tier: "1"}, "Node War, Tier 1"},
+		{"result", "", "", db.WarFilter{Result: "lose"}, "Lose"},
+		{"everything", "01-12-24", "31-12-24", db.WarFilter{WarType: "siege", Tier: "uncapped", Result: "win"}, "Siege, Uncapped, Win, 01-12-24 to 31-12-24"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeWarFilter(tt.from, tt.to, tt.filter); got != tt.want {
+				t.Errorf("describeWarFilter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportTitle(t *testing.T) {
+	if got := reportTitle("War Results", ""); got != "War Results" {
+		t.Errorf("reportTitle() = %q, want %q", got, "War Results")
+	}
+	if got := reportTitle("War Results", "Siege"); got != "War Results: Siege" {
+		t.Errorf("reportTitle() = %q, want %q", got, "War Results: Siege")
+	}
+}
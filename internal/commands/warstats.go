@@ -1,10 +1,11 @@
 package commands
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
-	"time"
 
 	"github.com/bwmarrin/discordgo"
 
@@ -53,7 +54,7 @@ func handleWarStats(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *d
 	}
 
 	// Parse command options
-	var dateStr string
+	var warRef, dateStr string
 	includeInactive := true // default to true
 	var includeMercs bool
 	var teamName string
@@ -61,6 +62,8 @@ func handleWarStats(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *d
 	options := i.ApplicationCommandData().Options
 	for _, opt := range options {
 		switch opt.Name {
+		case "war":
+			warRef = opt.StringValue()
 		case "date":
 			dateStr = opt.StringValue()
 		case "include_inactive":
@@ -72,15 +75,29 @@ func handleWarStats(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *d
 		}
 	}
 
-	// If date is provided, show stats for that specific war
-	if dateStr != "" {
-		handleWarStatsByDate(s, i, dbx, dateStr)
+	filter, description, problem := parseWarFilter(options, cfg)
+	if problem != "" {
+		discord.RespondEphemeral(s, i, problem)
+		return
+	}
+
+	// If a war or date is provided, show stats for that specific war
+	if warRef != "" || dateStr != "" {
+		if description != "" {
+			discord.RespondEphemeral(s, i, "The from, to, war_type, tier and result filters can't be combined with a single war.")
+			return
+		}
+		war, ok := resolveWar(s, i, dbx, cfg, warRef, dateStr)
+		if !ok {
+			return
+		}
+		handleWarStatsForWar(s, i, dbx, war)
 		return
 	}
 
 	// Otherwise, show stats for all wars (original behavior)
 	// Get war statistics
-	stats, err := db.GetWarStats(dbx, i.GuildID, includeInactive, includeMercs, teamName)
+	stats, err := db.GetWarStats(dbx, i.GuildID, includeInactive, includeMercs, teamName, filter)
 	if err != nil {
 		log.Printf("warstats error: %v", err)
 		discord.RespondEphemeral(s, i, "Failed to retrieve war statistics. Please try again.")
@@ -88,86 +105,43 @@ func handleWarStats(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *d
 	}
 
 	if len(stats) == 0 {
+		if description != "" {
+			discord.RespondEphemeral(s, i, "No roster members found with war participation in these wars.")
+			return
+		}
 		discord.RespondEphemeral(s, i, "No roster members found with war participation.")
 		return
 	}
 
-	// Build response message with aligned columns
-	var response strings.Builder
-	response.WriteString("**War Statistics**\n```\n")
-
-	// Header
-	response.WriteString(fmt.Sprintf("%-20s %12s %-15s %8s %8s %8s\n",
-		"Family Name", "Total Wars", "Most Recent", "Kills", "Deaths", "K/D"))
-	response.WriteString(strings.Repeat("-", 85) + "\n")
-
-	// Data rows
-	for _, stat := range stats {
-		response.WriteString(formatWarStatLine(stat))
-	}
-
-	response.WriteString("```")
-
-	// Discord has a 2000 character limit for messages
-	responseText := response.String()
-	if len(responseText) > 2000 {
-		// If too long, show fewer rows
-		var truncatedResponse strings.Builder
-		truncatedResponse.WriteString("**War Statistics** (showing first entries)\n```\n")
-		header := fmt.Sprintf("%-20s %12s %-15s %8s %8s %8s\n",
-			"Family Name", "Total Wars", "Most Recent", "Kills", "Deaths", "K/D")
-		truncatedResponse.WriteString(header)
-		truncatedResponse.WriteString(strings.Repeat("-", 85) + "\n")
-
-		currentLen := truncatedResponse.Len()
-		const closingLen = 3 // length of "```"
-
-		for _, stat := range stats {
-			line := formatWarStatLine(stat)
-
-			// Check if adding this line would exceed the limit
-			if currentLen+len(line)+closingLen > 1990 {
-				break
-			}
-			truncatedResponse.WriteString(line)
-			currentLen += len(line)
-		}
-		truncatedResponse.WriteString("```")
-		discord.RespondText(s, i, truncatedResponse.String())
-	} else {
-		discord.RespondText(s, i, responseText)
+	table := pagedTable{
+		Title: reportTitle("War Statistics", description),
+		Header: fmt.Sprintf("%-20s %12s %-15s %8s %8s %8s\n",
+			"Family Name", "Total Wars", "Most Recent", "Kills", "Deaths", "K/D") + strings.Repeat("-", 85) + "\n",
+		Lines: make([]string, len(stats)),
 	}
-}
-
-// handleWarStatsByDate shows war statistics for a specific date
-func handleWarStatsByDate(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, dateStr string) {
-	// Parse the date in Eastern timezone
-	est := getEasternLocation()
-	warDate, err := time.ParseInLocation("02-01-06", dateStr, est)
-	if err != nil {
-		discord.RespondEphemeral(s, i, "Invalid date format. Please use DD-MM-YY format (e.g., 15-01-25).")
-		return
+	for idx, stat := range stats {
+		table.Lines[idx] = formatWarStatLine(stat)
 	}
 
-	// Get war statistics for this specific date
-	stats, err := db.GetWarStatsByDate(dbx, i.GuildID, warDate)
+	respondPaged(s, i, table.pages())
+}
+
+// handleWarStatsForWar shows war statistics for a single war
+func handleWarStatsForWar(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, war *db.War) {
+	stats, err := db.GetWarStatsByWar(dbx, i.GuildID, war.ID)
 	if err != nil {
-		log.Printf("warstats by date error: %v", err)
+		log.Printf("warstats by war error: %v", err)
 		discord.RespondEphemeral(s, i, "Failed to retrieve war statistics. Please try again.")
 		return
 	}
 
 	if len(stats) == 0 {
-		discord.RespondEphemeral(s, i, fmt.Sprintf("No war data found for date %s.", dateStr))
+		discord.RespondEphemeral(s, i, fmt.Sprintf("No war data found for war %s.", formatWarName(*war)))
 		return
 	}
 
 	// Calculate totals
-	var totalKills, totalDeaths int
-	for _, stat := range stats {
-		totalKills += stat.Kills
-		totalDeaths += stat.Deaths
-	}
+	totalKills, totalDeaths, uncountedMembers := warMemberTotals(stats)
 
 	// Calculate overall K/D ratio
 	var overallKD string
@@ -180,19 +154,26 @@ func handleWarStatsByDate(s *discordgo.Session, i *discordgo.InteractionCreate,
 		overallKD = "0.00"
 	}
 
-	// Build response message with aligned columns
-	var response strings.Builder
-	response.WriteString(fmt.Sprintf("**War Statistics for %s**\n```\n", dateStr))
+	title := formatWarName(*war)
+	if war.IsExcluded {
+		title += " (excluded from totals)"
+	}
 
-	// Header
-	response.WriteString(fmt.Sprintf("%-20s %10s %10s %10s\n",
-		"Family Name", "Kills", "Deaths", "K/D"))
-	response.WriteString(strings.Repeat("-", 55) + "\n")
+	table := pagedTable{
+		Title: "War Statistics for " + title,
+		Header: fmt.Sprintf("%-20s %10s %10s %10s\n",
+			"Family Name", "Kills", "Deaths", "K/D") + strings.Repeat("-", 55) + "\n",
+		Lines: make([]string, len(stats)),
+		Footer: strings.Repeat("-", 55) + "\n" + fmt.Sprintf("%-20s %10d %10d %10s\n",
+			"TOTAL", totalKills, totalDeaths, overallKD),
+	}
 
-	// Data rows
-	for _, stat := range stats {
+	for idx, stat := range stats {
 		familyName := truncateString(stat.FamilyName, 20)
-		
+		if !countsTowardWarTotals(stat) {
+			familyName = truncateString(stat.FamilyName, 19) + "*"
+		}
+
 		// Calculate K/D ratio for this member
 		var kdStr string
 		if stat.Deaths > 0 {
@@ -204,18 +185,34 @@ func handleWarStatsByDate(s *discordgo.Session, i *discordgo.InteractionCreate,
 			kdStr = "0.00"
 		}
 
-		response.WriteString(fmt.Sprintf("%-20s %10d %10d %10s\n",
-			familyName, stat.Kills, stat.Deaths, kdStr))
+		table.Lines[idx] = fmt.Sprintf("%-20s %10d %10d %10s\n",
+			familyName, stat.Kills, stat.Deaths, kdStr)
 	}
 
-	// Add totals line
-	response.WriteString(strings.Repeat("-", 55) + "\n")
-	response.WriteString(fmt.Sprintf("%-20s %10d %10d %10s\n",
-		"TOTAL", totalKills, totalDeaths, overallKD))
+	if uncountedMembers > 0 {
+		table.Note = fmt.Sprintf("\n\\* %d member(s) not counted in the totals (K/D exception, or excluded from war stats on this date)", uncountedMembers)
+	}
 
-	response.WriteString("```")
+	respondPaged(s, i, table.pages())
+}
 
-	discord.RespondText(s, i, response.String())
+// countsTowardWarTotals reports whether a member's line counts toward the guild's totals for the war.
+// K/D exceptions (see /kdexception) and members excluded from war stats are listed but not counted.
+func countsTowardWarTotals(stat db.WarMemberStat) bool {
+	return !stat.IsException && !stat.IsExcluded
+}
+
+// warMemberTotals sums the kills and deaths of a war's members that count toward the guild's totals
+func warMemberTotals(stats []db.WarMemberStat) (kills, deaths, uncounted int) {
+	for _, stat := range stats {
+		if !countsTowardWarTotals(stat) {
+			uncounted++
+			continue
+		}
+		kills += stat.Kills
+		deaths += stat.Deaths
+	}
+	return kills, deaths, uncounted
 }
 
 func handleWarResults(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
@@ -224,8 +221,14 @@ func handleWarResults(s *discordgo.Session, i *discordgo.InteractionCreate, dbx
 		return
 	}
 
+	filter, description, problem := parseWarFilter(i.ApplicationCommandData().Options, cfg)
+	if problem != "" {
+		discord.RespondEphemeral(s, i, problem)
+		return
+	}
+
 	// Get war results
-	results, err := db.GetWarResults(dbx, i.GuildID)
+	results, err := db.GetWarResults(dbx, i.GuildID, filter)
 	if err != nil {
 		log.Printf("warresults error: %v", err)
 		discord.RespondEphemeral(s, i, "Failed to retrieve war results. Please try again.")
@@ -233,16 +236,16 @@ func handleWarResults(s *discordgo.Session, i *discordgo.InteractionCreate, dbx
 	}
 
 	if len(results) == 0 {
+		if description != "" {
+			discord.RespondEphemeral(s, i, "No war data found for these wars.")
+			return
+		}
 		discord.RespondEphemeral(s, i, "No war data found.")
 		return
 	}
 
 	// Calculate cumulative stats
-	var cumulativeKills, cumulativeDeaths int
-	for _, result := range results {
-		cumulativeKills += result.TotalKills
-		cumulativeDeaths += result.TotalDeaths
-	}
+	cumulativeKills, cumulativeDeaths, excludedCount := warResultTotals(results)
 
 	// Calculate cumulative K/D ratio
 	var cumulativeKD string
@@ -255,19 +258,19 @@ func handleWarResults(s *discordgo.Session, i *discordgo.InteractionCreate, dbx
 		cumulativeKD = "0.00"
 	}
 
-	// Build response message with aligned columns
-	var response strings.Builder
-	response.WriteString("**War Results**\n```\n")
-
-	// Header
-	response.WriteString(fmt.Sprintf("%-15s %8s %10s %10s %10s\n",
-		"Date", "Result", "Kills", "Deaths", "K/D"))
-	response.WriteString(strings.Repeat("-", 60) + "\n")
+	table := pagedTable{
+		Title: reportTitle("War Results", description),
+		Header: fmt.Sprintf("%-6s %-9s %-16s %6s %8s %8s %8s\n",
+			"War", "Date", "Label", "Result", "Kills", "Deaths", "K/D") + strings.Repeat("-", 67) + "\n",
+		Lines: make([]string, len(results)),
+		Footer: strings.Repeat("-", 67) + "\n" + fmt.Sprintf("%-6s %-9s %-16s %6s %8d %8d %8s\n",
+			"TOTAL", "", "", "", cumulativeKills, cumulativeDeaths, cumulativeKD),
+	}
 
-	// Data rows
-	for _, result := range results {
+	for idx, result := range results {
 		dateStr := result.WarDate.Format("02-01-06")
-		
+		label := truncateString(result.Label, 16)
+
 		// Format result as W/L or empty
 		var resultStr string
 		if result.Result == "win" {
@@ -277,7 +280,7 @@ func handleWarResults(s *discordgo.Session, i *discordgo.InteractionCreate, dbx
 		} else {
 			resultStr = "-"
 		}
-		
+
 		// Calculate K/D ratio for this war
 		var kdStr string
 		if result.TotalDeaths > 0 {
@@ -289,18 +292,35 @@ func handleWarResults(s *discordgo.Session, i *discordgo.InteractionCreate, dbx
 			kdStr = "0.00"
 		}
 
-		response.WriteString(fmt.Sprintf("%-15s %8s %10d %10d %10s\n",
-			dateStr, resultStr, result.TotalKills, result.TotalDeaths, kdStr))
+		// Excluded wars are listed but marked and left out of the totals
+		warNumber := fmt.Sprintf("#%d", result.ID)
+		if result.IsExcluded {
+			warNumber += "*"
+		}
+
+		table.Lines[idx] = fmt.Sprintf("%-6s %-9s %-16s %6s %8d %8d %8s\n",
+			warNumber, dateStr, label, resultStr, result.TotalKills, result.TotalDeaths, kdStr)
 	}
 
-	// Add cumulative line
-	response.WriteString(strings.Repeat("-", 60) + "\n")
-	response.WriteString(fmt.Sprintf("%-15s %8s %10d %10d %10s\n",
-		"TOTAL", "", cumulativeKills, cumulativeDeaths, cumulativeKD))
+	if excludedCount > 0 {
+		table.Note = fmt.Sprintf("\n\\* %d excluded war(s), not counted in the totals", excludedCount)
+	}
 
-	response.WriteString("```")
+	respondPaged(s, i, table.pages())
+}
 
-	discord.RespondText(s, i, response.String())
+// warResultTotals sums the kills and deaths of wars that are not excluded
+// and counts the excluded ones
+func warResultTotals(results []db.WarResult) (kills, deaths, excluded int) {
+	for _, result := range results {
+		if result.IsExcluded {
+			excluded++
+			continue
+		}
+		kills += result.TotalKills
+		deaths += result.TotalDeaths
+	}
+	return kills, deaths, excluded
 }
 
 func handleRemoveWar(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
@@ -309,34 +329,85 @@ func handleRemoveWar(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *
 		return
 	}
 
-	// Get the date parameter
-	options := i.ApplicationCommandData().Options
-	if len(options) == 0 {
-		discord.RespondEphemeral(s, i, "Please provide a date in DD-MM-YY format.")
-		return
+	var warRef, dateStr string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "war":
+			warRef = opt.StringValue()
+		case "date":
+			dateStr = opt.StringValue()
+		}
 	}
 
-	dateStr := options[0].StringValue()
+	if warRef == "" && dateStr == "" {
+		discord.RespondEphemeral(s, i, "Please choose a war, or provide a date in DD-MM-YY format.")
+		return
+	}
 
-	// Parse the date in Eastern timezone
-	est := getEasternLocation()
-	warDate, err := time.ParseInLocation("02-01-06", dateStr, est)
-	if err != nil {
-		discord.RespondEphemeral(s, i, "Invalid date format. Please use DD-MM-YY format (e.g., 15-01-25).")
+	war, ok := resolveWar(s, i, dbx, cfg, warRef, dateStr)
+	if !ok {
 		return
 	}
 
 	// Delete the war
-	err = db.DeleteWarByDate(dbx, i.GuildID, warDate)
-	if err != nil {
+	err := db.DeleteWar(dbx, i.GuildID, war.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		discord.RespondEphemeral(s, i, fmt.Sprintf("War #%d not found.", war.ID))
+		return
+	} else if err != nil {
 		log.Printf("removewar error: %v", err)
-		if strings.Contains(err.Error(), "no war found") {
-			discord.RespondEphemeral(s, i, fmt.Sprintf("No war found for date %s.", dateStr))
+		discord.RespondEphemeral(s, i, "Failed to remove war. Please try again.")
+		return
+	}
+
+	discord.RespondText(s, i, fmt.Sprintf("Successfully removed war %s.", formatWarName(*war)))
+}
+
+func handleExcludeWar(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	setWarExcluded(s, i, dbx, cfg, true)
+}
+
+func handleIncludeWar(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig) {
+	setWarExcluded(s, i, dbx, cfg, false)
+}
+
+// setWarExcluded handles /excludewar and /includewar
+func setWarExcluded(s *discordgo.Session, i *discordgo.InteractionCreate, dbx *db.DB, cfg *GuildConfig, excluded bool) {
+	if !hasOfficerPermission(s, i, cfg) {
+		discord.RespondEphemeral(s, i, "You need officer role or admin permission to use this command.")
+		return
+	}
+
+	var warRef string
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "war" {
+			warRef = opt.StringValue()
+		}
+	}
+
+	war, ok := resolveWar(s, i, dbx, cfg, warRef, "")
+	if !ok {
+		return
+	}
+
+	if war.IsExcluded == excluded {
+		if excluded {
+			discord.RespondEphemeral(s, i, fmt.Sprintf("War %s is already excluded from statistics.", formatWarName(*war)))
 		} else {
-			discord.RespondEphemeral(s, i, "Failed to remove war. Please try again.")
+			discord.RespondEphemeral(s, i, fmt.Sprintf("War %s is already included in statistics.", formatWarName(*war)))
 		}
 		return
 	}
 
-	discord.RespondText(s, i, fmt.Sprintf("Successfully removed war data for %s.", dateStr))
+	if err := db.SetWarExcluded(dbx, i.GuildID, war.ID, excluded); err != nil {
+		log.Printf("set war excluded error: %v", err)
+		discord.RespondEphemeral(s, i, "Failed to update war. Please try again.")
+		return
+	}
+
+	if excluded {
+		discord.RespondText(s, i, fmt.Sprintf("War %s is now excluded from statistics and attendance.", formatWarName(*war)))
+	} else {
+		discord.RespondText(s, i, fmt.Sprintf("War %s is now included in statistics and attendance.", formatWarName(*war)))
+	}
 }
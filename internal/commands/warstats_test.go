@@ -105,3 +105,50 @@ func TestFormatWarStatLine(t *testing.T) {
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
+
+func TestWarResultTotals(t *testing.T) {
+	results := []db.WarResult{
+		{ID: 3, TotalKills: 120, TotalDeaths: 80},
+		{ID: 2, TotalKills: 40, TotalDeaths: 90, IsExcluded: true},
+		{ID: 1, TotalKills: 60, TotalDeaths: 20},
+	}
+
+	kills, deaths, excluded := warResultTotals(results)
+	if kills != 180 || deaths != 100 || excluded != 1 {
+		t.Errorf("warResultTotals() = %d, %d, %d, expected 180, 100, 1", kills, deaths, excluded)
+	}
+
+	kills, deaths, excluded = warResultTotals(nil)
+	if kills != 0 || deaths != 0 || excluded != 0 {
+		t.Errorf("warResultTotals(nil) = %d, %d, %d, expected 0, 0, 0", kills, deaths, excluded)
+	}
+}
+
+func TestWarMemberTotals(t *testing.T) {
+	stats := []db.WarMemberStat{
+		{FamilyName: "Hammity", Kills: 12, Deaths: 4},
+		{FamilyName: "Newbie", Kills: 1, Deaths: 9, IsExcluded: true},
+		{FamilyName: "Zephyr", Kills: 8, Deaths: 6},
+	}
+
+	kills, deaths, excluded := warMemberTotals(stats)
+	if kills != 20 || deaths != 10 || excluded != 1 {
+		t.Errorf("warMemberTotals() = %d, %d, %d, expected 20, 10, 1", kills, deaths, excluded)
+	}
+}
+
+func TestWarMemberTotalsSkipsExceptions(t *testing.T) {
+	stats := []db.WarMemberStat{
+		{FamilyName: "Hammity", Kills: 12, Deaths: 4},
+		{FamilyName: "Caller", Kills: 2, Deaths: 15, IsException: true},
+		{FamilyName: "Newbie", Kills: 1, Deaths: 9, IsException: true, IsExcluded: true},
+	}
+
+	kills, deaths, uncounted := warMemberTotals(stats)
+	if kills != 12 || deaths != 4 || uncounted != 2 {
+		t.Errorf("warMemberTotals() = %d, %d, %d, expected 12, 4, 2", kills, deaths, uncounted)
+	}
+	if countsTowardWarTotals(stats[1]) {
+		t.Errorf("countsTowardWarTotals(%s) = true, expected false", stats[1].FamilyName)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"strings"
+	"time"
 
 	"PanickedBot/internal/db"
 )
@@ -12,14 +13,32 @@ import (
 type Config struct {
 	DiscordToken string
 	DatabaseDSN  string
+
+	// Optional API keys for screenshot extraction backends
+	OpenAIAPIKey string
+	OCRAPIKey    string
 }
 
 // GuildConfig represents guild-specific configuration
 type GuildConfig struct {
-	OfficerRoleID     string `db:"officer_role_id"`
-	GuildMemberRoleID string `db:"guild_member_role_id"`
-	MercenaryRoleID   string `db:"mercenary_role_id"`
-	CommandChannelID  string `db:"command_channel_id"`
+	OfficerRoleID     string  `db:"officer_role_id"`
+	GuildMemberRoleID string  `db:"guild_member_role_id"`
+	MercenaryRoleID   string  `db:"mercenary_role_id"`
+	CommandChannelID  string  `db:"command_channel_id"`
+	Timezone          string  `db:"timezone"`
+	MatchThreshold    float64 `db:"match_threshold"`
+	OCRBackend        string  `db:"ocr_backend"`
+	OCRModel          string  `db:"ocr_model"`
+	OCRBaseURL        string  `db:"ocr_base_url"`
+
+	AttendanceWeekStart  int `db:"attendance_week_start"`
+	AttendanceWarDays    int `db:"attendance_war_days"`
+	AttendanceMinWars    int `db:"attendance_min_wars"`
+	AttendanceMinPercent int `db:"attendance_min_percent"`
+
+	VacationApproval bool `db:"vacation_approval"`
+
+	CapTier string `db:"cap_tier"` // war tier whose stat caps decide meets_cap, empty when set by hand
 }
 
 // LoadConfigFromEnv loads configuration from environment variables
@@ -28,6 +47,8 @@ func LoadConfigFromEnv() (Config, error) {
 	c := Config{
 		DiscordToken: get("DISCORD_BOT_TOKEN"),
 		DatabaseDSN:  get("DATABASE_DSN"),
+		OpenAIAPIKey: get("OPENAI_API_KEY"),
+		OCRAPIKey:    get("OCR_API_KEY"),
 	}
 	if c.DiscordToken == "" {
 		return c, errors.New("DISCORD_BOT_TOKEN is not set")
@@ -38,12 +59,33 @@ func LoadConfigFromEnv() (Config, error) {
 	return c, nil
 }
 
+// Location returns the guild's timezone, used for every date it enters or reads
+func (c *GuildConfig) Location() *time.Location {
+	return GetLocation(c.Timezone)
+}
+
+// AttendancePolicy returns the guild's attendance policy
+func (c *GuildConfig) AttendancePolicy() AttendancePolicy {
+	return AttendancePolicy{
+		WeekStart:  time.Weekday(c.AttendanceWeekStart % 7),
+		WarDays:    WarDaysFromMask(c.AttendanceWarDays),
+		MinWars:    c.AttendanceMinWars,
+		MinPercent: c.AttendanceMinPercent,
+	}
+}
+
 // LoadGuildConfig loads guild-specific configuration from database
 func LoadGuildConfig(dbx *db.DB, guildID string) (*GuildConfig, error) {
 	var cfg GuildConfig
 	err := dbx.Get(&cfg, `
 		SELECT officer_role_id, guild_member_role_id, mercenary_role_id, 
-		       command_channel_id
+		       command_channel_id, timezone, match_threshold, ocr_backend,
+		       COALESCE(ocr_model, '') AS ocr_model,
+		       COALESCE(ocr_base_url, '') AS ocr_base_url,
+		       attendance_week_start, attendance_war_days,
+		       attendance_min_wars, attendance_min_percent,
+		       vacation_approval,
+		       COALESCE(cap_tier, '') AS cap_tier
 		FROM config
 		WHERE discord_guild_id = ?
 	`, guildID)
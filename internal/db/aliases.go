@@ -0,0 +1,179 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sqlcdb "PanickedBot/internal/db/sqlc"
+)
+
+// ErrAliasTaken is returned when an alias is already a family name or another alias in the guild
+var ErrAliasTaken = errors.New("alias is already in use")
+
+// MemberAlias represents an alternative name that resolves to a roster member
+type MemberAlias struct {
+	Alias           string
+	FamilyName      string
+	CreatedByUserID string // empty when kept automatically on rename
+	CreatedAt       time.Time
+}
+
+// AddMemberAlias registers an alias for a roster member
+// Returns ErrAliasTaken if the alias already resolves to a member
+func AddMemberAlias(db *DB, guildID string, memberID int64, alias, createdByUserID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Aliases must not shadow a family name or another alias
+	_, err := db.Queries.GetMemberByFamilyNameIncludingInactive(ctx, sqlcdb.GetMemberByFamilyNameIncludingInactiveParams{
+		DiscordGuildID: guildID,
+		FamilyName:     alias,
+	})
+	if err == nil {
+		return ErrAliasTaken
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to check alias: %w", err)
+	}
+
+	err = db.Queries.CreateMemberAlias(ctx, sqlcdb.CreateMemberAliasParams{
+		DiscordGuildID:  guildID,
+		RosterMemberID:  uint64(memberID),
+		Alias:           alias,
+		CreatedByUserID: sql.NullString{String: createdByUserID, Valid: createdByUserID != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create alias: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveMemberAlias deletes an alias and returns the family name it resolved to
+// Returns sql.ErrNoRows if the alias does not exist
+func RemoveMemberAlias(db *DB, guildID, alias string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	existing, err := db.Queries.GetMemberAlias(ctx, sqlcdb.GetMemberAliasParams{
+		DiscordGuildID: guildID,
+		Alias:          alias,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	_, err = db.Queries.DeleteMemberAlias(ctx, sqlcdb.DeleteMemberAliasParams{
+		DiscordGuildID: guildID,
+		Alias:          alias,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to delete alias: %w", err)
+	}
+
+	return existing.FamilyName, nil
+}
+
+// GetMemberAliases retrieves the aliases of a guild, or of one member when memberID is not zero
+func GetMemberAliases(db *DB, guildID string, memberID int64) ([]MemberAlias, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetMemberAliases(ctx, sqlcdb.GetMemberAliasesParams{
+		DiscordGuildID: guildID,
+		RosterMemberID: sql.NullInt64{Int64: memberID, Valid: memberID != 0},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := make([]MemberAlias, 0, len(rows))
+	for _, row := range rows {
+		aliases = append(aliases, MemberAlias{
+			Alias:           row.Alias,
+			FamilyName:      row.FamilyName,
+			CreatedByUserID: row.CreatedByUserID.String,
+			CreatedAt:       row.CreatedAt,
+		})
+	}
+
+	return aliases, nil
+}
+
+// renameMember changes a member's family name and keeps the old one as an alias
+// so screenshots and lookups using the old name still resolve to the member.
+// Returns ErrAliasTaken if the new name is an alias of another member.
+func renameMember(ctx context.Context, db *DB, memberID int64, familyName string) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := renameMemberTx(ctx, db.Queries.WithTx(tx.Tx), memberID, familyName); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// checkNewMemberName returns ErrAliasTaken if the family name of a member about to be created
+// is an alias of an existing member, the same rule renameMemberTx applies to renames
+func checkNewMemberName(ctx context.Context, q *sqlcdb.Queries, guildID, familyName string) error {
+	_, err := q.GetMemberAlias(ctx, sqlcdb.GetMemberAliasParams{
+		DiscordGuildID: guildID,
+		Alias:          familyName,
+	})
+	if err == nil {
+		return ErrAliasTaken
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to check aliases: %w", err)
+	}
+	return nil
+}
+
+// renameMemberTx is renameMember within an existing transaction
+func renameMemberTx(ctx context.Context, qtx *sqlcdb.Queries, memberID int64, familyName string) error {
+	// Both members would match the name at full confidence
+	taken, err := qtx.IsAliasOfOtherMember(ctx, sqlcdb.IsAliasOfOtherMemberParams{
+		RosterMemberID: uint64(memberID),
+		Alias:          familyName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check aliases: %w", err)
+	}
+	if taken {
+		return ErrAliasTaken
+	}
+
+	err = qtx.KeepFamilyNameAsAlias(ctx, sqlcdb.KeepFamilyNameAsAliasParams{
+		ID:            uint64(memberID),
+		NewFamilyName: familyName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to keep old family name: %w", err)
+	}
+
+	err = qtx.UpdateMemberFamilyName(ctx, sqlcdb.UpdateMemberFamilyNameParams{
+		FamilyName: familyName,
+		ID:         uint64(memberID),
+	})
+	if err != nil {
+		return err
+	}
+
+	// The new family name no longer needs to be an alias of the same member
+	err = qtx.DeleteMemberAliasForMember(ctx, sqlcdb.DeleteMemberAliasForMemberParams{
+		RosterMemberID: uint64(memberID),
+		Alias:          familyName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clean up aliases: %w", err)
+	}
+
+	return nil
+}
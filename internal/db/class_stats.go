@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sqlcdb "PanickedBot/internal/db/sqlc"
+)
+
+// ClassStat is the war performance of one class and spec, from the class and spec
+// each member had when their war lines were recorded
+type ClassStat struct {
+	Class       string // empty for lines recorded before class snapshots or for members without a class
+	Spec        string
+	Members     int
+	Lines       int // war lines, i.e. member appearances in wars
+	TotalKills  int
+	TotalDeaths int
+}
+
+// GetClassStats retrieves the kills and deaths of every class and spec in the guild's wars matching the filter
+func GetClassStats(db *DB, guildID string, filter WarFilter) ([]ClassStat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	startDate, endDate := filter.dateRange()
+	rows, err := db.Queries.GetClassStats(ctx, sqlcdb.GetClassStatsParams{
+		DiscordGuildID: guildID,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		WarType:        filter.warType(),
+		Tier:           filter.tier(),
+		Result:         filter.result(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get class stats: %w", err)
+	}
+
+	stats := make([]ClassStat, len(rows))
+	for i, row := range rows {
+		stats[i] = ClassStat{
+			Class:       row.Class,
+			Spec:        row.Spec,
+			Members:     int(row.Members),
+			Lines:       int(row.LineCount),
+			TotalKills:  int(row.TotalKills),
+			TotalDeaths: int(row.TotalDeaths),
+		}
+	}
+
+	return stats, nil
+}
@@ -70,3 +70,10 @@ func Open(cfg Config) (*DB, error) {
 		Queries: queries,
 	}, nil
 }
+
+// dateValue converts a date to its DATE column value.
+// The session time zone is UTC, so a date parsed in a guild's timezone east of UTC
+// would otherwise be stored as the day before; keep its calendar day instead.
+func dateValue(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
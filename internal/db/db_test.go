@@ -1,8 +1,12 @@
 package db
 
 import (
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"PanickedBot/internal/namematch"
 )
 
 func TestErrTeamAlreadyExists(t *testing.T) {
@@ -113,3 +117,289 @@ func TestNullStringFromPtr(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestMatchWarLine(t *testing.T) {
+	matcher := namematch.New([]namematch.Candidate{
+		{MemberID: 1, FamilyName: "Hammity", Name: "Hammity"},
+		{MemberID: 2, FamilyName: "Kethrya", Name: "Kethrya"},
+		{MemberID: 3, FamilyName: "Kaii", Name: "Kaii"},
+		{MemberID: 4, FamilyName: "Kail", Name: "Kail"},
+	})
+
+	tests := []struct {
+		name           string
+		familyName     string
+		expectedAction string
+		expectedMember int64
+	}{
+		{
+			name:           "exact match is linked",
+			familyName:     "hammity",
+			expectedAction: WarLineActionLink,
+			expectedMember: 1,
+		},
+		{
+			name:           "close match is linked",
+			familyName:     "Hammiity",
+			expectedAction: WarLineActionLink,
+			expectedMember: 1,
+		},
+		{
+			name:           "uncertain match is pending with suggestion",
+			familyName:     "Kethyrra",
+			expectedAction: WarLineActionPending,
+			expectedMember: 2,
+		},
+		{
+			name:           "exact read beats a folded match",
+			familyName:     "Kail",
+			expectedAction: WarLineActionLink,
+			expectedMember: 4,
+		},
+		{
+			name:           "folded read matching two members is pending",
+			familyName:     "Kai1",
+			expectedAction: WarLineActionPending,
+			expectedMember: 3,
+		},
+		{
+			name:           "unknown name creates member",
+			familyName:     "Zzyzx",
+			expectedAction: WarLineActionCreate,
+			expectedMember: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := matchWarLine(matcher, WarLineData{FamilyName: tt.familyName, Kills: 1, Deaths: 1}, 0.8)
+			if line.Action != tt.expectedAction {
+				t.Errorf("action = %q, expected %q (confidence %.2f)", line.Action, tt.expectedAction, line.Confidence)
+			}
+			if line.MemberID != tt.expectedMember {
+				t.Errorf("member = %d, expected %d", line.MemberID, tt.expectedMember)
+			}
+		})
+	}
+}
+
+func TestMatchedWarLineResolved(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     MatchedWarLine
+		expected bool
+	}{
+		{"pending", MatchedWarLine{Action: WarLineActionPending, MemberID: 1}, false},
+		{"linked", MatchedWarLine{Action: WarLineActionLink, MemberID: 1}, true},
+		{"linked member deleted", MatchedWarLine{Action: WarLineActionLink}, false},
+		{"create", MatchedWarLine{Action: WarLineActionCreate}, true},
+		{"drop", MatchedWarLine{Action: WarLineActionDrop}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.line.Resolved(); got != tt.expected {
+				t.Errorf("Resolved() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeWarLines(t *testing.T) {
+	linked := func(name string, memberID int64, confidence float64, kills, deaths int) MatchedWarLine {
+		return MatchedWarLine{
+			WarLineData: WarLineData{FamilyName: name, Kills: kills, Deaths: deaths},
+			Action:      WarLineActionLink,
+			MemberID:    memberID,
+			MatchedName: "Member",
+			Confidence:  confidence,
+		}
+	}
+	created := func(name string, kills, deaths int) MatchedWarLine {
+		return MatchedWarLine{
+			WarLineData: WarLineData{FamilyName: name, Kills: kills, Deaths: deaths},
+			Action:      WarLineActionCreate,
+			MatchedName: name,
+		}
+	}
+
+	t.Run("overlap kept once", func(t *testing.T) {
+		merged := MergeWarLines([]MatchedWarLine{
+			linked("Hammity", 1, 1, 10, 5),
+			linked("Other", 2, 1, 3, 4),
+			linked("Hamm1ty", 1, 0.9, 10, 5),
+			created("Newbie", 1, 1),
+			created("newbie", 1, 1),
+		})
+		if len(merged) != 3 {
+			t.Fatalf("expected 3 lines, got %d: %+v", len(merged), merged)
+		}
+		if merged[0].FamilyName != "Hammity" || merged[1].FamilyName != "Other" || merged[2].FamilyName != "Newbie" {
+			t.Errorf("unexpected order: %+v", merged)
+		}
+		for _, line := range merged {
+			if line.Conflict {
+				t.Errorf("line %q should not be a conflict", line.FamilyName)
+			}
+		}
+	})
+
+	t.Run("best match wins", func(t *testing.T) {
+		merged := MergeWarLines([]MatchedWarLine{
+			linked("Hamm1ty", 1, 0.9, 10, 5),
+			linked("Hammity", 1, 1, 10, 5),
+		})
+		if len(merged) != 1 || merged[0].FamilyName != "Hammity" {
+			t.Errorf("expected the exact match to be kept, got %+v", merged)
+		}
+	})
+
+	t.Run("conflict flagged", func(t *testing.T) {
+		merged := MergeWarLines([]MatchedWarLine{
+			linked("Hammity", 1, 1, 10, 5),
+			linked("Hammity", 1, 1, 12, 5),
+			created("Newbie", 1, 1),
+			created("Newbie", 2, 1),
+		})
+		if len(merged) != 4 {
+			t.Fatalf("expected 4 lines, got %d", len(merged))
+		}
+		for _, line := range merged {
+			if !line.Conflict {
+				t.Errorf("line %q (%d/%d) should be a conflict", line.FamilyName, line.Kills, line.Deaths)
+			}
+		}
+		if merged[0].Action != WarLineActionPending || merged[0].MemberID != 1 {
+			t.Errorf("conflicting linked line should stay pending with its suggestion, got %+v", merged[0])
+		}
+		if merged[2].Action != WarLineActionCreate {
+			t.Errorf("conflicting new member should keep its action, got %q", merged[2].Action)
+		}
+	})
+}
+
+func TestDuplicateWarLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []MatchedWarLine
+		expected bool
+	}{
+		{
+			name: "distinct members",
+			lines: []MatchedWarLine{
+				{Action: WarLineActionLink, MemberID: 1},
+				{Action: WarLineActionLink, MemberID: 2},
+				{WarLineData: WarLineData{FamilyName: "Newbie"}, Action: WarLineActionCreate},
+			},
+		},
+		{
+			name: "same member linked twice",
+			lines: []MatchedWarLine{
+				{Action: WarLineActionLink, MemberID: 1},
+				{Action: WarLineActionLink, MemberID: 1},
+			},
+			expected: true,
+		},
+		{
+			name: "same new member twice",
+			lines: []MatchedWarLine{
+				{WarLineData: WarLineData{FamilyName: "Newbie"}, Action: WarLineActionCreate},
+				{WarLineData: WarLineData{FamilyName: "newbie"}, Action: WarLineActionCreate},
+			},
+			expected: true,
+		},
+		{
+			name: "duplicate dropped",
+			lines: []MatchedWarLine{
+				{Action: WarLineActionLink, MemberID: 1},
+				{Action: WarLineActionDrop, MemberID: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, found := DuplicateWarLine(tt.lines); found != tt.expected {
+				t.Errorf("DuplicateWarLine() = %v, expected %v", found, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPlanRelink(t *testing.T) {
+	const target = 7
+
+	tests := []struct {
+		name            string
+		lines           []ocrNameLine
+		expectedRelink  []int64
+		expectedSkipped int
+	}{
+		{
+			name:           "unmatched lines are relinked",
+			lines:          []ocrNameLine{{ID: 1, WarID: 10}, {ID: 2, WarID: 11}},
+			expectedRelink: []int64{1, 2},
+		},
+		{
+			name: "lines linked to an auto-created member are relinked",
+			lines: []ocrNameLine{
+				{ID: 1, WarID: 10, MemberID: 99},
+				{ID: 2, WarID: 11, MemberID: 99},
+			},
+			expectedRelink: []int64{1, 2},
+		},
+		{
+			name:           "lines already linked to the member are left alone",
+			lines:          []ocrNameLine{{ID: 1, WarID: 10, MemberID: target}, {ID: 2, WarID: 11, MemberID: 99}},
+			expectedRelink: []int64{2},
+		},
+		{
+			name:            "wars where the member has a line are skipped",
+			lines:           []ocrNameLine{{ID: 1, WarID: 10, MemberID: 99, MemberHasLine: true}, {ID: 2, WarID: 11}},
+			expectedRelink:  []int64{2},
+			expectedSkipped: 1,
+		},
+		{
+			name:            "only one line per war is relinked",
+			lines:           []ocrNameLine{{ID: 1, WarID: 10}, {ID: 2, WarID: 10, MemberID: 99}},
+			expectedRelink:  []int64{1},
+			expectedSkipped: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relink, skipped := planRelink(tt.lines, target)
+
+			var ids []int64
+			for _, line := range relink {
+				ids = append(ids, line.ID)
+			}
+			if !slices.Equal(ids, tt.expectedRelink) {
+				t.Errorf("planRelink() relinks %v, expected %v", ids, tt.expectedRelink)
+			}
+			if skipped != tt.expectedSkipped {
+				t.Errorf("planRelink() skipped %d, expected %d", skipped, tt.expectedSkipped)
+			}
+		})
+	}
+}
+
+func TestDateValue(t *testing.T) {
+	for _, name := range []string{"Europe/Berlin", "America/New_York", "Asia/Tokyo"} {
+		t.Run(name, func(t *testing.T) {
+			loc, err := time.LoadLocation(name)
+			if err != nil {
+				t.Skipf("timezone database not available: %v", err)
+			}
+
+			// A date parsed at midnight in the guild's timezone keeps its calendar day
+			date := time.Date(2026, 3, 29, 0, 0, 0, 0, loc)
+			got := dateValue(date)
+			want := time.Date(2026, 3, 29, 0, 0, 0, 0, time.UTC)
+			if !got.Equal(want) {
+				t.Errorf("dateValue(%v) = %v, want %v", date, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	sqlcdb "PanickedBot/internal/db/sqlc"
+)
+
+// Exclusion scopes: what a member exclusion applies to
+const (
+	ExclusionScopeAll        = "all"
+	ExclusionScopeAttendance = "attendance" // war nights are excused like a vacation
+	ExclusionScopeStats      = "stats"      // wars do not count toward the member's K/D
+)
+
+// Exclusion represents a date-bounded exclusion of a member from attendance and/or war stats
+type Exclusion struct {
+	ID              int64
+	RosterMemberID  int64
+	FamilyName      string
+	Scope           string
+	StartDate       time.Time
+	EndDate         time.Time
+	Reason          string
+	CreatedByUserID string
+}
+
+// CreateExclusion creates a new exclusion for a member
+func CreateExclusion(db *DB, guildID string, memberID int64, scope string, startDate, endDate time.Time, reason string, createdByUserID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.Queries.CreateExclusion(ctx, sqlcdb.CreateExclusionParams{
+		DiscordGuildID:  guildID,
+		RosterMemberID:  uint64(memberID),
+		Scope:           sqlcdb.MemberExceptionsScope(scope),
+		StartDate:       dateValue(startDate),
+		EndDate:         dateValue(endDate),
+		Reason:          sql.NullString{String: reason, Valid: reason != ""},
+		CreatedByUserID: createdByUserID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// GetActiveExclusions retrieves the guild's current and upcoming exclusions.
+// today is the current calendar day in the guild's timezone.
+func GetActiveExclusions(db *DB, guildID string, today time.Time) ([]Exclusion, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetActiveExclusionsForGuild(ctx, sqlcdb.GetActiveExclusionsForGuildParams{
+		DiscordGuildID: guildID,
+		EndDate:        dateValue(today),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exclusions := make([]Exclusion, 0, len(rows))
+	for _, row := range rows {
+		exclusions = append(exclusions, Exclusion{
+			ID:              int64(row.ID),
+			RosterMemberID:  int64(row.RosterMemberID),
+			FamilyName:      row.FamilyName,
+			Scope:           string(row.Scope),
+			StartDate:       row.StartDate,
+			EndDate:         row.EndDate,
+			Reason:          row.Reason.String,
+			CreatedByUserID: row.CreatedByUserID,
+		})
+	}
+
+	return exclusions, nil
+}
+
+// DeleteExclusion removes an exclusion of the guild.
+// Returns false if the guild has no such exclusion.
+func DeleteExclusion(db *DB, guildID string, exclusionID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.Queries.DeleteExclusion(ctx, sqlcdb.DeleteExclusionParams{
+		DiscordGuildID: guildID,
+		ID:             uint64(exclusionID),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
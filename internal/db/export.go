@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sqlcdb "PanickedBot/internal/db/sqlc"
+)
+
+// ExportWarLine is a war line together with the war it belongs to
+type ExportWarLine struct {
+	War   War
+	Line  WarLine
+	Class string // class of the linked member at the time of the war, empty if unknown
+	Spec  string
+}
+
+// GetGuildMemberTeamNames retrieves the names of the active teams of every member, keyed by member ID
+func GetGuildMemberTeamNames(db *DB, guildID string) (map[int64][]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetGuildMemberTeamNames(ctx, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member teams: %w", err)
+	}
+
+	teams := make(map[int64][]string)
+	for _, row := range rows {
+		memberID := int64(row.RosterMemberID)
+		teams[memberID] = append(teams[memberID], row.DisplayName)
+	}
+	return teams, nil
+}
+
+// GetWarLinesInRange retrieves the lines of every war fought between start and end (inclusive),
+// oldest war first. Excluded wars are included and marked on their War.
+func GetWarLinesInRange(db *DB, guildID string, start, end time.Time) ([]ExportWarLine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetWarLinesInRange(ctx, sqlcdb.GetWarLinesInRangeParams{
+		DiscordGuildID: guildID,
+		StartDate:      dateValue(start),
+		EndDate:        dateValue(end),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get war lines: %w", err)
+	}
+
+	lines := make([]ExportWarLine, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, ExportWarLine{
+			War: convertWar(sqlcdb.GetWarRow{
+				ID:         row.WarID,
+				WarDate:    row.WarDate,
+				Label:      row.Label,
+				Result:     row.Result,
+				WarType:    row.WarType,
+				Tier:       row.Tier,
+				IsExcluded: row.IsExcluded,
+			}),
+			Line: convertWarLine(sqlcdb.GetWarLinesRow{
+				ID:              row.ID,
+				OcrName:         row.OcrName,
+				Kills:           row.Kills,
+				Deaths:          row.Deaths,
+				RosterMemberID:  row.RosterMemberID,
+				MatchedName:     row.MatchedName,
+				MatchConfidence: row.MatchConfidence,
+				FamilyName:      row.FamilyName,
+			}),
+			Class: row.Class.String,
+			Spec:  row.Spec.String,
+		})
+	}
+	return lines, nil
+}
@@ -0,0 +1,190 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sqlcdb "PanickedBot/internal/db/sqlc"
+)
+
+// GearHistoryEntry is a member's AP, AAP and DP right after a gear update
+type GearHistoryEntry struct {
+	ID              int64
+	AP              sql.NullInt32
+	AAP             sql.NullInt32
+	DP              sql.NullInt32
+	UpdatedByUserID string
+	CreatedAt       time.Time
+}
+
+// GearSnapshot is the most recent gear update of a member at some point in time
+type GearSnapshot struct {
+	MemberID  int64
+	AP        sql.NullInt32
+	AAP       sql.NullInt32
+	DP        sql.NullInt32
+	UpdatedAt time.Time
+}
+
+// updateGearStats writes AP, AAP and DP and records the resulting gear in the history
+// Stats left nil keep their current value; the screenshot verification is set or cleared with them
+func updateGearStats(ctx context.Context, db *DB, memberID int64, fields UpdateFields) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := updateGearStatsTx(ctx, db.Queries.WithTx(tx.Tx), memberID, fields); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// updateGearStatsTx is updateGearStats within an existing transaction
+func updateGearStatsTx(ctx context.Context, qtx *sqlcdb.Queries, memberID int64, fields UpdateFields) error {
+	// Use the combined query if all three are provided
+	if fields.AP != nil && fields.AAP != nil && fields.DP != nil {
+		err := qtx.UpdateMemberGearStats(ctx, sqlcdb.UpdateMemberGearStatsParams{
+			Ap:  sql.NullInt32{Int32: int32(*fields.AP), Valid: true},
+			Aap: sql.NullInt32{Int32: int32(*fields.AAP), Valid: true},
+			Dp:  sql.NullInt32{Int32: int32(*fields.DP), Valid: true},
+			ID:  uint64(memberID),
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		if fields.AP != nil {
+			err := qtx.UpdateMemberAP(ctx, sqlcdb.UpdateMemberAPParams{
+				Ap: sql.NullInt32{Int32: int32(*fields.AP), Valid: true},
+				ID: uint64(memberID),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if fields.AAP != nil {
+			err := qtx.UpdateMemberAAP(ctx, sqlcdb.UpdateMemberAAPParams{
+				Aap: sql.NullInt32{Int32: int32(*fields.AAP), Valid: true},
+				ID:  uint64(memberID),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if fields.DP != nil {
+			err := qtx.UpdateMemberDP(ctx, sqlcdb.UpdateMemberDPParams{
+				Dp: sql.NullInt32{Int32: int32(*fields.DP), Valid: true},
+				ID: uint64(memberID),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	verifiedAt := sql.NullTime{}
+	if fields.GearVerified {
+		verifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	err := qtx.UpdateMemberGearVerified(ctx, sqlcdb.UpdateMemberGearVerifiedParams{
+		GearVerifiedAt: verifiedAt,
+		ID:             uint64(memberID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update gear verification: %w", err)
+	}
+
+	err = qtx.RecordGearHistory(ctx, sqlcdb.RecordGearHistoryParams{
+		UpdatedByUserID: fields.UpdatedByUserID,
+		RosterMemberID:  uint64(memberID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record gear history: %w", err)
+	}
+
+	return nil
+}
+
+// GetGearHistory retrieves a member's most recent gear updates, newest first
+func GetGearHistory(db *DB, memberID int64, limit int) ([]GearHistoryEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetGearHistory(ctx, sqlcdb.GetGearHistoryParams{
+		RosterMemberID: uint64(memberID),
+		Limit:          int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gear history: %w", err)
+	}
+
+	entries := make([]GearHistoryEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = GearHistoryEntry{
+			ID:              int64(row.ID),
+			AP:              row.Ap,
+			AAP:             row.Aap,
+			DP:              row.Dp,
+			UpdatedByUserID: row.UpdatedByUserID,
+			CreatedAt:       row.CreatedAt,
+		}
+	}
+
+	return entries, nil
+}
+
+// GetGearAsOf retrieves each member's most recent gear update made at or before asOf,
+// keyed by member ID. Members without an update by then are missing from the map.
+func GetGearAsOf(db *DB, guildID string, asOf time.Time) (map[int64]GearSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetGearAsOf(ctx, sqlcdb.GetGearAsOfParams{
+		DiscordGuildID: guildID,
+		AsOf:           asOf,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gear snapshot: %w", err)
+	}
+
+	snapshots := make(map[int64]GearSnapshot, len(rows))
+	for _, row := range rows {
+		snapshots[int64(row.RosterMemberID)] = GearSnapshot{
+			MemberID:  int64(row.RosterMemberID),
+			AP:        row.Ap,
+			AAP:       row.Aap,
+			DP:        row.Dp,
+			UpdatedAt: row.CreatedAt,
+		}
+	}
+
+	return snapshots, nil
+}
+
+// GetGearUpdatedSince retrieves the IDs of the members with a gear update made after since
+func GetGearUpdatedSince(db *DB, guildID string, since time.Time) (map[int64]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetGearUpdatedSince(ctx, sqlcdb.GetGearUpdatedSinceParams{
+		DiscordGuildID: guildID,
+		Since:          since,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gear updates: %w", err)
+	}
+
+	updated := make(map[int64]bool, len(rows))
+	for _, id := range rows {
+		updated[int64(id)] = true
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	sqlcdb "PanickedBot/internal/db/sqlc"
+)
+
+// Gear screenshot statuses
+const (
+	GearScreenshotPending   = "pending"
+	GearScreenshotConfirmed = "confirmed"
+	GearScreenshotCanceled  = "canceled"
+)
+
+// GearScreenshot is a stat window screenshot whose stats wait for the submitter to confirm them
+type GearScreenshot struct {
+	ID                int64
+	DiscordGuildID    string
+	MemberID          int64
+	SubmittedByUserID string
+	ImagePath         string
+	Stats             UpdateFields // stats read from the screenshot
+	Status            string
+	CreatedAt         time.Time
+}
+
+// CreateGearScreenshot records the stats read from a screenshot until they are confirmed or canceled
+// Returns the ID of the screenshot
+func CreateGearScreenshot(db *DB, guildID string, memberID int64, submittedByUserID, imagePath string, stats UpdateFields) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if stats.AP == nil || stats.AAP == nil || stats.DP == nil {
+		return 0, fmt.Errorf("AP, AAP and DP are required")
+	}
+
+	// DRR is a DECIMAL(5,2) column
+	drr := sql.NullString{}
+	if stats.DRR != nil {
+		drr = sql.NullString{String: strconv.FormatFloat(*stats.DRR, 'f', 2, 64), Valid: true}
+	}
+
+	result, err := db.Queries.CreateGearScreenshot(ctx, sqlcdb.CreateGearScreenshotParams{
+		DiscordGuildID:    guildID,
+		RosterMemberID:    uint64(memberID),
+		SubmittedByUserID: submittedByUserID,
+		ImagePath:         imagePath,
+		Ap:                uint32(*stats.AP),
+		Aap:               uint32(*stats.AAP),
+		Dp:                uint32(*stats.DP),
+		Evasion:           nullInt32FromPtr(stats.Evasion),
+		Dr:                nullInt32FromPtr(stats.DR),
+		Drr:               drr,
+		Accuracy:          nullInt32FromPtr(stats.Accuracy),
+		Hp:                nullInt32FromPtr(stats.HP),
+		TotalAp:           nullInt32FromPtr(stats.TotalAP),
+		TotalAap:          nullInt32FromPtr(stats.TotalAAP),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create gear screenshot: %w", err)
+	}
+
+	return result.LastInsertId()
+}
+
+// GetGearScreenshot retrieves a gear screenshot by ID
+// Returns sql.ErrNoRows if it does not exist
+func GetGearScreenshot(db *DB, id int64) (*GearScreenshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	row, err := db.Queries.GetGearScreenshot(ctx, uint64(id))
+	if err != nil {
+		return nil, err
+	}
+
+	intPtr := func(v sql.NullInt32) *int {
+		if !v.Valid {
+			return nil
+		}
+		value := int(v.Int32)
+		return &value
+	}
+
+	ap, aap, dp := int(row.Ap), int(row.Aap), int(row.Dp)
+	stats := UpdateFields{
+		AP:       &ap,
+		AAP:      &aap,
+		DP:       &dp,
+		Evasion:  intPtr(row.Evasion),
+		DR:       intPtr(row.Dr),
+		Accuracy: intPtr(row.Accuracy),
+		HP:       intPtr(row.Hp),
+		TotalAP:  intPtr(row.TotalAp),
+		TotalAAP: intPtr(row.TotalAap),
+	}
+	if row.Drr.Valid {
+		if drr, err := strconv.ParseFloat(row.Drr.String, 64); err == nil {
+			stats.DRR = &drr
+		}
+	}
+
+	return &GearScreenshot{
+		ID:                int64(row.ID),
+		DiscordGuildID:    row.DiscordGuildID,
+		MemberID:          int64(row.RosterMemberID),
+		SubmittedByUserID: row.SubmittedByUserID,
+		ImagePath:         row.ImagePath,
+		Stats:             stats,
+		Status:            string(row.Status),
+		CreatedAt:         row.CreatedAt,
+	}, nil
+}
+
+// ResolveGearScreenshot moves a pending screenshot to the confirmed or canceled status
+// Returns false if the screenshot is no longer pending
+func ResolveGearScreenshot(db *DB, id int64, status string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.Queries.ResolveGearScreenshot(ctx, sqlcdb.ResolveGearScreenshotParams{
+		Status: sqlcdb.GearScreenshotsStatus(status),
+		ID:     uint64(id),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve gear screenshot: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
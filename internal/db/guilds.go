@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
 	sqlcdb "PanickedBot/internal/db/sqlc"
@@ -17,7 +18,8 @@ type GuildConfig struct {
 }
 
 // UpsertGuildAndConfig creates or updates guild and configuration in a transaction
-func UpsertGuildAndConfig(db *DB, guildID, guildName, commandChannelID string, officerRoleID, guildMemberRoleID, mercenaryRoleID *string) error {
+// matchThreshold and timezone are only updated when provided
+func UpsertGuildAndConfig(db *DB, guildID, guildName, commandChannelID string, officerRoleID, guildMemberRoleID, mercenaryRoleID *string, matchThreshold *float64, timezone *string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -60,6 +62,26 @@ func UpsertGuildAndConfig(db *DB, guildID, guildName, commandChannelID string, o
 		return err
 	}
 
+	if matchThreshold != nil {
+		err = qtx.UpdateMatchThreshold(ctx, sqlcdb.UpdateMatchThresholdParams{
+			MatchThreshold: fmt.Sprintf("%.2f", *matchThreshold),
+			DiscordGuildID: guildID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if timezone != nil {
+		err = qtx.UpdateTimezone(ctx, sqlcdb.UpdateTimezoneParams{
+			Timezone:       *timezone,
+			DiscordGuildID: guildID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	return tx.Commit()
 }
 
@@ -70,3 +92,55 @@ func nullStringFromPtr(s *string) sql.NullString {
 	}
 	return sql.NullString{String: *s, Valid: true}
 }
+
+// UpdateOCRSettings sets the screenshot extraction backend of a guild
+// Empty model and baseURL are stored as NULL
+func UpdateOCRSettings(db *DB, guildID, backend, model, baseURL string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.Queries.UpdateOCRSettings(ctx, sqlcdb.UpdateOCRSettingsParams{
+		OcrBackend:     sqlcdb.ConfigOcrBackend(backend),
+		OcrModel:       sql.NullString{String: model, Valid: model != ""},
+		OcrBaseUrl:     sql.NullString{String: baseURL, Valid: baseURL != ""},
+		DiscordGuildID: guildID,
+	})
+}
+
+// UpdateAttendancePolicy sets the attendance policy of a guild.
+// warDays is a bitmask of weekdays (bit 0 = Sunday); minPercent overrides minWars when not zero.
+func UpdateAttendancePolicy(db *DB, guildID string, weekStart, warDays, minWars, minPercent int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.Queries.UpdateAttendancePolicy(ctx, sqlcdb.UpdateAttendancePolicyParams{
+		AttendanceWeekStart:  uint16(weekStart),
+		AttendanceWarDays:    uint16(warDays),
+		AttendanceMinWars:    uint16(minWars),
+		AttendanceMinPercent: uint16(minPercent),
+		DiscordGuildID:       guildID,
+	})
+}
+
+// UpdateVacationApproval sets whether vacations requested by members need officer approval
+func UpdateVacationApproval(db *DB, guildID string, required bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.Queries.UpdateVacationApproval(ctx, sqlcdb.UpdateVacationApprovalParams{
+		VacationApproval: required,
+		DiscordGuildID:   guildID,
+	})
+}
+
+// UpdateCapTier sets the war tier whose stat caps decide meets_cap
+// An empty tier goes back to setting meets_cap by hand
+func UpdateCapTier(db *DB, guildID, tier string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.Queries.UpdateCapTier(ctx, sqlcdb.UpdateCapTierParams{
+		CapTier:        sqlcdb.NullConfigCapTier{ConfigCapTier: sqlcdb.ConfigCapTier(tier), Valid: tier != ""},
+		DiscordGuildID: guildID,
+	})
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sqlcdb "PanickedBot/internal/db/sqlc"
+)
+
+// MemberWarTotals are a member's totals over the wars counted in war statistics
+type MemberWarTotals struct {
+	TotalWars   int
+	TotalKills  int
+	TotalDeaths int
+}
+
+// MemberWar is a member's kills and deaths in one war
+type MemberWar struct {
+	War        War
+	Kills      int
+	Deaths     int
+	IsExcluded bool // fought during one of the member's war stats exclusions
+}
+
+// CountsTowardStats reports whether the war counts toward the member's totals
+func (w MemberWar) CountsTowardStats() bool {
+	return !w.War.IsExcluded && !w.IsExcluded
+}
+
+// GetMemberWarTotals retrieves a member's wars, kills and deaths, leaving out excluded wars
+// and wars fought during the member's war stats exclusions like GetWarStats
+func GetMemberWarTotals(db *DB, guildID string, memberID int64) (MemberWarTotals, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	row, err := db.Queries.GetMemberWarTotals(ctx, sqlcdb.GetMemberWarTotalsParams{
+		DiscordGuildID: guildID,
+		RosterMemberID: sql.NullInt64{Int64: memberID, Valid: true},
+	})
+	if err != nil {
+		return MemberWarTotals{}, fmt.Errorf("failed to get member war totals: %w", err)
+	}
+
+	return MemberWarTotals{
+		TotalWars:   int(row.TotalWars),
+		TotalKills:  int(row.TotalKills),
+		TotalDeaths: int(row.TotalDeaths),
+	}, nil
+}
+
+// GetMemberRecentWars retrieves a member's kills and deaths in their latest wars, most recent first.
+// Wars that do not count toward the member's totals are included and marked.
+func GetMemberRecentWars(db *DB, guildID string, memberID int64, limit int) ([]MemberWar, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetMemberRecentWars(ctx, sqlcdb.GetMemberRecentWarsParams{
+		DiscordGuildID: guildID,
+		RosterMemberID: sql.NullInt64{Int64: memberID, Valid: true},
+		Limit:          int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member wars: %w", err)
+	}
+
+	wars := make([]MemberWar, 0, len(rows))
+	for _, row := range rows {
+		wars = append(wars, MemberWar{
+			War: convertWar(sqlcdb.GetWarRow{
+				ID:         row.ID,
+				WarDate:    row.WarDate,
+				Label:      row.Label,
+				Result:     row.Result,
+				WarType:    row.WarType,
+				Tier:       row.Tier,
+				IsExcluded: row.IsExcluded,
+			}),
+			Kills:      int(row.Kills),
+			Deaths:     int(row.Deaths),
+			IsExcluded: row.IsMemberExcluded,
+		})
+	}
+	return wars, nil
+}
@@ -30,7 +30,9 @@ type Member struct {
 	HP             sql.NullInt32
 	TotalAP        sql.NullInt32
 	TotalAAP       sql.NullInt32
+	GearVerifiedAt sql.NullTime // AP/AAP/DP confirmed from a stat window screenshot
 	MeetsCap       bool
+	CapOverride    sql.NullBool // officer override of MeetsCap
 	IsException    bool
 	IsMercenary    bool
 	IsActive       bool
@@ -162,6 +164,51 @@ func GetAllActiveMembers(db *DB, guildID string) ([]Member, error) {
 	return members, nil
 }
 
+// RosterFilter limits the members returned by GetRosterMembers
+type RosterFilter struct {
+	IncludeMercs    bool
+	IncludeInactive bool
+	TeamName        string // only members of this team when set
+}
+
+// GetRosterMembers retrieves the roster members matching the filter, ordered by family name
+func GetRosterMembers(db *DB, guildID string, filter RosterFilter) ([]Member, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var includeMercs interface{} = 0
+	if filter.IncludeMercs {
+		includeMercs = 1
+	}
+
+	var includeInactive interface{} = 0
+	if filter.IncludeInactive {
+		includeInactive = 1
+	}
+
+	var teamName sql.NullString
+	if filter.TeamName != "" {
+		teamName = sql.NullString{String: filter.TeamName, Valid: true}
+	}
+
+	rows, err := db.Queries.GetRosterMembers(ctx, sqlcdb.GetRosterMembersParams{
+		DiscordGuildID:  guildID,
+		IncludeMercs:    includeMercs,
+		IncludeInactive: includeInactive,
+		TeamName:        teamName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]Member, len(rows))
+	for i, row := range rows {
+		members[i] = *convertToMember(row)
+	}
+
+	return members, nil
+}
+
 // GetAllActiveMembersForAttendance retrieves all active members for attendance checking
 func GetAllActiveMembersForAttendance(db *DB, guildID string) ([]MemberForAttendance, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -192,7 +239,7 @@ func GetAllActiveMembersForAttendance(db *DB, guildID string) ([]MemberForAttend
 	return members, nil
 }
 
-// MemberVacation represents a vacation period
+// MemberVacation represents a vacation or attendance exclusion period
 type MemberVacation struct {
 	ID              int64
 	DiscordGuildID  string
@@ -204,7 +251,7 @@ type MemberVacation struct {
 	CreatedAt       time.Time
 }
 
-// GetMemberVacationsForAttendance retrieves vacations for a member
+// GetMemberVacationsForAttendance retrieves the approved vacations and attendance exclusions of a member
 func GetMemberVacationsForAttendance(db *DB, memberID int64) ([]MemberVacation, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -247,6 +294,17 @@ func GetMemberWarDates(db *DB, guildID string, memberID int64) ([]time.Time, err
 	return dates, nil
 }
 
+// GetGuildWarDates retrieves the dates on which the guild fought a war counted in statistics, since a date
+func GetGuildWarDates(db *DB, guildID string, since time.Time) ([]time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.Queries.GetGuildWarDates(ctx, sqlcdb.GetGuildWarDatesParams{
+		DiscordGuildID: guildID,
+		WarDate:        dateValue(since),
+	})
+}
+
 // SetMemberActive sets the active status of a member
 func SetMemberActive(db *DB, memberID int64, active bool) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -269,6 +327,17 @@ func SetMemberMercenary(db *DB, memberID int64, mercenary bool) error {
 	})
 }
 
+// SetMemberException sets whether a member's K/D is left out of guild totals
+func SetMemberException(db *DB, memberID int64, exception bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.Queries.SetMemberException(ctx, sqlcdb.SetMemberExceptionParams{
+		IsException: exception,
+		ID:          uint64(memberID),
+	})
+}
+
 // GetMemberTeamNames retrieves team names for a member
 func GetMemberTeamNames(db *DB, guildID string, memberID int64) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -345,6 +414,36 @@ type UpdateFields struct {
 	AP          *int
 	AAP         *int
 	DP          *int
+
+	// Discord user making the change, recorded in the gear history with AP/AAP/DP
+	UpdatedByUserID string
+
+	// AP/AAP/DP were read from a confirmed stat window screenshot; otherwise
+	// changing them clears the screenshot verification
+	GearVerified bool
+
+	// Extended gear profile
+	Evasion  *int
+	DR       *int
+	DRR      *float64 // percentage
+	Accuracy *int
+	HP       *int
+	TotalAP  *int
+	TotalAAP *int
+}
+
+// hasExtendedStats returns true if any stat of the extended gear profile is set
+func (f UpdateFields) hasExtendedStats() bool {
+	return f.Evasion != nil || f.DR != nil || f.DRR != nil || f.Accuracy != nil ||
+		f.HP != nil || f.TotalAP != nil || f.TotalAAP != nil
+}
+
+// nullInt32FromPtr converts an optional stat to a nullable column value
+func nullInt32FromPtr(v *int) sql.NullInt32 {
+	if v == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: int32(*v), Valid: true}
 }
 
 // UpdateMember updates member fields using sqlc-generated queries
@@ -355,11 +454,7 @@ func UpdateMember(db *DB, memberID int64, fields UpdateFields) error {
 	hasUpdates := false
 
 	if fields.FamilyName != nil {
-		err := db.Queries.UpdateMemberFamilyName(ctx, sqlcdb.UpdateMemberFamilyNameParams{
-			FamilyName: *fields.FamilyName,
-			ID:         uint64(memberID),
-		})
-		if err != nil {
+		if err := renameMember(ctx, db, memberID, *fields.FamilyName); err != nil {
 			return err
 		}
 		hasUpdates = true
@@ -409,52 +504,34 @@ func UpdateMember(db *DB, memberID int64, fields UpdateFields) error {
 		hasUpdates = true
 	}
 
-	// Handle gear stats - use the combined query if all three are provided
-	if fields.AP != nil && fields.AAP != nil && fields.DP != nil {
-		err := db.Queries.UpdateMemberGearStats(ctx, sqlcdb.UpdateMemberGearStatsParams{
-			Ap:  sql.NullInt32{Int32: int32(*fields.AP), Valid: true},
-			Aap: sql.NullInt32{Int32: int32(*fields.AAP), Valid: true},
-			Dp:  sql.NullInt32{Int32: int32(*fields.DP), Valid: true},
-			ID:  uint64(memberID),
-		})
-		if err != nil {
+	if fields.AP != nil || fields.AAP != nil || fields.DP != nil {
+		if err := updateGearStats(ctx, db, memberID, fields); err != nil {
 			return err
 		}
 		hasUpdates = true
-	} else {
-		// Update individual gear stats if only some are provided
-		if fields.AP != nil {
-			err := db.Queries.UpdateMemberAP(ctx, sqlcdb.UpdateMemberAPParams{
-				Ap: sql.NullInt32{Int32: int32(*fields.AP), Valid: true},
-				ID: uint64(memberID),
-			})
-			if err != nil {
-				return err
-			}
-			hasUpdates = true
-		}
+	}
 
-		if fields.AAP != nil {
-			err := db.Queries.UpdateMemberAAP(ctx, sqlcdb.UpdateMemberAAPParams{
-				Aap: sql.NullInt32{Int32: int32(*fields.AAP), Valid: true},
-				ID:  uint64(memberID),
-			})
-			if err != nil {
-				return err
-			}
-			hasUpdates = true
+	if fields.hasExtendedStats() {
+		// DRR is a DECIMAL(5,2) column
+		drr := sql.NullString{}
+		if fields.DRR != nil {
+			drr = sql.NullString{String: strconv.FormatFloat(*fields.DRR, 'f', 2, 64), Valid: true}
 		}
 
-		if fields.DP != nil {
-			err := db.Queries.UpdateMemberDP(ctx, sqlcdb.UpdateMemberDPParams{
-				Dp: sql.NullInt32{Int32: int32(*fields.DP), Valid: true},
-				ID: uint64(memberID),
-			})
-			if err != nil {
-				return err
-			}
-			hasUpdates = true
+		err := db.Queries.UpdateMemberExtendedStats(ctx, sqlcdb.UpdateMemberExtendedStatsParams{
+			Evasion:  nullInt32FromPtr(fields.Evasion),
+			Dr:       nullInt32FromPtr(fields.DR),
+			Drr:      drr,
+			Accuracy: nullInt32FromPtr(fields.Accuracy),
+			Hp:       nullInt32FromPtr(fields.HP),
+			TotalAp:  nullInt32FromPtr(fields.TotalAP),
+			TotalAap: nullInt32FromPtr(fields.TotalAAP),
+			ID:       uint64(memberID),
+		})
+		if err != nil {
+			return err
 		}
+		hasUpdates = true
 	}
 
 	if !hasUpdates {
@@ -465,10 +542,15 @@ func UpdateMember(db *DB, memberID int64, fields UpdateFields) error {
 }
 
 // CreateMember creates a new roster member using sqlc-generated query
+// Returns ErrAliasTaken if the family name is an alias of another member.
 func CreateMember(db *DB, guildID, discordUserID, familyName string) (int64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if err := checkNewMemberName(ctx, db.Queries, guildID, familyName); err != nil {
+		return 0, err
+	}
+
 	result, err := db.Queries.CreateMember(ctx, sqlcdb.CreateMemberParams{
 		DiscordGuildID: guildID,
 		DiscordUserID:  sql.NullString{String: discordUserID, Valid: true},
@@ -531,7 +613,9 @@ func convertToMember(row interface{}) *Member {
 			HP:             r.Hp,
 			TotalAP:        r.TotalAp,
 			TotalAAP:       r.TotalAap,
+			GearVerifiedAt: r.GearVerifiedAt,
 			MeetsCap:       r.MeetsCap,
+			CapOverride:    r.MeetsCapOverride,
 			IsException:    r.IsException,
 			IsMercenary:    r.IsMercenary,
 			IsActive:       r.IsActive,
@@ -563,7 +647,9 @@ func convertToMember(row interface{}) *Member {
 			HP:             r.Hp,
 			TotalAP:        r.TotalAp,
 			TotalAAP:       r.TotalAap,
+			GearVerifiedAt: r.GearVerifiedAt,
 			MeetsCap:       r.MeetsCap,
+			CapOverride:    r.MeetsCapOverride,
 			IsException:    r.IsException,
 			IsMercenary:    r.IsMercenary,
 			IsActive:       r.IsActive,
@@ -595,7 +681,9 @@ func convertToMember(row interface{}) *Member {
 			HP:             r.Hp,
 			TotalAP:        r.TotalAp,
 			TotalAAP:       r.TotalAap,
+			GearVerifiedAt: r.GearVerifiedAt,
 			MeetsCap:       r.MeetsCap,
+			CapOverride:    r.MeetsCapOverride,
 			IsException:    r.IsException,
 			IsMercenary:    r.IsMercenary,
 			IsActive:       r.IsActive,
@@ -627,7 +715,9 @@ func convertToMember(row interface{}) *Member {
 			HP:             r.Hp,
 			TotalAP:        r.TotalAp,
 			TotalAAP:       r.TotalAap,
+			GearVerifiedAt: r.GearVerifiedAt,
 			MeetsCap:       r.MeetsCap,
+			CapOverride:    r.MeetsCapOverride,
 			IsException:    r.IsException,
 			IsMercenary:    r.IsMercenary,
 			IsActive:       r.IsActive,
@@ -659,7 +749,43 @@ func convertToMember(row interface{}) *Member {
 			HP:             r.Hp,
 			TotalAP:        r.TotalAp,
 			TotalAAP:       r.TotalAap,
+			GearVerifiedAt: r.GearVerifiedAt,
+			MeetsCap:       r.MeetsCap,
+			CapOverride:    r.MeetsCapOverride,
+			IsException:    r.IsException,
+			IsMercenary:    r.IsMercenary,
+			IsActive:       r.IsActive,
+			CreatedAt:      r.CreatedAt,
+		}
+	case sqlcdb.GetRosterMembersRow:
+		var drr sql.NullFloat64
+		if r.Drr.Valid {
+			if val, err := strconv.ParseFloat(r.Drr.String, 64); err == nil {
+				drr = sql.NullFloat64{Float64: val, Valid: true}
+			}
+		}
+
+		return &Member{
+			ID:             int64(r.ID),
+			DiscordGuildID: r.DiscordGuildID,
+			DiscordUserID:  r.DiscordUserID,
+			FamilyName:     r.FamilyName,
+			DisplayName:    r.DisplayName,
+			Class:          r.Class,
+			Spec:           r.Spec,
+			AP:             r.Ap,
+			AAP:            r.Aap,
+			DP:             r.Dp,
+			Evasion:        r.Evasion,
+			DR:             r.Dr,
+			DRR:            drr,
+			Accuracy:       r.Accuracy,
+			HP:             r.Hp,
+			TotalAP:        r.TotalAp,
+			TotalAAP:       r.TotalAap,
+			GearVerifiedAt: r.GearVerifiedAt,
 			MeetsCap:       r.MeetsCap,
+			CapOverride:    r.MeetsCapOverride,
 			IsException:    r.IsException,
 			IsMercenary:    r.IsMercenary,
 			IsActive:       r.IsActive,
@@ -0,0 +1,49 @@
+-- name: GetMemberAlias :one
+SELECT ma.id, ma.roster_member_id, rm.family_name
+FROM member_aliases ma
+JOIN roster_members rm ON ma.roster_member_id = rm.id
+WHERE ma.discord_guild_id = ? AND LOWER(ma.alias) = LOWER(sqlc.arg(alias))
+LIMIT 1;
+
+-- name: CreateMemberAlias :exec
+INSERT INTO member_aliases (discord_guild_id, roster_member_id, alias, created_by_user_id)
+VALUES (?, ?, ?, ?);
+
+-- name: DeleteMemberAlias :execresult
+DELETE FROM member_aliases
+WHERE discord_guild_id = ? AND LOWER(alias) = LOWER(sqlc.arg(alias));
+
+-- name: GetMemberAliases :many
+SELECT ma.alias, rm.family_name, ma.created_by_user_id, ma.created_at
+FROM member_aliases ma
+JOIN roster_members rm ON ma.roster_member_id = rm.id
+WHERE ma.discord_guild_id = ?
+  AND (sqlc.narg('roster_member_id') IS NULL OR ma.roster_member_id = sqlc.narg('roster_member_id'))
+ORDER BY rm.family_name, ma.alias;
+
+-- name: GetAliasCandidates :many
+SELECT ma.alias, ma.roster_member_id, rm.family_name
+FROM member_aliases ma
+JOIN roster_members rm ON ma.roster_member_id = rm.id
+WHERE ma.discord_guild_id = ?;
+
+-- name: IsAliasOfOtherMember :one
+-- Whether the name is an alias of another member of the member's guild
+SELECT EXISTS (
+  SELECT 1 FROM member_aliases ma
+  JOIN roster_members rm ON rm.id = sqlc.arg('roster_member_id')
+  WHERE ma.discord_guild_id = rm.discord_guild_id
+    AND ma.roster_member_id <> rm.id
+    AND LOWER(ma.alias) = LOWER(sqlc.arg('alias'))
+) AS is_taken;
+
+-- name: KeepFamilyNameAsAlias :exec
+-- Stores the member's current family name as an alias before it is renamed
+INSERT IGNORE INTO member_aliases (discord_guild_id, roster_member_id, alias)
+SELECT roster_members.discord_guild_id, roster_members.id, roster_members.family_name
+FROM roster_members
+WHERE roster_members.id = ? AND roster_members.family_name <> sqlc.arg(new_family_name);
+
+-- name: DeleteMemberAliasForMember :exec
+DELETE FROM member_aliases
+WHERE roster_member_id = ? AND alias = ?;
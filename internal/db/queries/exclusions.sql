@@ -0,0 +1,25 @@
+-- name: CreateExclusion :execresult
+INSERT INTO member_exceptions (
+    discord_guild_id,
+    roster_member_id,
+    type,
+    scope,
+    start_date,
+    end_date,
+    reason,
+    created_by_user_id
+) VALUES (?, ?, 'exclude', ?, ?, ?, ?, ?);
+
+-- name: GetActiveExclusionsForGuild :many
+-- Exclusions that have not ended by the given date
+SELECT me.id, me.roster_member_id, me.scope, me.start_date, me.end_date, me.reason, me.created_by_user_id, rm.family_name
+FROM member_exceptions me
+JOIN roster_members rm ON me.roster_member_id = rm.id
+WHERE me.discord_guild_id = ?
+  AND me.type = 'exclude'
+  AND me.end_date >= ?
+ORDER BY me.start_date, me.id;
+
+-- name: DeleteExclusion :execresult
+DELETE FROM member_exceptions
+WHERE discord_guild_id = ? AND id = ? AND type = 'exclude';
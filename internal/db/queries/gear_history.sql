@@ -0,0 +1,34 @@
+-- name: RecordGearHistory :exec
+-- Snapshots the member's AP, AAP and DP after an update
+INSERT INTO gear_history (discord_guild_id, roster_member_id, ap, aap, dp, updated_by_user_id)
+SELECT rm.discord_guild_id, rm.id, rm.ap, rm.aap, rm.dp, CAST(sqlc.arg('updated_by_user_id') AS CHAR)
+FROM roster_members rm
+WHERE rm.id = sqlc.arg('roster_member_id');
+
+-- name: GetGearHistory :many
+SELECT id, ap, aap, dp, updated_by_user_id, created_at
+FROM gear_history
+WHERE roster_member_id = ?
+ORDER BY created_at DESC, id DESC
+LIMIT ?;
+
+-- name: GetGearAsOf :many
+-- Each member's most recent gear update made at or before the given time
+SELECT gh.roster_member_id, gh.ap, gh.aap, gh.dp, gh.created_at
+FROM gear_history gh
+WHERE gh.discord_guild_id = sqlc.arg('discord_guild_id')
+  AND gh.id = (
+    SELECT gh2.id
+    FROM gear_history gh2
+    WHERE gh2.roster_member_id = gh.roster_member_id
+      AND gh2.created_at <= sqlc.arg('as_of')
+    ORDER BY gh2.created_at DESC, gh2.id DESC
+    LIMIT 1
+  );
+
+-- name: GetGearUpdatedSince :many
+-- Members with a gear update made after the given time
+SELECT DISTINCT gh.roster_member_id
+FROM gear_history gh
+WHERE gh.discord_guild_id = sqlc.arg('discord_guild_id')
+  AND gh.created_at > sqlc.arg('since');
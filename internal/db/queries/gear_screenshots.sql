@@ -0,0 +1,17 @@
+-- name: CreateGearScreenshot :execresult
+INSERT INTO gear_screenshots (
+  discord_guild_id, roster_member_id, submitted_by_user_id, image_path,
+  ap, aap, dp, evasion, dr, drr, accuracy, hp, total_ap, total_aap
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetGearScreenshot :one
+SELECT id, discord_guild_id, roster_member_id, submitted_by_user_id, image_path,
+       ap, aap, dp, evasion, dr, drr, accuracy, hp, total_ap, total_aap, status, created_at
+FROM gear_screenshots
+WHERE id = ?;
+
+-- name: ResolveGearScreenshot :execresult
+-- Only a pending screenshot can be confirmed or canceled, so each is applied at most once
+UPDATE gear_screenshots
+SET status = ?, resolved_at = CURRENT_TIMESTAMP(6)
+WHERE id = ? AND status = 'pending';
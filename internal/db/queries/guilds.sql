@@ -13,3 +13,28 @@ ON DUPLICATE KEY UPDATE
     officer_role_id      = VALUES(officer_role_id),
     guild_member_role_id = VALUES(guild_member_role_id),
     mercenary_role_id    = VALUES(mercenary_role_id);
+
+-- name: UpdateMatchThreshold :exec
+UPDATE config SET match_threshold = ?
+WHERE discord_guild_id = ?;
+
+-- name: UpdateTimezone :exec
+UPDATE config SET timezone = ?
+WHERE discord_guild_id = ?;
+
+-- name: UpdateAttendancePolicy :exec
+UPDATE config
+SET attendance_week_start = ?, attendance_war_days = ?, attendance_min_wars = ?, attendance_min_percent = ?
+WHERE discord_guild_id = ?;
+
+-- name: UpdateVacationApproval :exec
+UPDATE config SET vacation_approval = ?
+WHERE discord_guild_id = ?;
+
+-- name: UpdateOCRSettings :exec
+UPDATE config SET ocr_backend = ?, ocr_model = ?, ocr_base_url = ?
+WHERE discord_guild_id = ?;
+
+-- name: UpdateCapTier :exec
+UPDATE config SET cap_tier = ?
+WHERE discord_guild_id = ?;
@@ -1,33 +1,49 @@
 -- name: GetMemberByDiscordUserID :one
 SELECT id, discord_guild_id, discord_user_id, family_name, display_name,
        class, spec, ap, aap, dp, evasion, dr, drr, 
-       accuracy, hp, total_ap, total_aap, meets_cap, is_exception, is_mercenary, is_active, created_at
+       accuracy, hp, total_ap, total_aap, gear_verified_at, meets_cap, meets_cap_override, is_exception, is_mercenary, is_active, created_at
 FROM roster_members 
 WHERE discord_guild_id = ? AND discord_user_id = ? AND is_active = 1
 LIMIT 1;
 
 -- name: GetMemberByFamilyName :one
+-- Matches the family name or one of the member's aliases, preferring the family name
 SELECT id, discord_guild_id, discord_user_id, family_name, display_name,
        class, spec, ap, aap, dp, evasion, dr, drr, 
-       accuracy, hp, total_ap, total_aap, meets_cap, is_exception, is_mercenary, is_active, created_at
-FROM roster_members 
-WHERE discord_guild_id = ? AND LOWER(family_name) = LOWER(sqlc.arg(family_name)) AND is_active = 1
+       accuracy, hp, total_ap, total_aap, gear_verified_at, meets_cap, meets_cap_override, is_exception, is_mercenary, is_active, created_at
+FROM roster_members rm
+WHERE rm.discord_guild_id = ? AND rm.is_active = 1
+  AND (LOWER(rm.family_name) = LOWER(sqlc.arg(family_name))
+       OR EXISTS (
+         SELECT 1 FROM member_aliases ma
+         WHERE ma.roster_member_id = rm.id
+           AND LOWER(ma.alias) = LOWER(sqlc.arg(family_name))
+       ))
+ORDER BY LOWER(rm.family_name) = LOWER(sqlc.arg(family_name)) DESC
 LIMIT 1;
 
 -- name: GetMemberByDiscordUserIDIncludingInactive :one
 SELECT id, discord_guild_id, discord_user_id, family_name, display_name,
        class, spec, ap, aap, dp, evasion, dr, drr, 
-       accuracy, hp, total_ap, total_aap, meets_cap, is_exception, is_mercenary, is_active, created_at
+       accuracy, hp, total_ap, total_aap, gear_verified_at, meets_cap, meets_cap_override, is_exception, is_mercenary, is_active, created_at
 FROM roster_members 
 WHERE discord_guild_id = ? AND discord_user_id = ?
 LIMIT 1;
 
 -- name: GetMemberByFamilyNameIncludingInactive :one
+-- Matches the family name or one of the member's aliases, preferring the family name
 SELECT id, discord_guild_id, discord_user_id, family_name, display_name,
        class, spec, ap, aap, dp, evasion, dr, drr, 
-       accuracy, hp, total_ap, total_aap, meets_cap, is_exception, is_mercenary, is_active, created_at
-FROM roster_members 
-WHERE discord_guild_id = ? AND LOWER(family_name) = LOWER(sqlc.arg(family_name))
+       accuracy, hp, total_ap, total_aap, gear_verified_at, meets_cap, meets_cap_override, is_exception, is_mercenary, is_active, created_at
+FROM roster_members rm
+WHERE rm.discord_guild_id = ?
+  AND (LOWER(rm.family_name) = LOWER(sqlc.arg(family_name))
+       OR EXISTS (
+         SELECT 1 FROM member_aliases ma
+         WHERE ma.roster_member_id = rm.id
+           AND LOWER(ma.alias) = LOWER(sqlc.arg(family_name))
+       ))
+ORDER BY LOWER(rm.family_name) = LOWER(sqlc.arg(family_name)) DESC
 LIMIT 1;
 
 -- name: GetMemberByID :one
@@ -40,11 +56,30 @@ LIMIT 1;
 -- name: GetAllActiveMembers :many
 SELECT id, discord_guild_id, discord_user_id, family_name, display_name,
        class, spec, ap, aap, dp, evasion, dr, drr, 
-       accuracy, hp, total_ap, total_aap, meets_cap, is_exception, is_mercenary, is_active, created_at
+       accuracy, hp, total_ap, total_aap, gear_verified_at, meets_cap, meets_cap_override, is_exception, is_mercenary, is_active, created_at
 FROM roster_members 
 WHERE discord_guild_id = ? AND is_active = 1 AND is_mercenary = 0
 ORDER BY family_name;
 
+-- name: GetRosterMembers :many
+-- Like GetAllActiveMembers, optionally including mercenaries and inactive members
+-- and limited to the members of a team
+SELECT id, discord_guild_id, discord_user_id, family_name, display_name,
+       class, spec, ap, aap, dp, evasion, dr, drr, 
+       accuracy, hp, total_ap, total_aap, gear_verified_at, meets_cap, meets_cap_override, is_exception, is_mercenary, is_active, created_at
+FROM roster_members rm
+WHERE rm.discord_guild_id = ?
+  AND (sqlc.narg('include_mercs') = 1 OR rm.is_mercenary = 0)
+  AND (sqlc.narg('include_inactive') = 1 OR rm.is_active = 1)
+  AND (sqlc.narg('team_name') IS NULL OR EXISTS (
+    SELECT 1 FROM member_teams mt
+    JOIN teams t ON mt.team_id = t.id
+    WHERE mt.roster_member_id = rm.id
+      AND t.discord_guild_id = rm.discord_guild_id
+      AND LOWER(t.display_name) = LOWER(sqlc.narg('team_name'))
+  ))
+ORDER BY rm.family_name;
+
 -- name: GetAllActiveMembersForAttendance :many
 SELECT id, discord_guild_id, discord_user_id, family_name, display_name,
        class, spec, ap, aap, dp, created_at
@@ -53,9 +88,12 @@ WHERE discord_guild_id = ? AND is_active = 1 AND is_mercenary = 0
 ORDER BY family_name;
 
 -- name: GetMemberVacationsForAttendance :many
+-- Approved vacations and attendance exclusions, both of which excuse the war nights they cover
 SELECT id, discord_guild_id, roster_member_id, start_date, end_date, reason, created_by_user_id, created_at
 FROM member_exceptions
-WHERE roster_member_id = ? AND type = 'vacation'
+WHERE roster_member_id = ?
+  AND status = 'approved'
+  AND (type = 'vacation' OR (type = 'exclude' AND scope IN ('all', 'attendance')))
 ORDER BY start_date;
 
 -- name: GetMemberWarDates :many
@@ -67,6 +105,14 @@ WHERE w.discord_guild_id = ?
   AND w.is_excluded = 0
 ORDER BY w.war_date;
 
+-- name: GetGuildWarDates :many
+SELECT DISTINCT war_date
+FROM wars
+WHERE discord_guild_id = ?
+  AND war_date >= ?
+  AND is_excluded = 0
+ORDER BY war_date;
+
 -- name: GetMemberTeamIDs :many
 SELECT team_id
 FROM member_teams
@@ -82,6 +128,15 @@ WHERE mt.roster_member_id = ?
   AND t.is_active = 1
 ORDER BY mt.assigned_at;
 
+-- name: GetGuildMemberTeamNames :many
+-- Team names of every member of the guild, for exports
+SELECT mt.roster_member_id, t.display_name
+FROM member_teams mt
+JOIN teams t ON mt.team_id = t.id
+WHERE t.discord_guild_id = ?
+  AND t.is_active = 1
+ORDER BY mt.roster_member_id, mt.assigned_at;
+
 -- name: DeleteMemberTeams :exec
 DELETE FROM member_teams
 WHERE roster_member_id = ?;
@@ -100,6 +155,11 @@ UPDATE roster_members
 SET is_mercenary = ?
 WHERE id = ?;
 
+-- name: SetMemberException :exec
+UPDATE roster_members 
+SET is_exception = ?
+WHERE id = ?;
+
 -- name: CreateMember :execresult
 INSERT INTO roster_members (discord_guild_id, discord_user_id, family_name, is_active)
 VALUES (?, ?, ?, 1);
@@ -114,6 +174,11 @@ UPDATE roster_members
 SET display_name = ?
 WHERE id = ?;
 
+-- name: UpdateMemberDiscordUserID :exec
+UPDATE roster_members 
+SET discord_user_id = ?
+WHERE id = ?;
+
 -- name: UpdateMemberClass :exec
 UPDATE roster_members 
 SET class = ?
@@ -129,11 +194,30 @@ UPDATE roster_members
 SET meets_cap = ?
 WHERE id = ?;
 
+-- name: UpdateMemberMeetsCapOverride :exec
+-- A NULL override lets meets_cap be computed from the stat caps again
+UPDATE roster_members
+SET meets_cap_override = sqlc.narg('meets_cap_override'),
+    meets_cap = COALESCE(sqlc.narg('meets_cap_override'), meets_cap)
+WHERE id = sqlc.arg('id');
+
 -- name: UpdateMemberGearStats :exec
 UPDATE roster_members 
 SET ap = ?, aap = ?, dp = ?
 WHERE id = ?;
 
+-- name: UpdateMemberExtendedStats :exec
+-- Stats passed as NULL keep their current value
+UPDATE roster_members 
+SET evasion = COALESCE(sqlc.narg('evasion'), evasion),
+    dr = COALESCE(sqlc.narg('dr'), dr),
+    drr = COALESCE(sqlc.narg('drr'), drr),
+    accuracy = COALESCE(sqlc.narg('accuracy'), accuracy),
+    hp = COALESCE(sqlc.narg('hp'), hp),
+    total_ap = COALESCE(sqlc.narg('total_ap'), total_ap),
+    total_aap = COALESCE(sqlc.narg('total_aap'), total_aap)
+WHERE id = sqlc.arg('id');
+
 -- name: UpdateMemberAP :exec
 UPDATE roster_members 
 SET ap = ?
@@ -148,3 +232,9 @@ WHERE id = ?;
 UPDATE roster_members 
 SET dp = ?
 WHERE id = ?;
+
+-- name: UpdateMemberGearVerified :exec
+-- Set when AP/AAP/DP come from a confirmed stat window screenshot, cleared when entered by hand
+UPDATE roster_members 
+SET gear_verified_at = ?
+WHERE id = ?;
@@ -0,0 +1,24 @@
+-- name: UpsertStatCap :exec
+INSERT INTO stat_caps (discord_guild_id, tier, max_ap, max_aap, max_dp, min_gs, updated_by_user_id)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    max_ap             = VALUES(max_ap),
+    max_aap            = VALUES(max_aap),
+    max_dp             = VALUES(max_dp),
+    min_gs             = VALUES(min_gs),
+    updated_by_user_id = VALUES(updated_by_user_id);
+
+-- name: GetStatCaps :many
+SELECT tier, max_ap, max_aap, max_dp, min_gs, updated_by_user_id, updated_at
+FROM stat_caps
+WHERE discord_guild_id = ?
+ORDER BY tier;
+
+-- name: DeleteStatCap :execresult
+DELETE FROM stat_caps
+WHERE discord_guild_id = ? AND tier = ?;
+
+-- name: GetMemberCapStatus :one
+SELECT discord_guild_id, ap, aap, dp, meets_cap, meets_cap_override
+FROM roster_members
+WHERE id = ?;
@@ -6,24 +6,42 @@ INSERT INTO member_exceptions (
     start_date,
     end_date,
     reason,
+    status,
     created_by_user_id
-) VALUES (?, ?, 'vacation', ?, ?, ?, ?);
+) VALUES (?, ?, 'vacation', ?, ?, ?, ?, ?);
 
 -- name: GetMemberVacations :many
-SELECT id, discord_guild_id, roster_member_id, type, start_date, end_date, reason, created_by_user_id, created_at
+SELECT id, discord_guild_id, roster_member_id, type, start_date, end_date, reason, status, created_by_user_id, created_at
 FROM member_exceptions
 WHERE roster_member_id = ? AND type = 'vacation'
 ORDER BY start_date DESC;
 
--- name: DeleteVacation :exec
+-- name: GetVacation :one
+SELECT me.id, me.roster_member_id, me.start_date, me.end_date, me.reason, me.status, me.created_by_user_id, rm.family_name
+FROM member_exceptions me
+JOIN roster_members rm ON me.roster_member_id = rm.id
+WHERE me.discord_guild_id = ? AND me.id = ? AND me.type = 'vacation';
+
+-- name: UpdateVacation :exec
+UPDATE member_exceptions
+SET start_date = ?, end_date = ?, reason = ?
+WHERE discord_guild_id = ? AND id = ? AND type = 'vacation';
+
+-- name: ApproveVacation :execresult
+UPDATE member_exceptions
+SET status = 'approved', approved_by_user_id = ?
+WHERE discord_guild_id = ? AND id = ? AND type = 'vacation' AND status = 'pending';
+
+-- name: DeleteVacation :execresult
 DELETE FROM member_exceptions
-WHERE id = ? AND type = 'vacation';
+WHERE discord_guild_id = ? AND id = ? AND type = 'vacation';
 
 -- name: GetActiveVacationsForGuild :many
-SELECT me.id, me.roster_member_id, me.start_date, me.end_date, me.reason, rm.family_name
+-- Vacations that have not ended by the given date, including pending requests
+SELECT me.id, me.roster_member_id, me.start_date, me.end_date, me.reason, me.status, me.created_by_user_id, rm.family_name
 FROM member_exceptions me
 JOIN roster_members rm ON me.roster_member_id = rm.id
 WHERE me.discord_guild_id = ? 
   AND me.type = 'vacation'
-  AND me.end_date >= CURDATE()
-ORDER BY me.start_date;
+  AND me.end_date >= ?
+ORDER BY me.start_date, me.id;
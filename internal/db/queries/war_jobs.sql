@@ -0,0 +1,104 @@
+-- name: CreateWarJob :execresult
+INSERT INTO war_jobs (discord_guild_id, request_channel_id, request_message_id,
+                      requested_by_user_id, result, war_type, tier, label, status)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'queued');
+
+-- name: CreateWarJobAttachment :exec
+INSERT INTO war_job_attachments (job_id, idx, discord_attachment_id, filename,
+                                 content_type, size_bytes, url, local_path)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetWarJob :one
+SELECT id, discord_guild_id, request_channel_id, request_message_id, requested_by_user_id,
+       result, war_type, tier, label, status, error, war_date, review_message_id, created_at
+FROM war_jobs
+WHERE id = ?;
+
+-- name: GetWarJobAttachments :many
+SELECT id, job_id, idx, discord_attachment_id, filename, content_type, size_bytes, url, local_path
+FROM war_job_attachments
+WHERE job_id = ?
+ORDER BY idx;
+
+-- name: SetWarJobAttachmentLocalPath :exec
+UPDATE war_job_attachments
+SET local_path = ?
+WHERE id = ?;
+
+-- name: GetQueuedWarJobIDs :many
+SELECT id
+FROM war_jobs
+WHERE status = 'queued'
+ORDER BY created_at, id;
+
+-- name: ClaimWarJob :execresult
+UPDATE war_jobs
+SET status = 'processing', started_at = NOW(6), error = NULL
+WHERE id = ? AND status = 'queued';
+
+-- name: CompleteWarJob :exec
+UPDATE war_jobs
+SET status = 'done', finished_at = NOW(6), error = NULL
+WHERE id = ?;
+
+-- name: FailWarJob :exec
+UPDATE war_jobs
+SET status = 'error', error = ?, finished_at = NOW(6)
+WHERE id = ?;
+
+-- name: RequeueInterruptedWarJobs :execresult
+-- Jobs interrupted while importing a reviewed war go back to review, the rest are extracted again
+UPDATE war_jobs
+SET status = CASE WHEN war_date IS NULL THEN 'queued' ELSE 'review' END, started_at = NULL
+WHERE status = 'processing';
+
+-- name: HoldWarJobForReview :exec
+UPDATE war_jobs
+SET status = 'review', war_date = ?
+WHERE id = ?;
+
+-- name: SetWarJobReviewMessage :exec
+UPDATE war_jobs
+SET review_message_id = ?
+WHERE id = ?;
+
+-- name: ClaimReviewedWarJob :execresult
+UPDATE war_jobs
+SET status = 'processing'
+WHERE id = ? AND status = 'review';
+
+-- name: ReturnWarJobToReview :exec
+-- A confirmed import that failed to save goes back to review with its decisions
+UPDATE war_jobs
+SET status = 'review'
+WHERE id = ? AND status = 'processing';
+
+-- name: CancelWarJob :execresult
+UPDATE war_jobs
+SET status = 'canceled', finished_at = NOW(6)
+WHERE id = ? AND status IN ('queued', 'review');
+
+-- name: DeleteWarJobLines :exec
+DELETE FROM war_job_lines
+WHERE job_id = ?;
+
+-- name: CreateWarJobLine :exec
+INSERT INTO war_job_lines (job_id, idx, ocr_name, kills, deaths, action,
+                           roster_member_id, matched_name, match_confidence, conflict)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetWarJobLines :many
+SELECT id, job_id, idx, ocr_name, kills, deaths, action, roster_member_id, matched_name, match_confidence, conflict
+FROM war_job_lines
+WHERE job_id = ?
+ORDER BY idx;
+
+-- name: GetWarJobLine :one
+SELECT id, job_id, idx, ocr_name, kills, deaths, action, roster_member_id, matched_name, match_confidence, conflict
+FROM war_job_lines
+WHERE id = ? AND job_id = ?;
+
+-- name: UpdateWarJobLine :exec
+UPDATE war_job_lines
+SET action = ?, roster_member_id = ?, matched_name = ?, match_confidence = ?
+WHERE id = ? AND job_id = ?;
@@ -3,11 +3,23 @@ SELECT
     rm.family_name,
     CAST(COUNT(DISTINCT CASE WHEN w.id IS NOT NULL THEN w.id END) AS UNSIGNED) as total_wars,
     MAX(CASE WHEN w.id IS NOT NULL THEN w.war_date END) as most_recent_war,
-    CAST(COALESCE(SUM(DISTINCT CASE WHEN w.id IS NOT NULL THEN wl.kills ELSE 0 END), 0) AS SIGNED) as total_kills,
-    CAST(COALESCE(SUM(DISTINCT CASE WHEN w.id IS NOT NULL THEN wl.deaths ELSE 0 END), 0) AS SIGNED) as total_deaths
+    CAST(COALESCE(SUM(CASE WHEN w.id IS NOT NULL THEN wl.kills END), 0) AS SIGNED) as total_kills,
+    CAST(COALESCE(SUM(CASE WHEN w.id IS NOT NULL THEN wl.deaths END), 0) AS SIGNED) as total_deaths
 FROM roster_members rm
 LEFT JOIN war_lines wl ON rm.id = wl.roster_member_id
 LEFT JOIN wars w ON wl.war_id = w.id AND w.is_excluded = 0
+  AND w.war_date BETWEEN sqlc.arg('start_date') AND sqlc.arg('end_date')
+  AND (sqlc.narg('war_type') IS NULL OR w.war_type = sqlc.narg('war_type'))
+  AND (sqlc.narg('tier') IS NULL OR w.tier = sqlc.narg('tier'))
+  AND (sqlc.narg('result') IS NULL OR w.result = sqlc.narg('result'))
+  -- Wars fought during one of the member's stats exclusions do not count
+  AND NOT EXISTS (
+    SELECT 1 FROM member_exceptions me
+    WHERE me.roster_member_id = wl.roster_member_id
+      AND me.type = 'exclude'
+      AND me.scope IN ('all', 'stats')
+      AND w.war_date BETWEEN me.start_date AND me.end_date
+  )
 WHERE rm.discord_guild_id = ?
   AND (sqlc.narg('include_mercs') = 1 OR rm.is_mercenary = 0)
   AND (sqlc.narg('include_inactive') = 1 OR rm.is_active = 1)
@@ -22,55 +34,268 @@ GROUP BY rm.id, rm.family_name
 HAVING total_wars > 0
 ORDER BY rm.family_name;
 
--- name: CreateWarJob :execresult
-INSERT INTO war_jobs (discord_guild_id, request_channel_id, request_message_id, 
-                      requested_by_user_id, status, started_at, finished_at)
-VALUES (?, ?, ?, ?, 'done', NOW(), NOW());
-
 -- name: CreateWar :execresult
 INSERT INTO wars (discord_guild_id, job_id, war_date, label, result, war_type, tier)
 VALUES (?, ?, ?, ?, ?, ?, ?);
 
--- name: GetRosterMemberByFamilyName :one
-SELECT id FROM roster_members
-WHERE discord_guild_id = ? AND LOWER(family_name) = LOWER(sqlc.arg(family_name))
-LIMIT 1;
-
 -- name: CreateRosterMember :execresult
+-- Returns the existing member's ID as the insert ID when the family name is already on the roster
 INSERT INTO roster_members (discord_guild_id, family_name, is_active)
-VALUES (?, ?, 1);
+VALUES (?, ?, 1)
+ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id);
+
+-- name: GetMatchCandidates :many
+SELECT id, family_name FROM roster_members
+WHERE discord_guild_id = ?
+ORDER BY family_name;
+
+-- name: GetLinkedOCRNames :many
+SELECT DISTINCT wl.ocr_name, wl.roster_member_id, rm.family_name
+FROM war_lines wl
+JOIN wars w ON wl.war_id = w.id
+JOIN roster_members rm ON wl.roster_member_id = rm.id
+WHERE w.discord_guild_id = ?;
 
 -- name: CreateWarLine :exec
-INSERT INTO war_lines (war_id, roster_member_id, ocr_name, kills, deaths, matched_name)
-VALUES (?, ?, ?, ?, ?, ?);
+-- Snapshots the linked member's class and spec at the time of the war
+INSERT INTO war_lines (war_id, roster_member_id, ocr_name, kills, deaths, matched_name, match_confidence, class, spec)
+VALUES (sqlc.arg('war_id'), sqlc.narg('roster_member_id'), sqlc.arg('ocr_name'), sqlc.arg('kills'), sqlc.arg('deaths'),
+        sqlc.narg('matched_name'), sqlc.narg('match_confidence'),
+        (SELECT rm.class FROM roster_members rm WHERE rm.id = sqlc.narg('roster_member_id')),
+        (SELECT rm.spec FROM roster_members rm WHERE rm.id = sqlc.narg('roster_member_id')));
 
 -- name: GetWarResults :many
 SELECT 
+    w.id,
     w.war_date,
+    w.label,
     w.result,
-    CAST(COALESCE(SUM(wl.kills), 0) AS SIGNED) as total_kills,
-    CAST(COALESCE(SUM(wl.deaths), 0) AS SIGNED) as total_deaths
+    w.is_excluded,
+    -- Members marked as K/D exceptions (shotcallers, flag carriers) and members excluded from
+    -- war stats on the war date are left out of guild totals
+    CAST(COALESCE(SUM(CASE WHEN COALESCE(rm.is_exception, 0) = 0 AND NOT EXISTS (
+        SELECT 1 FROM member_exceptions me
+        WHERE me.roster_member_id = wl.roster_member_id
+          AND me.type = 'exclude'
+          AND me.scope IN ('all', 'stats')
+          AND w.war_date BETWEEN me.start_date AND me.end_date
+    ) THEN wl.kills ELSE 0 END), 0) AS SIGNED) as total_kills,
+    CAST(COALESCE(SUM(CASE WHEN COALESCE(rm.is_exception, 0) = 0 AND NOT EXISTS (
+        SELECT 1 FROM member_exceptions me
+        WHERE me.roster_member_id = wl.roster_member_id
+          AND me.type = 'exclude'
+          AND me.scope IN ('all', 'stats')
+          AND w.war_date BETWEEN me.start_date AND me.end_date
+    ) THEN wl.deaths ELSE 0 END), 0) AS SIGNED) as total_deaths
 FROM wars w
 LEFT JOIN war_lines wl ON w.id = wl.war_id
-WHERE w.discord_guild_id = ? AND w.is_excluded = 0
-GROUP BY w.id, w.war_date, w.result
-ORDER BY w.war_date DESC;
+LEFT JOIN roster_members rm ON wl.roster_member_id = rm.id
+WHERE w.discord_guild_id = sqlc.arg('discord_guild_id')
+  AND w.war_date BETWEEN sqlc.arg('start_date') AND sqlc.arg('end_date')
+  AND (sqlc.narg('war_type') IS NULL OR w.war_type = sqlc.narg('war_type'))
+  AND (sqlc.narg('tier') IS NULL OR w.tier = sqlc.narg('tier'))
+  AND (sqlc.narg('result') IS NULL OR w.result = sqlc.narg('result'))
+GROUP BY w.id, w.war_date, w.label, w.result, w.is_excluded
+ORDER BY w.war_date DESC, w.id DESC;
+
+-- name: GetWar :one
+SELECT id, war_date, label, result, war_type, tier, is_excluded
+FROM wars
+WHERE discord_guild_id = ? AND id = ?;
+
+-- name: GetWarsByDate :many
+SELECT id, war_date, label, result, war_type, tier, is_excluded
+FROM wars
+WHERE discord_guild_id = ? AND war_date = ?
+ORDER BY id;
+
+-- name: SearchWars :many
+-- Matches the war ID, a DD-MM-YY date prefix or part of the label; an empty search lists the latest wars.
+-- Every use of search goes through CONCAT so sqlc infers the same string type for it.
+SELECT id, war_date, label, result, war_type, tier, is_excluded
+FROM wars
+WHERE discord_guild_id = sqlc.arg(discord_guild_id)
+  AND (CONCAT(sqlc.arg(search), '') = ''
+    OR CAST(id AS CHAR) = CONCAT(sqlc.arg(search), '')
+    OR DATE_FORMAT(war_date, '%d-%m-%y') LIKE CONCAT(sqlc.arg(search), '%')
+    OR label LIKE CONCAT('%', sqlc.arg(search), '%'))
+ORDER BY war_date DESC, id DESC
+LIMIT ?;
 
--- name: DeleteWarByDate :execresult
+-- name: DeleteWar :execresult
 DELETE FROM wars
-WHERE discord_guild_id = ? AND war_date = ?;
+WHERE discord_guild_id = ? AND id = ?;
 
--- name: GetWarStatsByDate :many
+-- name: GetWarStatsByWar :many
 SELECT 
     rm.family_name,
     CAST(COALESCE(SUM(wl.kills), 0) AS SIGNED) as kills,
-    CAST(COALESCE(SUM(wl.deaths), 0) AS SIGNED) as deaths
+    CAST(COALESCE(SUM(wl.deaths), 0) AS SIGNED) as deaths,
+    rm.is_exception,
+    EXISTS (
+        SELECT 1 FROM member_exceptions me
+        WHERE me.roster_member_id = rm.id
+          AND me.type = 'exclude'
+          AND me.scope IN ('all', 'stats')
+          AND w.war_date BETWEEN me.start_date AND me.end_date
+    ) as is_excluded
 FROM wars w
 LEFT JOIN war_lines wl ON w.id = wl.war_id
 LEFT JOIN roster_members rm ON wl.roster_member_id = rm.id
 WHERE w.discord_guild_id = ? 
-  AND w.war_date = ?
-  AND w.is_excluded = 0
+  AND w.id = ?
   AND rm.id IS NOT NULL
-GROUP BY rm.id, rm.family_name
+GROUP BY rm.id, rm.family_name, rm.is_exception, w.war_date
 ORDER BY rm.family_name;
+
+-- name: SetWarExcluded :exec
+UPDATE wars
+SET is_excluded = ?
+WHERE discord_guild_id = ? AND id = ?;
+
+-- name: UpdateWarDetails :exec
+UPDATE wars
+SET result = ?, war_type = ?, tier = ?, label = ?
+WHERE discord_guild_id = ? AND id = ?;
+
+-- name: GetWarLines :many
+SELECT wl.id, wl.ocr_name, wl.kills, wl.deaths, wl.roster_member_id, wl.matched_name, wl.match_confidence, rm.family_name
+FROM war_lines wl
+LEFT JOIN roster_members rm ON wl.roster_member_id = rm.id
+WHERE wl.war_id = ?
+ORDER BY wl.id;
+
+-- name: GetWarLine :one
+SELECT wl.id, wl.ocr_name, wl.kills, wl.deaths, wl.roster_member_id, wl.matched_name, wl.match_confidence, rm.family_name
+FROM war_lines wl
+LEFT JOIN roster_members rm ON wl.roster_member_id = rm.id
+WHERE wl.id = ? AND wl.war_id = ?;
+
+-- name: CountMemberWarLines :one
+-- Counts a member's lines in a war, ignoring the line being edited
+SELECT COUNT(*) FROM war_lines
+WHERE war_id = ? AND roster_member_id = ? AND id <> ?;
+
+-- name: UpdateWarLine :exec
+-- Linking the line to another member snapshots that member's class and spec.
+-- class and spec are set first so they compare against the line's previous member.
+UPDATE war_lines
+SET class = IF(war_lines.roster_member_id <=> sqlc.narg('roster_member_id'), war_lines.class,
+        (SELECT rm.class FROM roster_members rm WHERE rm.id = sqlc.narg('roster_member_id'))),
+    spec = IF(war_lines.roster_member_id <=> sqlc.narg('roster_member_id'), war_lines.spec,
+        (SELECT rm.spec FROM roster_members rm WHERE rm.id = sqlc.narg('roster_member_id'))),
+    kills = sqlc.arg('kills'),
+    deaths = sqlc.arg('deaths'),
+    roster_member_id = sqlc.narg('roster_member_id'),
+    matched_name = sqlc.narg('matched_name'),
+    match_confidence = sqlc.narg('match_confidence')
+WHERE war_lines.id = sqlc.arg('id') AND war_lines.war_id = sqlc.arg('war_id');
+
+-- name: DeleteWarLine :execresult
+DELETE FROM war_lines
+WHERE id = ? AND war_id = ?;
+
+-- name: GetWarLinesByOCRName :many
+-- Lines imported under the name, unmatched or linked to any member (e.g. one created by the import),
+-- and whether the target member already has another line in the same war
+SELECT wl.id, wl.war_id, wl.roster_member_id,
+       EXISTS (
+         SELECT 1 FROM war_lines other
+         WHERE other.war_id = wl.war_id
+           AND other.roster_member_id = sqlc.arg('member_id')
+           AND other.id <> wl.id
+       ) AS member_has_line
+FROM war_lines wl
+JOIN wars w ON wl.war_id = w.id
+WHERE w.discord_guild_id = sqlc.arg('discord_guild_id') AND wl.ocr_name = sqlc.arg('ocr_name')
+ORDER BY wl.id;
+
+-- name: SearchWarLineOCRNames :many
+-- Imported names matching the search, names with unmatched lines first
+SELECT wl.ocr_name
+FROM war_lines wl
+JOIN wars w ON wl.war_id = w.id
+WHERE w.discord_guild_id = sqlc.arg(discord_guild_id)
+  AND wl.ocr_name LIKE CONCAT('%', sqlc.arg(search), '%')
+GROUP BY wl.ocr_name
+ORDER BY MIN(wl.roster_member_id IS NOT NULL), wl.ocr_name
+LIMIT ?;
+
+-- name: GetWarLinesInRange :many
+-- Every line of the guild's wars fought between the two dates, including excluded wars
+SELECT w.id AS war_id, w.war_date, w.label, w.result, w.war_type, w.tier, w.is_excluded,
+       wl.id, wl.ocr_name, wl.kills, wl.deaths, wl.roster_member_id, wl.matched_name, wl.match_confidence, rm.family_name,
+       wl.class, wl.spec
+FROM war_lines wl
+JOIN wars w ON wl.war_id = w.id
+LEFT JOIN roster_members rm ON wl.roster_member_id = rm.id
+WHERE w.discord_guild_id = sqlc.arg('discord_guild_id')
+  AND w.war_date BETWEEN sqlc.arg('start_date') AND sqlc.arg('end_date')
+ORDER BY w.war_date, w.id, wl.id;
+
+-- name: GetClassStats :many
+-- Kills and deaths per class and spec snapshot of the war lines linked to members.
+-- Excluded wars, stats exclusions and K/D exceptions are left out like in the guild totals.
+SELECT COALESCE(wl.class, '') AS class,
+       COALESCE(wl.spec, '') AS spec,
+       CAST(COUNT(DISTINCT wl.roster_member_id) AS UNSIGNED) AS members,
+       CAST(COUNT(*) AS UNSIGNED) AS line_count,
+       CAST(COALESCE(SUM(wl.kills), 0) AS SIGNED) AS total_kills,
+       CAST(COALESCE(SUM(wl.deaths), 0) AS SIGNED) AS total_deaths
+FROM war_lines wl
+JOIN wars w ON wl.war_id = w.id
+JOIN roster_members rm ON wl.roster_member_id = rm.id
+WHERE w.discord_guild_id = sqlc.arg('discord_guild_id')
+  AND w.is_excluded = 0
+  AND rm.is_exception = 0
+  AND w.war_date BETWEEN sqlc.arg('start_date') AND sqlc.arg('end_date')
+  AND (sqlc.narg('war_type') IS NULL OR w.war_type = sqlc.narg('war_type'))
+  AND (sqlc.narg('tier') IS NULL OR w.tier = sqlc.narg('tier'))
+  AND (sqlc.narg('result') IS NULL OR w.result = sqlc.narg('result'))
+  AND NOT EXISTS (
+    SELECT 1 FROM member_exceptions me
+    WHERE me.roster_member_id = wl.roster_member_id
+      AND me.type = 'exclude'
+      AND me.scope IN ('all', 'stats')
+      AND w.war_date BETWEEN me.start_date AND me.end_date
+  )
+GROUP BY COALESCE(wl.class, ''), COALESCE(wl.spec, '')
+ORDER BY class, spec;
+
+-- name: GetMemberWarTotals :one
+-- A member's totals over the wars counted in war statistics, like GetWarStats
+SELECT CAST(COUNT(DISTINCT w.id) AS UNSIGNED) AS total_wars,
+       CAST(COALESCE(SUM(wl.kills), 0) AS SIGNED) AS total_kills,
+       CAST(COALESCE(SUM(wl.deaths), 0) AS SIGNED) AS total_deaths
+FROM war_lines wl
+JOIN wars w ON wl.war_id = w.id
+WHERE w.discord_guild_id = sqlc.arg('discord_guild_id')
+  AND wl.roster_member_id = sqlc.arg('roster_member_id')
+  AND w.is_excluded = 0
+  AND NOT EXISTS (
+    SELECT 1 FROM member_exceptions me
+    WHERE me.roster_member_id = wl.roster_member_id
+      AND me.type = 'exclude'
+      AND me.scope IN ('all', 'stats')
+      AND w.war_date BETWEEN me.start_date AND me.end_date
+  );
+
+-- name: GetMemberRecentWars :many
+-- A member's kills and deaths in their latest wars, including wars left out of war statistics
+SELECT w.id, w.war_date, w.label, w.result, w.war_type, w.tier, w.is_excluded,
+       CAST(SUM(wl.kills) AS SIGNED) AS kills,
+       CAST(SUM(wl.deaths) AS SIGNED) AS deaths,
+       EXISTS (
+         SELECT 1 FROM member_exceptions me
+         WHERE me.roster_member_id = wl.roster_member_id
+           AND me.type = 'exclude'
+           AND me.scope IN ('all', 'stats')
+           AND w.war_date BETWEEN me.start_date AND me.end_date
+       ) AS is_member_excluded
+FROM wars w
+JOIN war_lines wl ON wl.war_id = w.id
+WHERE w.discord_guild_id = sqlc.arg('discord_guild_id')
+  AND wl.roster_member_id = sqlc.arg('roster_member_id')
+GROUP BY w.id, w.war_date, w.label, w.result, w.war_type, w.tier, w.is_excluded, wl.roster_member_id
+ORDER BY w.war_date DESC, w.id DESC
+LIMIT ?;
@@ -0,0 +1,249 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sqlcdb "PanickedBot/internal/db/sqlc"
+)
+
+// Roster import outcomes
+const (
+	RosterImportCreated  = "created"
+	RosterImportUpdated  = "updated"
+	RosterImportRejected = "rejected"
+)
+
+// RosterImportMember is one member of a roster import
+// Empty or nil fields keep the member's current value
+type RosterImportMember struct {
+	FamilyName    string
+	DiscordUserID string
+	DisplayName   string
+	Class         string
+	Spec          string
+	AP            *int
+	AAP           *int
+	DP            *int
+	TeamIDs       []int64 // replaces the member's teams when not nil
+	IsMercenary   *bool
+}
+
+// RosterImportResult is the outcome of importing one member
+type RosterImportResult struct {
+	MemberID int64  // zero when rejected
+	Action   string // RosterImportCreated, RosterImportUpdated or RosterImportRejected
+	Problem  string // why the member was rejected
+}
+
+// ImportRosterMembers creates or updates roster members in a single transaction.
+// Members are matched by Discord user ID first and family name second, including inactive
+// members, and every imported member is made active. A member whose Discord user and family
+// name belong to different members, or whose new family name is an alias of another member,
+// is rejected without failing the import.
+func ImportRosterMembers(db *DB, guildID string, members []RosterImportMember, updatedByUserID string) ([]RosterImportResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := db.Queries.WithTx(tx.Tx)
+
+	results := make([]RosterImportResult, len(members))
+	for idx, m := range members {
+		result, err := importRosterMember(ctx, qtx, guildID, m, updatedByUserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import %s: %w", m.FamilyName, err)
+		}
+		results[idx] = result
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// aliasTakenResult rejects a member whose family name is an alias of another member
+func aliasTakenResult() RosterImportResult {
+	return RosterImportResult{
+		Action:  RosterImportRejected,
+		Problem: "the family name is an alias of another member",
+	}
+}
+
+// importRosterMember creates or updates one member within the import transaction
+func importRosterMember(ctx context.Context, qtx *sqlcdb.Queries, guildID string, m RosterImportMember, updatedByUserID string) (RosterImportResult, error) {
+	var memberID int64
+	var currentName string
+
+	if m.DiscordUserID != "" {
+		row, err := qtx.GetMemberByDiscordUserIDIncludingInactive(ctx, sqlcdb.GetMemberByDiscordUserIDIncludingInactiveParams{
+			DiscordGuildID: guildID,
+			DiscordUserID:  sql.NullString{String: m.DiscordUserID, Valid: true},
+		})
+		if err == nil {
+			memberID, currentName = int64(row.ID), row.FamilyName
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return RosterImportResult{}, err
+		}
+	}
+
+	byName, err := qtx.GetMemberByFamilyNameIncludingInactive(ctx, sqlcdb.GetMemberByFamilyNameIncludingInactiveParams{
+		DiscordGuildID: guildID,
+		FamilyName:     m.FamilyName,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return RosterImportResult{}, err
+	}
+	nameTaken := err == nil
+
+	action := RosterImportUpdated
+	switch {
+	case memberID != 0 && nameTaken && int64(byName.ID) != memberID:
+		problem := fmt.Sprintf("the Discord user is linked to %s and the family name belongs to another member", currentName)
+		if !strings.EqualFold(byName.FamilyName, m.FamilyName) {
+			problem = fmt.Sprintf("the Discord user is linked to %s and the family name is an alias of %s", currentName, byName.FamilyName)
+		}
+		return RosterImportResult{Action: RosterImportRejected, Problem: problem}, nil
+
+	case memberID != 0:
+		// Linked member under a new family name
+		if !nameTaken {
+			err := renameMemberTx(ctx, qtx, memberID, m.FamilyName)
+			if errors.Is(err, ErrAliasTaken) {
+				return aliasTakenResult(), nil
+			} else if err != nil {
+				return RosterImportResult{}, err
+			}
+		}
+
+	case nameTaken:
+		if byName.DiscordUserID.Valid && m.DiscordUserID != "" && byName.DiscordUserID.String != m.DiscordUserID {
+			return RosterImportResult{
+				Action:  RosterImportRejected,
+				Problem: "the family name is linked to another Discord user",
+			}, nil
+		}
+
+		memberID = int64(byName.ID)
+		if m.DiscordUserID != "" && !byName.DiscordUserID.Valid {
+			err := qtx.UpdateMemberDiscordUserID(ctx, sqlcdb.UpdateMemberDiscordUserIDParams{
+				DiscordUserID: sql.NullString{String: m.DiscordUserID, Valid: true},
+				ID:            uint64(memberID),
+			})
+			if err != nil {
+				return RosterImportResult{}, err
+			}
+		}
+
+	default:
+		err := checkNewMemberName(ctx, qtx, guildID, m.FamilyName)
+		if errors.Is(err, ErrAliasTaken) {
+			return aliasTakenResult(), nil
+		} else if err != nil {
+			return RosterImportResult{}, err
+		}
+
+		result, err := qtx.CreateMember(ctx, sqlcdb.CreateMemberParams{
+			DiscordGuildID: guildID,
+			DiscordUserID:  sql.NullString{String: m.DiscordUserID, Valid: m.DiscordUserID != ""},
+			FamilyName:     m.FamilyName,
+		})
+		if err != nil {
+			return RosterImportResult{}, err
+		}
+		if memberID, err = result.LastInsertId(); err != nil {
+			return RosterImportResult{}, err
+		}
+		action = RosterImportCreated
+	}
+
+	if err := qtx.SetMemberActive(ctx, sqlcdb.SetMemberActiveParams{IsActive: true, ID: uint64(memberID)}); err != nil {
+		return RosterImportResult{}, err
+	}
+
+	if m.DisplayName != "" {
+		err := qtx.UpdateMemberDisplayName(ctx, sqlcdb.UpdateMemberDisplayNameParams{
+			DisplayName: sql.NullString{String: m.DisplayName, Valid: true},
+			ID:          uint64(memberID),
+		})
+		if err != nil {
+			return RosterImportResult{}, err
+		}
+	}
+
+	if m.Class != "" {
+		err := qtx.UpdateMemberClass(ctx, sqlcdb.UpdateMemberClassParams{
+			Class: sql.NullString{String: m.Class, Valid: true},
+			ID:    uint64(memberID),
+		})
+		if err != nil {
+			return RosterImportResult{}, err
+		}
+	}
+
+	if m.Spec != "" {
+		err := qtx.UpdateMemberSpec(ctx, sqlcdb.UpdateMemberSpecParams{
+			Spec: sql.NullString{String: m.Spec, Valid: true},
+			ID:   uint64(memberID),
+		})
+		if err != nil {
+			return RosterImportResult{}, err
+		}
+	}
+
+	if m.IsMercenary != nil {
+		err := qtx.SetMemberMercenary(ctx, sqlcdb.SetMemberMercenaryParams{
+			IsMercenary: *m.IsMercenary,
+			ID:          uint64(memberID),
+		})
+		if err != nil {
+			return RosterImportResult{}, err
+		}
+	}
+
+	if m.AP != nil || m.AAP != nil || m.DP != nil {
+		err := updateGearStatsTx(ctx, qtx, memberID, UpdateFields{
+			AP:              m.AP,
+			AAP:             m.AAP,
+			DP:              m.DP,
+			UpdatedByUserID: updatedByUserID,
+		})
+		if err != nil {
+			return RosterImportResult{}, err
+		}
+	}
+
+	if m.TeamIDs != nil {
+		if err := qtx.DeleteMemberTeams(ctx, uint64(memberID)); err != nil {
+			return RosterImportResult{}, err
+		}
+
+		seen := make(map[int64]bool)
+		for _, teamID := range m.TeamIDs {
+			if seen[teamID] {
+				continue
+			}
+			seen[teamID] = true
+
+			err := qtx.InsertMemberTeam(ctx, sqlcdb.InsertMemberTeamParams{
+				RosterMemberID: uint64(memberID),
+				TeamID:         uint64(teamID),
+			})
+			if err != nil {
+				return RosterImportResult{}, err
+			}
+		}
+	}
+
+	return RosterImportResult{MemberID: memberID, Action: action}, nil
+}
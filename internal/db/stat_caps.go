@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sqlcdb "PanickedBot/internal/db/sqlc"
+)
+
+// StatCap holds the stat limits a guild set for a war tier
+type StatCap struct {
+	Tier            string // "1", "2" or "uncapped"
+	MaxAP           sql.NullInt32
+	MaxAAP          sql.NullInt32
+	MaxDP           sql.NullInt32
+	MinGS           sql.NullInt32
+	UpdatedByUserID string
+	UpdatedAt       time.Time
+}
+
+// MemberCapStatus holds what is needed to evaluate a member against the stat caps
+type MemberCapStatus struct {
+	DiscordGuildID string
+	AP             sql.NullInt32
+	AAP            sql.NullInt32
+	DP             sql.NullInt32
+	MeetsCap       bool
+	CapOverride    sql.NullBool
+}
+
+// SetStatCap creates or replaces the stat caps of a war tier
+// Limits left nil are not enforced
+func SetStatCap(db *DB, guildID, tier string, maxAP, maxAAP, maxDP, minGS *int, updatedByUserID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := db.Queries.UpsertStatCap(ctx, sqlcdb.UpsertStatCapParams{
+		DiscordGuildID:  guildID,
+		Tier:            sqlcdb.StatCapsTier(tier),
+		MaxAp:           nullInt32FromPtr(maxAP),
+		MaxAap:          nullInt32FromPtr(maxAAP),
+		MaxDp:           nullInt32FromPtr(maxDP),
+		MinGs:           nullInt32FromPtr(minGS),
+		UpdatedByUserID: updatedByUserID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save stat caps: %w", err)
+	}
+
+	return nil
+}
+
+// GetStatCaps retrieves the stat caps of every war tier of a guild
+func GetStatCaps(db *DB, guildID string) ([]StatCap, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetStatCaps(ctx, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stat caps: %w", err)
+	}
+
+	caps := make([]StatCap, len(rows))
+	for i, row := range rows {
+		caps[i] = StatCap{
+			Tier:            string(row.Tier),
+			MaxAP:           row.MaxAp,
+			MaxAAP:          row.MaxAap,
+			MaxDP:           row.MaxDp,
+			MinGS:           row.MinGs,
+			UpdatedByUserID: row.UpdatedByUserID,
+			UpdatedAt:       row.UpdatedAt,
+		}
+	}
+
+	return caps, nil
+}
+
+// DeleteStatCap removes the stat caps of a war tier
+// Returns false if the tier had no caps
+func DeleteStatCap(db *DB, guildID, tier string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.Queries.DeleteStatCap(ctx, sqlcdb.DeleteStatCapParams{
+		DiscordGuildID: guildID,
+		Tier:           sqlcdb.StatCapsTier(tier),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// GetMemberCapStatus retrieves a member's gear and meets_cap state
+func GetMemberCapStatus(db *DB, memberID int64) (*MemberCapStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	row, err := db.Queries.GetMemberCapStatus(ctx, uint64(memberID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &MemberCapStatus{
+		DiscordGuildID: row.DiscordGuildID,
+		AP:             row.Ap,
+		AAP:            row.Aap,
+		DP:             row.Dp,
+		MeetsCap:       row.MeetsCap,
+		CapOverride:    row.MeetsCapOverride,
+	}, nil
+}
+
+// SetMeetsCapOverride fixes a member's meets_cap to the given value
+// A nil override lets meets_cap be computed from the stat caps again
+func SetMeetsCapOverride(db *DB, memberID int64, override *bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var value sql.NullBool
+	if override != nil {
+		value = sql.NullBool{Bool: *override, Valid: true}
+	}
+
+	return db.Queries.UpdateMemberMeetsCapOverride(ctx, sqlcdb.UpdateMemberMeetsCapOverrideParams{
+		MeetsCapOverride: value,
+		ID:               uint64(memberID),
+	})
+}
@@ -8,19 +8,33 @@ import (
 	sqlcdb "PanickedBot/internal/db/sqlc"
 )
 
+// Vacation statuses. Pending vacations do not excuse attendance until an officer approves them.
+const (
+	VacationApproved = "approved"
+	VacationPending  = "pending"
+)
+
 // Vacation represents a vacation entry
 type Vacation struct {
 	ID              int64
 	RosterMemberID  int64
+	FamilyName      string // only set by queries that join the roster
 	StartDate       time.Time
 	EndDate         time.Time
 	Reason          string
+	Status          string
 	CreatedByUserID string
 	CreatedAt       time.Time
 }
 
-// CreateVacation creates a new vacation entry for a member
-func CreateVacation(db *DB, guildID string, memberID int64, startDate, endDate time.Time, reason string, createdByUserID string) (int64, error) {
+// IsPending returns true if the vacation is waiting for an officer's approval
+func (v Vacation) IsPending() bool {
+	return v.Status == VacationPending
+}
+
+// CreateVacation creates a new vacation entry for a member.
+// Pending vacations must be approved with ApproveVacation before they count toward attendance.
+func CreateVacation(db *DB, guildID string, memberID int64, startDate, endDate time.Time, reason string, createdByUserID string, pending bool) (int64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -29,13 +43,19 @@ func CreateVacation(db *DB, guildID string, memberID int64, startDate, endDate t
 		reasonNullString = sql.NullString{String: reason, Valid: true}
 	}
 
+	status := VacationApproved
+	if pending {
+		status = VacationPending
+	}
+
 	result, err := db.Queries.CreateVacation(ctx, sqlcdb.CreateVacationParams{
-		DiscordGuildID:   guildID,
-		RosterMemberID:   uint64(memberID),
-		StartDate:        startDate,
-		EndDate:          endDate,
-		Reason:           reasonNullString,
-		CreatedByUserID:  createdByUserID,
+		DiscordGuildID:  guildID,
+		RosterMemberID:  uint64(memberID),
+		StartDate:       dateValue(startDate),
+		EndDate:         dateValue(endDate),
+		Reason:          reasonNullString,
+		Status:          sqlcdb.MemberExceptionsStatus(status),
+		CreatedByUserID: createdByUserID,
 	})
 	if err != nil {
 		return 0, err
@@ -62,6 +82,7 @@ func GetMemberVacations(db *DB, memberID int64) ([]Vacation, error) {
 			StartDate:       row.StartDate,
 			EndDate:         row.EndDate,
 			Reason:          row.Reason.String,
+			Status:          string(row.Status),
 			CreatedByUserID: row.CreatedByUserID,
 			CreatedAt:       row.CreatedAt,
 		}
@@ -70,3 +91,114 @@ func GetMemberVacations(db *DB, memberID int64) ([]Vacation, error) {
 
 	return vacations, nil
 }
+
+// convertVacation converts a vacation row joined with the member's family name
+func convertVacation(row sqlcdb.GetVacationRow) Vacation {
+	return Vacation{
+		ID:              int64(row.ID),
+		RosterMemberID:  int64(row.RosterMemberID),
+		FamilyName:      row.FamilyName,
+		StartDate:       row.StartDate,
+		EndDate:         row.EndDate,
+		Reason:          row.Reason.String,
+		Status:          string(row.Status),
+		CreatedByUserID: row.CreatedByUserID,
+	}
+}
+
+// GetVacation retrieves a vacation of the guild by ID.
+// Returns sql.ErrNoRows if the guild has no such vacation.
+func GetVacation(db *DB, guildID string, vacationID int64) (*Vacation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	row, err := db.Queries.GetVacation(ctx, sqlcdb.GetVacationParams{
+		DiscordGuildID: guildID,
+		ID:             uint64(vacationID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	vacation := convertVacation(row)
+	return &vacation, nil
+}
+
+// GetActiveVacations retrieves the guild's current and upcoming vacations, including pending requests.
+// today is the current calendar day in the guild's timezone.
+func GetActiveVacations(db *DB, guildID string, today time.Time) ([]Vacation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetActiveVacationsForGuild(ctx, sqlcdb.GetActiveVacationsForGuildParams{
+		DiscordGuildID: guildID,
+		EndDate:        dateValue(today),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	vacations := make([]Vacation, 0, len(rows))
+	for _, row := range rows {
+		vacations = append(vacations, convertVacation(sqlcdb.GetVacationRow(row)))
+	}
+
+	return vacations, nil
+}
+
+// UpdateVacation changes the dates and reason of a vacation of the guild
+func UpdateVacation(db *DB, guildID string, vacationID int64, startDate, endDate time.Time, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.Queries.UpdateVacation(ctx, sqlcdb.UpdateVacationParams{
+		StartDate:      dateValue(startDate),
+		EndDate:        dateValue(endDate),
+		Reason:         sql.NullString{String: reason, Valid: reason != ""},
+		DiscordGuildID: guildID,
+		ID:             uint64(vacationID),
+	})
+}
+
+// ApproveVacation approves a pending vacation of the guild.
+// Returns false if the vacation does not exist or was already approved.
+func ApproveVacation(db *DB, guildID string, vacationID int64, approvedByUserID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.Queries.ApproveVacation(ctx, sqlcdb.ApproveVacationParams{
+		ApprovedByUserID: sql.NullString{String: approvedByUserID, Valid: true},
+		DiscordGuildID:   guildID,
+		ID:               uint64(vacationID),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// DeleteVacation removes a vacation of the guild.
+// Returns false if the guild has no such vacation.
+func DeleteVacation(db *DB, guildID string, vacationID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.Queries.DeleteVacation(ctx, sqlcdb.DeleteVacationParams{
+		DiscordGuildID: guildID,
+		ID:             uint64(vacationID),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,420 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	sqlcdb "PanickedBot/internal/db/sqlc"
+)
+
+// ErrWarLineExists is returned when a member would get a second line in the same war
+var ErrWarLineExists = errors.New("member already has a line in this war")
+
+// WarLine represents a single member line of an imported war
+type WarLine struct {
+	ID          int64
+	OCRName     string
+	Kills       int
+	Deaths      int
+	MemberID    int64  // zero when the line is not linked to a roster member
+	FamilyName  string // current family name of the linked member
+	MatchedName string
+	Confidence  float64 // zero for unmatched or manually linked lines
+}
+
+// WarUpdateFields represents war fields that can be updated; nil fields are left unchanged
+type WarUpdateFields struct {
+	Result  *string
+	WarType *string
+	Tier    *string
+	Label   *string // an empty label clears it
+}
+
+// WarLineFields represents war line fields that can be updated; nil fields are left unchanged
+type WarLineFields struct {
+	Kills      *int
+	Deaths     *int
+	MemberID   *int64
+	FamilyName string // family name of MemberID, stored as the line's matched name
+}
+
+// convertWarLine converts a war_lines row to a WarLine
+func convertWarLine(row sqlcdb.GetWarLinesRow) WarLine {
+	line := WarLine{
+		ID:          int64(row.ID),
+		OCRName:     row.OcrName,
+		Kills:       int(row.Kills),
+		Deaths:      int(row.Deaths),
+		MemberID:    row.RosterMemberID.Int64,
+		FamilyName:  row.FamilyName.String,
+		MatchedName: row.MatchedName.String,
+	}
+
+	if row.MatchConfidence.Valid {
+		if confidence, err := strconv.ParseFloat(row.MatchConfidence.String, 64); err == nil {
+			line.Confidence = confidence
+		}
+	}
+
+	return line
+}
+
+// getWarForEdit checks that a war belongs to the guild within an edit transaction
+func getWarForEdit(ctx context.Context, qtx *sqlcdb.Queries, guildID string, warID int64) (sqlcdb.GetWarRow, error) {
+	war, err := qtx.GetWar(ctx, sqlcdb.GetWarParams{
+		DiscordGuildID: guildID,
+		ID:             uint64(warID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return war, err
+		}
+		return war, fmt.Errorf("failed to load war: %w", err)
+	}
+	return war, nil
+}
+
+// checkMemberWarLine returns ErrWarLineExists if the member already has a line in the war other than lineID
+func checkMemberWarLine(ctx context.Context, qtx *sqlcdb.Queries, warID, memberID, lineID int64) error {
+	count, err := qtx.CountMemberWarLines(ctx, sqlcdb.CountMemberWarLinesParams{
+		WarID:          uint64(warID),
+		RosterMemberID: sql.NullInt64{Int64: memberID, Valid: true},
+		ID:             uint64(lineID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check existing lines: %w", err)
+	}
+	if count > 0 {
+		return ErrWarLineExists
+	}
+	return nil
+}
+
+// GetWarLines retrieves the lines of a guild's war in import order
+func GetWarLines(db *DB, guildID string, warID int64) ([]WarLine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := getWarForEdit(ctx, db.Queries, guildID, warID); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Queries.GetWarLines(ctx, uint64(warID))
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]WarLine, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, convertWarLine(row))
+	}
+
+	return lines, nil
+}
+
+// UpdateWar changes a war's result, type, tier or label in a transaction.
+// Returns sql.ErrNoRows if the guild has no such war.
+func UpdateWar(db *DB, guildID string, warID int64, fields WarUpdateFields) (*War, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := db.Queries.WithTx(tx.Tx)
+
+	row, err := getWarForEdit(ctx, qtx, guildID, warID)
+	if err != nil {
+		return nil, err
+	}
+
+	if fields.Result != nil {
+		row.Result = sqlcdb.NullWarsResult{WarsResult: sqlcdb.WarsResult(*fields.Result), Valid: *fields.Result != ""}
+	}
+	if fields.WarType != nil {
+		row.WarType = sqlcdb.NullWarsWarType{WarsWarType: sqlcdb.WarsWarType(*fields.WarType), Valid: *fields.WarType != ""}
+	}
+	if fields.Tier != nil {
+		row.Tier = sqlcdb.NullWarsTier{WarsTier: sqlcdb.WarsTier(*fields.Tier), Valid: *fields.Tier != ""}
+	}
+	if fields.Label != nil {
+		row.Label = sql.NullString{String: *fields.Label, Valid: *fields.Label != ""}
+	}
+
+	err = qtx.UpdateWarDetails(ctx, sqlcdb.UpdateWarDetailsParams{
+		Result:         row.Result,
+		WarType:        row.WarType,
+		Tier:           row.Tier,
+		Label:          row.Label,
+		DiscordGuildID: guildID,
+		ID:             uint64(warID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update war: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	war := convertWar(row)
+	return &war, nil
+}
+
+// AddWarLine adds a line for a roster member to a war in a transaction.
+// Returns sql.ErrNoRows if the guild has no such war, ErrWarLineExists if the member is already in it.
+func AddWarLine(db *DB, guildID string, warID int64, memberID int64, familyName string, kills, deaths int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := db.Queries.WithTx(tx.Tx)
+
+	if _, err := getWarForEdit(ctx, qtx, guildID, warID); err != nil {
+		return err
+	}
+
+	if err := checkMemberWarLine(ctx, qtx, warID, memberID, 0); err != nil {
+		return err
+	}
+
+	err = qtx.CreateWarLine(ctx, sqlcdb.CreateWarLineParams{
+		WarID:          uint64(warID),
+		RosterMemberID: sql.NullInt64{Int64: memberID, Valid: true},
+		OcrName:        familyName,
+		Kills:          int32(kills),
+		Deaths:         int32(deaths),
+		MatchedName:    sql.NullString{String: familyName, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create war line: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateWarLine corrects the kills, deaths or linked member of a war line in a transaction
+// and returns the updated line. Returns sql.ErrNoRows if the guild has no such war or line,
+// ErrWarLineExists if the new member already has another line in the war.
+func UpdateWarLine(db *DB, guildID string, warID, lineID int64, fields WarLineFields) (*WarLine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := db.Queries.WithTx(tx.Tx)
+
+	if _, err := getWarForEdit(ctx, qtx, guildID, warID); err != nil {
+		return nil, err
+	}
+
+	row, err := qtx.GetWarLine(ctx, sqlcdb.GetWarLineParams{
+		ID:    uint64(lineID),
+		WarID: uint64(warID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	line := convertWarLine(sqlcdb.GetWarLinesRow(row))
+
+	if fields.Kills != nil {
+		line.Kills = *fields.Kills
+	}
+	if fields.Deaths != nil {
+		line.Deaths = *fields.Deaths
+	}
+	if fields.MemberID != nil && *fields.MemberID != line.MemberID {
+		if err := checkMemberWarLine(ctx, qtx, warID, *fields.MemberID, lineID); err != nil {
+			return nil, err
+		}
+		// A manual link has no match confidence
+		line.MemberID = *fields.MemberID
+		line.FamilyName = fields.FamilyName
+		line.MatchedName = fields.FamilyName
+		line.Confidence = 0
+	}
+
+	err = qtx.UpdateWarLine(ctx, sqlcdb.UpdateWarLineParams{
+		Kills:           int32(line.Kills),
+		Deaths:          int32(line.Deaths),
+		RosterMemberID:  sql.NullInt64{Int64: line.MemberID, Valid: line.MemberID != 0},
+		MatchedName:     sql.NullString{String: line.MatchedName, Valid: line.MatchedName != ""},
+		MatchConfidence: nullConfidence(line.Confidence),
+		ID:              uint64(lineID),
+		WarID:           uint64(warID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update war line: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &line, nil
+}
+
+// RemoveWarLine deletes a line from a war in a transaction and returns the removed line.
+// Returns sql.ErrNoRows if the guild has no such war or line.
+func RemoveWarLine(db *DB, guildID string, warID, lineID int64) (*WarLine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := db.Queries.WithTx(tx.Tx)
+
+	if _, err := getWarForEdit(ctx, qtx, guildID, warID); err != nil {
+		return nil, err
+	}
+
+	row, err := qtx.GetWarLine(ctx, sqlcdb.GetWarLineParams{
+		ID:    uint64(lineID),
+		WarID: uint64(warID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := qtx.DeleteWarLine(ctx, sqlcdb.DeleteWarLineParams{
+		ID:    uint64(lineID),
+		WarID: uint64(warID),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to delete war line: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	line := convertWarLine(sqlcdb.GetWarLinesRow(row))
+	return &line, nil
+}
+
+// ocrNameLine is a war line imported under an OCR name that /editwar relink may link to a member
+type ocrNameLine struct {
+	ID            int64
+	WarID         int64
+	MemberID      int64 // 0 when unmatched
+	MemberHasLine bool  // the target member already has another line in the war
+}
+
+// planRelink picks the lines to link to a member. Lines already linked to the member are left
+// alone, and wars where the member already has a line, or gets one from an earlier line, are skipped.
+func planRelink(lines []ocrNameLine, memberID int64) (relink []ocrNameLine, skipped int) {
+	planned := make(map[int64]bool)
+	for _, line := range lines {
+		if line.MemberID == memberID {
+			continue
+		}
+		if line.MemberHasLine || planned[line.WarID] {
+			skipped++
+			continue
+		}
+		planned[line.WarID] = true
+		relink = append(relink, line)
+	}
+	return relink, skipped
+}
+
+// RelinkOCRName links every war line with the given OCR name to a roster member in a transaction,
+// whether the line is unmatched or linked to another member, e.g. one the import created from a misread.
+// Wars where the member already has a line are skipped.
+// Returns sql.ErrNoRows if no line has that name.
+func RelinkOCRName(db *DB, guildID, ocrName string, memberID int64, familyName string) (relinked, skipped int, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := db.Queries.WithTx(tx.Tx)
+
+	rows, err := qtx.GetWarLinesByOCRName(ctx, sqlcdb.GetWarLinesByOCRNameParams{
+		MemberID:       sql.NullInt64{Int64: memberID, Valid: true},
+		DiscordGuildID: guildID,
+		OcrName:        ocrName,
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load lines: %w", err)
+	}
+	if len(rows) == 0 {
+		return 0, 0, sql.ErrNoRows
+	}
+
+	lines := make([]ocrNameLine, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, ocrNameLine{
+			ID:            int64(row.ID),
+			WarID:         int64(row.WarID),
+			MemberID:      row.RosterMemberID.Int64,
+			MemberHasLine: row.MemberHasLine,
+		})
+	}
+
+	toRelink, skipped := planRelink(lines, memberID)
+	for _, planned := range toRelink {
+		line, err := qtx.GetWarLine(ctx, sqlcdb.GetWarLineParams{ID: uint64(planned.ID), WarID: uint64(planned.WarID)})
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to load war line: %w", err)
+		}
+
+		err = qtx.UpdateWarLine(ctx, sqlcdb.UpdateWarLineParams{
+			Kills:          line.Kills,
+			Deaths:         line.Deaths,
+			RosterMemberID: sql.NullInt64{Int64: memberID, Valid: true},
+			MatchedName:    sql.NullString{String: familyName, Valid: true},
+			ID:             uint64(planned.ID),
+			WarID:          uint64(planned.WarID),
+		})
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to relink war line: %w", err)
+		}
+		relinked++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return relinked, skipped, nil
+}
+
+// SearchWarLineOCRNames lists OCR names of war lines, names with lines not linked to any roster member first
+func SearchWarLineOCRNames(db *DB, guildID, search string, limit int) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.Queries.SearchWarLineOCRNames(ctx, sqlcdb.SearchWarLineOCRNamesParams{
+		DiscordGuildID: guildID,
+		Search:         search,
+		Limit:          int32(limit),
+	})
+}
@@ -0,0 +1,430 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	sqlcdb "PanickedBot/internal/db/sqlc"
+)
+
+// WarJob represents a queued /addwar import request
+type WarJob struct {
+	ID                int64
+	DiscordGuildID    string
+	RequestChannelID  string
+	RequestMessageID  string
+	RequestedByUserID string
+	Result            string // "win", "lose", or empty
+	WarType           string // "node", "siege", or empty
+	Tier              string // "1", "2", "uncapped", or empty
+	Label             string // copied to the war on import, empty for none
+	Status            string
+	Error             string
+	WarDate           time.Time // set once the job is held for review
+	ReviewMessageID   string
+	CreatedAt         time.Time
+}
+
+// WarJobAttachment represents a file attached to a war import request
+type WarJobAttachment struct {
+	ID                  int64
+	Idx                 int
+	DiscordAttachmentID string
+	Filename            string
+	ContentType         string
+	SizeBytes           int64
+	URL                 string
+	LocalPath           string
+}
+
+// CreateWarJob queues a new war import job and records its attachments, with their saved copies, in a transaction
+func CreateWarJob(db *DB, guildID, requestChannelID, requestMessageID, requestedByUserID, warResult, warType, tier, label string, attachments []WarJobAttachment) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := db.Queries.WithTx(tx.Tx)
+
+	params := sqlcdb.CreateWarJobParams{
+		DiscordGuildID:    guildID,
+		RequestChannelID:  requestChannelID,
+		RequestMessageID:  requestMessageID,
+		RequestedByUserID: requestedByUserID,
+		Label:             sql.NullString{String: label, Valid: label != ""},
+	}
+	if warResult != "" {
+		params.Result = sqlcdb.NullWarJobsResult{WarJobsResult: sqlcdb.WarJobsResult(warResult), Valid: true}
+	}
+	if warType != "" {
+		params.WarType = sqlcdb.NullWarJobsWarType{WarJobsWarType: sqlcdb.WarJobsWarType(warType), Valid: true}
+	}
+	if tier != "" {
+		params.Tier = sqlcdb.NullWarJobsTier{WarJobsTier: sqlcdb.WarJobsTier(tier), Valid: true}
+	}
+
+	result, err := qtx.CreateWarJob(ctx, params)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create war job: %w", err)
+	}
+
+	jobID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get job ID: %w", err)
+	}
+
+	for idx, attachment := range attachments {
+		err = qtx.CreateWarJobAttachment(ctx, sqlcdb.CreateWarJobAttachmentParams{
+			JobID:               uint64(jobID),
+			Idx:                 int32(idx),
+			DiscordAttachmentID: attachment.DiscordAttachmentID,
+			Filename:            attachment.Filename,
+			ContentType:         sql.NullString{String: attachment.ContentType, Valid: attachment.ContentType != ""},
+			SizeBytes:           sql.NullInt64{Int64: attachment.SizeBytes, Valid: attachment.SizeBytes > 0},
+			Url:                 attachment.URL,
+			LocalPath:           sql.NullString{String: attachment.LocalPath, Valid: attachment.LocalPath != ""},
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to record attachment '%s': %w", attachment.Filename, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return jobID, nil
+}
+
+// GetWarJob retrieves a war import job by ID
+func GetWarJob(db *DB, jobID int64) (*WarJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	row, err := db.Queries.GetWarJob(ctx, uint64(jobID))
+	if err != nil {
+		return nil, err
+	}
+
+	job := &WarJob{
+		ID:                int64(row.ID),
+		DiscordGuildID:    row.DiscordGuildID,
+		RequestChannelID:  row.RequestChannelID,
+		RequestMessageID:  row.RequestMessageID,
+		RequestedByUserID: row.RequestedByUserID,
+		Label:             row.Label.String,
+		Status:            string(row.Status),
+		Error:             row.Error.String,
+		WarDate:           row.WarDate.Time,
+		ReviewMessageID:   row.ReviewMessageID.String,
+		CreatedAt:         row.CreatedAt,
+	}
+	if row.Result.Valid {
+		job.Result = string(row.Result.WarJobsResult)
+	}
+	if row.WarType.Valid {
+		job.WarType = string(row.WarType.WarJobsWarType)
+	}
+	if row.Tier.Valid {
+		job.Tier = string(row.Tier.WarJobsTier)
+	}
+
+	return job, nil
+}
+
+// GetWarJobAttachments retrieves the attachments of a war import job in upload order
+func GetWarJobAttachments(db *DB, jobID int64) ([]WarJobAttachment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetWarJobAttachments(ctx, uint64(jobID))
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make([]WarJobAttachment, 0, len(rows))
+	for _, row := range rows {
+		attachments = append(attachments, WarJobAttachment{
+			ID:                  int64(row.ID),
+			Idx:                 int(row.Idx),
+			DiscordAttachmentID: row.DiscordAttachmentID,
+			Filename:            row.Filename,
+			ContentType:         row.ContentType.String,
+			SizeBytes:           row.SizeBytes.Int64,
+			URL:                 row.Url,
+			LocalPath:           row.LocalPath.String,
+		})
+	}
+
+	return attachments, nil
+}
+
+// SetWarJobAttachmentLocalPath records where an attachment was saved on disk
+func SetWarJobAttachmentLocalPath(db *DB, attachmentID int64, localPath string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.Queries.SetWarJobAttachmentLocalPath(ctx, sqlcdb.SetWarJobAttachmentLocalPathParams{
+		LocalPath: sql.NullString{String: localPath, Valid: localPath != ""},
+		ID:        uint64(attachmentID),
+	})
+}
+
+// GetQueuedWarJobIDs retrieves the IDs of all queued war import jobs, oldest first
+func GetQueuedWarJobIDs(db *DB) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ids, err := db.Queries.GetQueuedWarJobIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]int64, len(ids))
+	for i, id := range ids {
+		result[i] = int64(id)
+	}
+
+	return result, nil
+}
+
+// ClaimWarJob moves a queued job to processing
+// Returns false if the job was not queued (already claimed, finished or canceled)
+func ClaimWarJob(db *DB, jobID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.Queries.ClaimWarJob(ctx, uint64(jobID))
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// FailWarJob marks a job as failed and stores the error message
+func FailWarJob(db *DB, jobID int64, errMsg string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.Queries.FailWarJob(ctx, sqlcdb.FailWarJobParams{
+		Error: sql.NullString{String: errMsg, Valid: true},
+		ID:    uint64(jobID),
+	})
+}
+
+// RequeueInterruptedWarJobs puts jobs left in processing (e.g. by a crash or restart) back in the queue
+// Returns the number of requeued jobs
+func RequeueInterruptedWarJobs(db *DB) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.Queries.RequeueInterruptedWarJobs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// HoldWarJobForReview stores the matched lines of a job and holds it until an officer confirms or cancels it
+func HoldWarJobForReview(db *DB, jobID int64, warDate time.Time, lines []MatchedWarLine) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := db.Queries.WithTx(tx.Tx)
+
+	// Replace lines left by an interrupted earlier attempt
+	if err := qtx.DeleteWarJobLines(ctx, uint64(jobID)); err != nil {
+		return fmt.Errorf("failed to clear job lines: %w", err)
+	}
+
+	for idx, line := range lines {
+		err = qtx.CreateWarJobLine(ctx, sqlcdb.CreateWarJobLineParams{
+			JobID:           uint64(jobID),
+			Idx:             int32(idx),
+			OcrName:         line.FamilyName,
+			Kills:           int32(line.Kills),
+			Deaths:          int32(line.Deaths),
+			Action:          sqlcdb.WarJobLinesAction(line.Action),
+			RosterMemberID:  sql.NullInt64{Int64: line.MemberID, Valid: line.MemberID != 0},
+			MatchedName:     sql.NullString{String: line.MatchedName, Valid: line.MatchedName != ""},
+			MatchConfidence: nullConfidence(line.Confidence),
+			Conflict:        line.Conflict,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to store line for '%s': %w", line.FamilyName, err)
+		}
+	}
+
+	err = qtx.HoldWarJobForReview(ctx, sqlcdb.HoldWarJobForReviewParams{
+		WarDate: sql.NullTime{Time: dateValue(warDate), Valid: true},
+		ID:      uint64(jobID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to hold job for review: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SetWarJobReviewMessage records the message holding a job's review controls
+func SetWarJobReviewMessage(db *DB, jobID int64, messageID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.Queries.SetWarJobReviewMessage(ctx, sqlcdb.SetWarJobReviewMessageParams{
+		ReviewMessageID: sql.NullString{String: messageID, Valid: messageID != ""},
+		ID:              uint64(jobID),
+	})
+}
+
+// GetWarJobLines retrieves the lines of a job held for review in import order
+func GetWarJobLines(db *DB, jobID int64) ([]MatchedWarLine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetWarJobLines(ctx, uint64(jobID))
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]MatchedWarLine, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, convertWarJobLine(row))
+	}
+
+	return lines, nil
+}
+
+// GetWarJobLine retrieves a single line of a job held for review
+func GetWarJobLine(db *DB, jobID, lineID int64) (*MatchedWarLine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	row, err := db.Queries.GetWarJobLine(ctx, sqlcdb.GetWarJobLineParams{
+		ID:    uint64(lineID),
+		JobID: uint64(jobID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	line := convertWarJobLine(row)
+	return &line, nil
+}
+
+// UpdateWarJobLine stores an officer's decision for a line of a job held for review
+func UpdateWarJobLine(db *DB, jobID int64, line MatchedWarLine) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.Queries.UpdateWarJobLine(ctx, sqlcdb.UpdateWarJobLineParams{
+		Action:          sqlcdb.WarJobLinesAction(line.Action),
+		RosterMemberID:  sql.NullInt64{Int64: line.MemberID, Valid: line.MemberID != 0},
+		MatchedName:     sql.NullString{String: line.MatchedName, Valid: line.MatchedName != ""},
+		MatchConfidence: nullConfidence(line.Confidence),
+		ID:              uint64(line.ID),
+		JobID:           uint64(jobID),
+	})
+}
+
+// ClaimReviewedWarJob moves a job held for review to processing
+// Returns false if the job is no longer awaiting review (already confirmed or canceled)
+func ClaimReviewedWarJob(db *DB, jobID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.Queries.ClaimReviewedWarJob(ctx, uint64(jobID))
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// ReturnWarJobToReview puts a confirmed job whose import failed back in review
+func ReturnWarJobToReview(db *DB, jobID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.Queries.ReturnWarJobToReview(ctx, uint64(jobID))
+}
+
+// CancelWarJob cancels a job that is queued or held for review
+// Returns false if the job had already been picked up or finished
+func CancelWarJob(db *DB, jobID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.Queries.CancelWarJob(ctx, uint64(jobID))
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// convertWarJobLine converts a stored job line to a MatchedWarLine
+func convertWarJobLine(row sqlcdb.WarJobLine) MatchedWarLine {
+	line := MatchedWarLine{
+		WarLineData: WarLineData{
+			FamilyName: row.OcrName,
+			Kills:      int(row.Kills),
+			Deaths:     int(row.Deaths),
+		},
+		ID:          int64(row.ID),
+		Action:      string(row.Action),
+		MemberID:    row.RosterMemberID.Int64,
+		MatchedName: row.MatchedName.String,
+		Conflict:    row.Conflict,
+	}
+
+	if row.MatchConfidence.Valid {
+		if confidence, err := strconv.ParseFloat(row.MatchConfidence.String, 64); err == nil {
+			line.Confidence = confidence
+		}
+	}
+
+	return line
+}
+
+// nullConfidence converts a match confidence to its DECIMAL column value, NULL when unmatched
+func nullConfidence(confidence float64) sql.NullString {
+	if confidence <= 0 {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: fmt.Sprintf("%.4f", confidence), Valid: true}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	sqlcdb "PanickedBot/internal/db/sqlc"
+	"PanickedBot/internal/namematch"
 )
 
 // WarStats represents war statistics for a member
@@ -18,11 +20,45 @@ type WarStats struct {
 	TotalDeaths   int
 }
 
+// WarFilter limits the wars counted by the war statistics queries. The zero value counts every war.
+type WarFilter struct {
+	Start   time.Time // first war date, zero for the first war
+	End     time.Time // last war date, zero for the latest war
+	WarType string    // "node" or "siege", empty for all
+	Tier    string    // "1", "2" or "uncapped", empty for all
+	Result  string    // "win" or "lose", empty for all
+}
+
+// dateRange returns the first and last war dates of the filter, with open ends filled in
+func (f WarFilter) dateRange() (start, end time.Time) {
+	start, end = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	if !f.Start.IsZero() {
+		start = dateValue(f.Start)
+	}
+	if !f.End.IsZero() {
+		end = dateValue(f.End)
+	}
+	return start, end
+}
+
+func (f WarFilter) warType() sqlcdb.NullWarsWarType {
+	return sqlcdb.NullWarsWarType{WarsWarType: sqlcdb.WarsWarType(f.WarType), Valid: f.WarType != ""}
+}
+
+func (f WarFilter) tier() sqlcdb.NullWarsTier {
+	return sqlcdb.NullWarsTier{WarsTier: sqlcdb.WarsTier(f.Tier), Valid: f.Tier != ""}
+}
+
+func (f WarFilter) result() sqlcdb.NullWarsResult {
+	return sqlcdb.NullWarsResult{WarsResult: sqlcdb.WarsResult(f.Result), Valid: f.Result != ""}
+}
+
 // GetWarStats retrieves war statistics for members
 // includeInactive: if true, includes inactive members in results (default true)
 // includeMercs: if true, includes mercenary members in results (default false)
 // teamName: if not empty, filters results to only members of that team
-func GetWarStats(db *DB, guildID string, includeInactive bool, includeMercs bool, teamName string) ([]WarStats, error) {
+// filter: only wars matching the filter are counted
+func GetWarStats(db *DB, guildID string, includeInactive bool, includeMercs bool, teamName string, filter WarFilter) ([]WarStats, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -42,11 +78,17 @@ func GetWarStats(db *DB, guildID string, includeInactive bool, includeMercs bool
 		teamNameVal = sql.NullString{String: teamName, Valid: true}
 	}
 
+	startDate, endDate := filter.dateRange()
 	rows, err := db.Queries.GetWarStats(ctx, sqlcdb.GetWarStatsParams{
 		DiscordGuildID:  guildID,
 		IncludeInactive: includeInactiveVal,
 		IncludeMercs:    includeMercsVal,
 		TeamName:        teamNameVal,
+		StartDate:       startDate,
+		EndDate:         endDate,
+		WarType:         filter.warType(),
+		Tier:            filter.tier(),
+		Result:          filter.result(),
 	})
 	if err != nil {
 		return nil, err
@@ -81,35 +123,248 @@ type WarLineData struct {
 	Deaths     int
 }
 
-// CreateWarFromCSV creates a war entry and associated war lines from CSV data
-func CreateWarFromCSV(db *DB, guildID string, requestChannelID string, requestMessageID string, requestedByUserID string, warDate time.Time, warResult string, warType string, tier string, warLines []WarLineData) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+// SuggestionThreshold is the lowest match confidence offered as a suggestion.
+// Lines below the guild's match threshold but above this wait for an officer to confirm the suggestion,
+// lines below it default to creating a new member.
+const SuggestionThreshold = 0.5
+
+// War line import actions
+const (
+	WarLineActionPending = "pending" // waiting for an officer's decision
+	WarLineActionLink    = "link"
+	WarLineActionCreate  = "create"
+	WarLineActionDrop    = "drop"
+)
 
-	tx, err := db.BeginTxx(ctx, nil)
+// MatchedWarLine is an imported war line and how it resolves to the roster
+type MatchedWarLine struct {
+	WarLineData
+	ID          int64 // war_job_lines ID, zero until the import is held for review
+	Action      string
+	MemberID    int64 // member to link, or the suggested member while pending; zero if none
+	MatchedName string
+	Confidence  float64 // zero when the line has no match
+	Conflict    bool    // overlapping screenshots show different kills or deaths for the same member
+}
+
+// Resolved reports whether the line can be imported without an officer's decision
+func (l MatchedWarLine) Resolved() bool {
+	switch l.Action {
+	case WarLineActionLink:
+		return l.MemberID != 0
+	case WarLineActionCreate, WarLineActionDrop:
+		return true
+	}
+	return false
+}
+
+// WarImportSummary describes how the lines of an imported war were applied to the roster
+type WarImportSummary struct {
+	WarID   int64
+	Linked  int
+	Created []string
+	Dropped int
+}
+
+// loadNameMatcher builds a matcher from the guild roster, member aliases and OCR names linked by earlier imports
+func loadNameMatcher(ctx context.Context, q *sqlcdb.Queries, guildID string) (*namematch.Matcher, error) {
+	members, err := q.GetMatchCandidates(ctx, guildID)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to load roster: %w", err)
 	}
-	defer func() { _ = tx.Rollback() }()
 
-	// Create queries with transaction
-	qtx := db.Queries.WithTx(tx.Tx)
+	aliases, err := q.GetAliasCandidates(ctx, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aliases: %w", err)
+	}
 
-	// Create war_job entry
-	jobResult, err := qtx.CreateWarJob(ctx, sqlcdb.CreateWarJobParams{
-		DiscordGuildID:    guildID,
-		RequestChannelID:  requestChannelID,
-		RequestMessageID:  requestMessageID,
-		RequestedByUserID: requestedByUserID,
-	})
+	linked, err := q.GetLinkedOCRNames(ctx, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known names: %w", err)
+	}
+
+	candidates := make([]namematch.Candidate, 0, len(members)+len(aliases)+len(linked))
+	for _, member := range members {
+		candidates = append(candidates, namematch.Candidate{
+			MemberID:   int64(member.ID),
+			FamilyName: member.FamilyName,
+			Name:       member.FamilyName,
+		})
+	}
+	for _, alias := range aliases {
+		candidates = append(candidates, namematch.Candidate{
+			MemberID:   int64(alias.RosterMemberID),
+			FamilyName: alias.FamilyName,
+			Name:       alias.Alias,
+		})
+	}
+	for _, row := range linked {
+		candidates = append(candidates, namematch.Candidate{
+			MemberID:   row.RosterMemberID.Int64,
+			FamilyName: row.FamilyName,
+			Name:       row.OcrName,
+		})
+	}
+
+	return namematch.New(candidates), nil
+}
+
+// LoadNameMatcher builds a name matcher for the guild roster
+func LoadNameMatcher(db *DB, guildID string) (*namematch.Matcher, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return loadNameMatcher(ctx, db.Queries, guildID)
+}
+
+// MatchWarLines matches parsed war lines to the guild roster.
+// Lines whose best match reaches matchThreshold are linked, uncertain or tied matches are left pending
+// with the suggested member, and names with no plausible match default to a new member.
+func MatchWarLines(db *DB, guildID string, matchThreshold float64, warLines []WarLineData) ([]MatchedWarLine, error) {
+	matcher, err := LoadNameMatcher(db, guildID)
 	if err != nil {
-		return fmt.Errorf("failed to create war job: %w", err)
+		return nil, err
+	}
+
+	matched := make([]MatchedWarLine, 0, len(warLines))
+	for _, line := range warLines {
+		matched = append(matched, matchWarLine(matcher, line, matchThreshold))
+	}
+
+	return matched, nil
+}
+
+// matchWarLine picks the default action for a line based on its closest roster match
+func matchWarLine(matcher *namematch.Matcher, line WarLineData, matchThreshold float64) MatchedWarLine {
+	result := MatchedWarLine{WarLineData: line, Action: WarLineActionCreate, MatchedName: line.FamilyName}
+
+	matches := matcher.Rank(line.FamilyName, 2)
+	if len(matches) == 0 || matches[0].Confidence < SuggestionThreshold {
+		return result
+	}
+	match := matches[0]
+
+	result.MemberID = match.MemberID
+	result.MatchedName = match.FamilyName
+	result.Confidence = match.Confidence
+
+	// A name scoring the same against two members is left for an officer to pick
+	if match.Confidence >= matchThreshold && !namematch.Ambiguous(matches) {
+		result.Action = WarLineActionLink
+	} else {
+		result.Action = WarLineActionPending
+	}
+
+	return result
+}
+
+// mergeKey identifies the member a line belongs to when merging overlapping screenshots.
+// Linked lines are keyed by member, the rest by their normalized name.
+func mergeKey(line MatchedWarLine) string {
+	if line.Action == WarLineActionLink && line.MemberID != 0 {
+		return fmt.Sprintf("member:%d", line.MemberID)
+	}
+	return "name:" + namematch.Normalize(line.FamilyName)
+}
+
+// MergeWarLines merges the lines of overlapping screenshots of the same war.
+// A member shown on several screenshots with the same kills and deaths is kept once, using
+// the line with the best match. When the screenshots disagree, one line per distinct result
+// is kept, flagged as a conflict and left pending so an officer picks the right one.
+// Lines keep the order in which members first appear.
+func MergeWarLines(lines []MatchedWarLine) []MatchedWarLine {
+	var order []string
+	groups := make(map[string][]MatchedWarLine)
+
+	for _, line := range lines {
+		key := mergeKey(line)
+		group, seen := groups[key]
+		if !seen {
+			order = append(order, key)
+		}
+
+		duplicate := false
+		for idx, existing := range group {
+			if existing.Kills == line.Kills && existing.Deaths == line.Deaths {
+				if line.Confidence > existing.Confidence {
+					group[idx] = line
+				}
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			group = append(group, line)
+		}
+		groups[key] = group
+	}
+
+	merged := make([]MatchedWarLine, 0, len(lines))
+	for _, key := range order {
+		group := groups[key]
+		if len(group) > 1 {
+			for idx := range group {
+				group[idx].Conflict = true
+				if group[idx].Action == WarLineActionLink {
+					group[idx].Action = WarLineActionPending
+				}
+			}
+		}
+		merged = append(merged, group...)
+	}
+
+	return merged
+}
+
+// DuplicateWarLine returns the name of a member that more than one imported line resolves to.
+// Dropped lines are ignored, so officers resolve conflicts by dropping the wrong lines.
+func DuplicateWarLine(lines []MatchedWarLine) (string, bool) {
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		var key string
+		switch line.Action {
+		case WarLineActionLink:
+			key = fmt.Sprintf("member:%d", line.MemberID)
+		case WarLineActionCreate:
+			key = "name:" + strings.ToLower(line.FamilyName)
+		default:
+			continue
+		}
+
+		if seen[key] {
+			return line.MatchedName, true
+		}
+		seen[key] = true
+	}
+
+	return "", false
+}
+
+// CreateWarFromCSV creates a war entry and associated war lines for a war import job
+// and marks the job as done, all in one transaction.
+// Every line must be resolved: linked lines reference their member, "create" lines add
+// the family name to the roster and dropped lines are skipped.
+func CreateWarFromCSV(db *DB, guildID string, jobID int64, warDate time.Time, warResult string, warType string, tier string, label string, warLines []MatchedWarLine) (*WarImportSummary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, line := range warLines {
+		if !line.Resolved() {
+			return nil, fmt.Errorf("war line for '%s' has not been resolved", line.FamilyName)
+		}
+	}
+	if name, found := DuplicateWarLine(warLines); found {
+		return nil, fmt.Errorf("more than one war line resolves to '%s'", name)
 	}
 
-	jobID, err := jobResult.LastInsertId()
+	tx, err := db.BeginTxx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to get job ID: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Create queries with transaction
+	qtx := db.Queries.WithTx(tx.Tx)
 
 	// Prepare the result field
 	var resultField sqlcdb.NullWarsResult
@@ -133,89 +388,201 @@ func CreateWarFromCSV(db *DB, guildID string, requestChannelID string, requestMe
 	warDBResult, err := qtx.CreateWar(ctx, sqlcdb.CreateWarParams{
 		DiscordGuildID: guildID,
 		JobID:          uint64(jobID),
-		WarDate:        warDate,
-		Label:          sql.NullString{String: fmt.Sprintf("CSV Import - %s", warDate.Format("02-01-06")), Valid: true},
+		WarDate:        dateValue(warDate),
+		Label:          sql.NullString{String: label, Valid: label != ""},
 		Result:         resultField,
 		WarType:        warTypeField,
 		Tier:           tierField,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to create war: %w", err)
+		return nil, fmt.Errorf("failed to create war: %w", err)
 	}
 
 	warID, err := warDBResult.LastInsertId()
 	if err != nil {
-		return fmt.Errorf("failed to get war ID: %w", err)
+		return nil, fmt.Errorf("failed to get war ID: %w", err)
 	}
 
+	summary := &WarImportSummary{WarID: warID}
+
 	// Create war_lines entries
 	for _, line := range warLines {
-		// Try to match the family name to a roster member (case insensitive)
-		rosterMemberID, err := qtx.GetRosterMemberByFamilyName(ctx, sqlcdb.GetRosterMemberByFamilyNameParams{
-			DiscordGuildID: guildID,
-			FamilyName:     line.FamilyName,
-		})
+		memberID := line.MemberID
 
-		var memberID sql.NullInt64
-		if err == sql.ErrNoRows {
-			// Roster member doesn't exist - create one
+		switch line.Action {
+		case WarLineActionDrop:
+			summary.Dropped++
+			continue
+
+		case WarLineActionCreate:
+			// Returns the existing member if the name is already on the roster
 			result, err := qtx.CreateRosterMember(ctx, sqlcdb.CreateRosterMemberParams{
 				DiscordGuildID: guildID,
 				FamilyName:     line.FamilyName,
 			})
 			if err != nil {
-				return fmt.Errorf("failed to create roster member for '%s': %w", line.FamilyName, err)
+				return nil, fmt.Errorf("failed to create roster member for '%s': %w", line.FamilyName, err)
 			}
 
-			newID, err := result.LastInsertId()
+			memberID, err = result.LastInsertId()
 			if err != nil {
-				return fmt.Errorf("failed to get new roster member ID for '%s': %w", line.FamilyName, err)
+				return nil, fmt.Errorf("failed to get new roster member ID for '%s': %w", line.FamilyName, err)
+			}
+
+			if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 1 {
+				summary.Created = append(summary.Created, line.FamilyName)
+			} else {
+				summary.Linked++
 			}
 
-			memberID.Int64 = newID
-			memberID.Valid = true
-		} else if err != nil {
-			return fmt.Errorf("failed to lookup roster member for '%s': %w", line.FamilyName, err)
-		} else {
-			memberID.Int64 = int64(rosterMemberID)
-			memberID.Valid = true
+		default:
+			summary.Linked++
 		}
 
 		// Insert war_line
 		err = qtx.CreateWarLine(ctx, sqlcdb.CreateWarLineParams{
-			WarID:          uint64(warID),
-			RosterMemberID: memberID,
-			OcrName:        line.FamilyName,
-			Kills:          int32(line.Kills),
-			Deaths:         int32(line.Deaths),
-			MatchedName:    sql.NullString{String: line.FamilyName, Valid: true},
+			WarID:           uint64(warID),
+			RosterMemberID:  sql.NullInt64{Int64: memberID, Valid: true},
+			OcrName:         line.FamilyName,
+			Kills:           int32(line.Kills),
+			Deaths:          int32(line.Deaths),
+			MatchedName:     sql.NullString{String: line.MatchedName, Valid: line.MatchedName != ""},
+			MatchConfidence: nullConfidence(line.Confidence),
 		})
 		if err != nil {
-			return fmt.Errorf("failed to create war line for '%s': %w", line.FamilyName, err)
+			return nil, fmt.Errorf("failed to create war line for '%s': %w", line.FamilyName, err)
 		}
 	}
 
+	// Mark the job as done together with the war it produced
+	if err := qtx.CompleteWarJob(ctx, uint64(jobID)); err != nil {
+		return nil, fmt.Errorf("failed to complete war job: %w", err)
+	}
+
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return nil
+	return summary, nil
+}
+
+// War identifies a single imported war
+type War struct {
+	ID         int64
+	WarDate    time.Time
+	Label      string // empty when the war has no label
+	Result     string // "win", "lose", or empty
+	WarType    string // "node", "siege", or empty
+	Tier       string // "1", "2", "uncapped", or empty
+	IsExcluded bool
+}
+
+// convertWar converts a wars row to a War
+func convertWar(row sqlcdb.GetWarRow) War {
+	war := War{
+		ID:         int64(row.ID),
+		WarDate:    row.WarDate,
+		Label:      row.Label.String,
+		IsExcluded: row.IsExcluded,
+	}
+	if row.Result.Valid {
+		war.Result = string(row.Result.WarsResult)
+	}
+	if row.WarType.Valid {
+		war.WarType = string(row.WarType.WarsWarType)
+	}
+	if row.Tier.Valid {
+		war.Tier = string(row.Tier.WarsTier)
+	}
+	return war
+}
+
+// GetWar retrieves a war by ID; returns sql.ErrNoRows if the guild has no such war
+func GetWar(db *DB, guildID string, warID int64) (*War, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	row, err := db.Queries.GetWar(ctx, sqlcdb.GetWarParams{
+		DiscordGuildID: guildID,
+		ID:             uint64(warID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	war := convertWar(row)
+	return &war, nil
+}
+
+// GetWarsByDate retrieves every war fought on a date, in import order
+func GetWarsByDate(db *DB, guildID string, warDate time.Time) ([]War, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetWarsByDate(ctx, sqlcdb.GetWarsByDateParams{
+		DiscordGuildID: guildID,
+		WarDate:        dateValue(warDate),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wars := make([]War, 0, len(rows))
+	for _, row := range rows {
+		wars = append(wars, convertWar(sqlcdb.GetWarRow(row)))
+	}
+
+	return wars, nil
+}
+
+// SearchWars finds wars by ID, DD-MM-YY date prefix or label, most recent first.
+// An empty search returns the latest wars.
+func SearchWars(db *DB, guildID string, search string, limit int) ([]War, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.SearchWars(ctx, sqlcdb.SearchWarsParams{
+		DiscordGuildID: guildID,
+		Search:         search,
+		Limit:          int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wars := make([]War, 0, len(rows))
+	for _, row := range rows {
+		wars = append(wars, convertWar(sqlcdb.GetWarRow(row)))
+	}
+
+	return wars, nil
 }
 
 // WarResult represents a single war's aggregated results
 type WarResult struct {
+	ID          int64
 	WarDate     time.Time
+	Label       string
 	Result      string // "win", "lose", or empty
+	IsExcluded  bool
 	TotalKills  int
 	TotalDeaths int
 }
 
-// GetWarResults retrieves all war results for a guild
-func GetWarResults(db *DB, guildID string) ([]WarResult, error) {
+// GetWarResults retrieves the results of the guild's wars matching the filter, including excluded wars
+func GetWarResults(db *DB, guildID string, filter WarFilter) ([]WarResult, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	rows, err := db.Queries.GetWarResults(ctx, guildID)
+	startDate, endDate := filter.dateRange()
+	rows, err := db.Queries.GetWarResults(ctx, sqlcdb.GetWarResultsParams{
+		DiscordGuildID: guildID,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		WarType:        filter.warType(),
+		Tier:           filter.tier(),
+		Result:         filter.result(),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -223,11 +590,14 @@ func GetWarResults(db *DB, guildID string) ([]WarResult, error) {
 	results := make([]WarResult, 0, len(rows))
 	for _, row := range rows {
 		result := WarResult{
+			ID:          int64(row.ID),
 			WarDate:     row.WarDate,
+			Label:       row.Label.String,
+			IsExcluded:  row.IsExcluded,
 			TotalKills:  int(row.TotalKills),
 			TotalDeaths: int(row.TotalDeaths),
 		}
-		
+
 		// Handle the result field (can be NULL)
 		if row.Result.Valid {
 			result.Result = string(row.Result.WarsResult)
@@ -239,14 +609,26 @@ func GetWarResults(db *DB, guildID string) ([]WarResult, error) {
 	return results, nil
 }
 
-// DeleteWarByDate deletes all war data for a specific date
-func DeleteWarByDate(db *DB, guildID string, warDate time.Time) error {
+// SetWarExcluded excludes a war from, or includes it back into, statistics and attendance
+func SetWarExcluded(db *DB, guildID string, warID int64, excluded bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.Queries.SetWarExcluded(ctx, sqlcdb.SetWarExcludedParams{
+		IsExcluded:     excluded,
+		DiscordGuildID: guildID,
+		ID:             uint64(warID),
+	})
+}
+
+// DeleteWar deletes a war and its lines; returns sql.ErrNoRows if the guild has no such war
+func DeleteWar(db *DB, guildID string, warID int64) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	result, err := db.Queries.DeleteWarByDate(ctx, sqlcdb.DeleteWarByDateParams{
+	result, err := db.Queries.DeleteWar(ctx, sqlcdb.DeleteWarParams{
 		DiscordGuildID: guildID,
-		WarDate:        warDate,
+		ID:             uint64(warID),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to delete war: %w", err)
@@ -258,33 +640,35 @@ func DeleteWarByDate(db *DB, guildID string, warDate time.Time) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("no war found for date %s", warDate.Format("02-01-06"))
+		return sql.ErrNoRows
 	}
 
 	return nil
 }
 
-// WarStatByDate represents war statistics for a member on a specific date
-type WarStatByDate struct {
-	FamilyName string
-	Kills      int
-	Deaths     int
+// WarMemberStat represents a member's kills and deaths in a single war
+type WarMemberStat struct {
+	FamilyName  string
+	Kills       int
+	Deaths      int
+	IsException bool // the member's K/D is left out of guild totals
+	IsExcluded  bool // the war falls within one of the member's stats exclusions
 }
 
-// GetWarStatsByDate retrieves war statistics for a specific date
-func GetWarStatsByDate(db *DB, guildID string, warDate time.Time) ([]WarStatByDate, error) {
+// GetWarStatsByWar retrieves member statistics for a single war
+func GetWarStatsByWar(db *DB, guildID string, warID int64) ([]WarMemberStat, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	rows, err := db.Queries.GetWarStatsByDate(ctx, sqlcdb.GetWarStatsByDateParams{
+	rows, err := db.Queries.GetWarStatsByWar(ctx, sqlcdb.GetWarStatsByWarParams{
 		DiscordGuildID: guildID,
-		WarDate:        warDate,
+		ID:             uint64(warID),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	stats := make([]WarStatByDate, 0, len(rows))
+	stats := make([]WarMemberStat, 0, len(rows))
 	for _, row := range rows {
 		// Handle NullString for FamilyName
 		familyName := ""
@@ -292,10 +676,12 @@ func GetWarStatsByDate(db *DB, guildID string, warDate time.Time) ([]WarStatByDa
 			familyName = row.FamilyName.String
 		}
 
-		stat := WarStatByDate{
-			FamilyName: familyName,
-			Kills:      int(row.Kills),
-			Deaths:     int(row.Deaths),
+		stat := WarMemberStat{
+			FamilyName:  familyName,
+			Kills:       int(row.Kills),
+			Deaths:      int(row.Deaths),
+			IsException: row.IsException.Bool,
+			IsExcluded:  row.IsExcluded,
 		}
 		stats = append(stats, stat)
 	}
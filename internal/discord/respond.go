@@ -1,6 +1,10 @@
 package discord
 
-import "github.com/bwmarrin/discordgo"
+import (
+	"bytes"
+
+	"github.com/bwmarrin/discordgo"
+)
 
 func RespondText(s *discordgo.Session, i *discordgo.InteractionCreate, msg string) {
 	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -28,6 +32,16 @@ func DeferResponse(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	})
 }
 
+// DeferEphemeral defers the response like DeferResponse, showing the loading message to the user only
+func DeferEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
 // FollowUpText sends a follow-up message after a deferred response
 func FollowUpText(s *discordgo.Session, i *discordgo.InteractionCreate, msg string) error {
 	_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
@@ -44,3 +58,27 @@ func FollowUpEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, msg
 	})
 	return err
 }
+
+// RespondAutocomplete answers an autocomplete interaction with the given choices
+func RespondAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, choices []*discordgo.ApplicationCommandOptionChoice) {
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+}
+
+// FollowUpFile sends an ephemeral follow-up message with a file attached after a deferred response
+func FollowUpFile(s *discordgo.Session, i *discordgo.InteractionCreate, msg, name, contentType string, data []byte) error {
+	_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: msg,
+		Files: []*discordgo.File{{
+			Name:        name,
+			ContentType: contentType,
+			Reader:      bytes.NewReader(data),
+		}},
+		Flags: discordgo.MessageFlagsEphemeral,
+	})
+	return err
+}
@@ -0,0 +1,111 @@
+package extract
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"PanickedBot/internal/db"
+)
+
+// CleanCSVContent removes markdown code blocks and blank lines from CSV content
+func CleanCSVContent(content string) string {
+	lines := strings.Split(content, "\n")
+	var cleaned []string
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		// Skip blank lines
+		if trimmed == "" {
+			continue
+		}
+
+		// Skip markdown code block markers
+		if trimmed == "```" || trimmed == "```csv" || strings.HasPrefix(trimmed, "```") {
+			continue
+		}
+
+		cleaned = append(cleaned, trimmed)
+	}
+
+	return strings.Join(cleaned, "\n")
+}
+
+// ParseWarCSV parses a CSV file with war data, reading the date in loc
+// First line: date in DD-MM-YY format
+// Remaining lines: family_name, kills, deaths
+func ParseWarCSV(content io.Reader, loc *time.Location) (warDate time.Time, warLines []db.WarLineData, err error) {
+	reader := csv.NewReader(content)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1 // Allow variable number of fields per record
+
+	// Read first line (date)
+	dateRecord, err := reader.Read()
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("failed to read date line: %w", err)
+	}
+
+	if len(dateRecord) == 0 {
+		return time.Time{}, nil, fmt.Errorf("date line is empty")
+	}
+
+	warDate, err = time.ParseInLocation("02-01-06", strings.TrimSpace(dateRecord[0]), loc)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("invalid date format (expected DD-MM-YY): %w", err)
+	}
+
+	// Read remaining lines (war data)
+	lineNum := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return time.Time{}, nil, fmt.Errorf("failed to read line %d: %w", lineNum+1, err)
+		}
+
+		lineNum++
+
+		if len(record) < 3 {
+			return time.Time{}, nil, fmt.Errorf("line %d: expected 3 fields (family_name, kills, deaths), got %d", lineNum, len(record))
+		}
+
+		familyName := strings.TrimSpace(record[0])
+		if familyName == "" {
+			return time.Time{}, nil, fmt.Errorf("line %d: family_name cannot be empty", lineNum)
+		}
+
+		kills, err := strconv.Atoi(strings.TrimSpace(record[1]))
+		if err != nil {
+			return time.Time{}, nil, fmt.Errorf("line %d: invalid kills value '%s': %w", lineNum, record[1], err)
+		}
+		if kills < 0 {
+			return time.Time{}, nil, fmt.Errorf("line %d: kills cannot be negative (got %d)", lineNum, kills)
+		}
+
+		deaths, err := strconv.Atoi(strings.TrimSpace(record[2]))
+		if err != nil {
+			return time.Time{}, nil, fmt.Errorf("line %d: invalid deaths value '%s': %w", lineNum, record[2], err)
+		}
+		if deaths < 0 {
+			return time.Time{}, nil, fmt.Errorf("line %d: deaths cannot be negative (got %d)", lineNum, deaths)
+		}
+
+		warLines = append(warLines, db.WarLineData{
+			FamilyName: familyName,
+			Kills:      kills,
+			Deaths:     deaths,
+		})
+	}
+
+	if len(warLines) == 0 {
+		return time.Time{}, nil, fmt.Errorf("no war data found in CSV")
+	}
+
+	return warDate, warLines, nil
+}
@@ -1,8 +1,9 @@
-package commands
+package extract
 
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestCleanCSVContent(t *testing.T) {
@@ -81,9 +82,9 @@ FamilyName2,15,8`,
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := cleanCSVContent(tt.input)
+			result := CleanCSVContent(tt.input)
 			if result != tt.expected {
-				t.Errorf("cleanCSVContent() = %q, want %q", result, tt.expected)
+				t.Errorf("CleanCSVContent() = %q, want %q", result, tt.expected)
 			}
 		})
 	}
@@ -135,26 +136,26 @@ FamilyName2,15,8`,
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			warDate, warLines, err := parseWarCSV(strings.NewReader(tt.input))
+			warDate, warLines, err := ParseWarCSV(strings.NewReader(tt.input), time.UTC)
 			
 			if tt.expectError {
 				if err == nil {
-					t.Errorf("parseWarCSV() expected error but got none")
+					t.Errorf("ParseWarCSV() expected error but got none")
 				}
 				return
 			}
 
 			if err != nil {
-				t.Errorf("parseWarCSV() unexpected error: %v", err)
+				t.Errorf("ParseWarCSV() unexpected error: %v", err)
 				return
 			}
 
 			if warDate.Format("02-01-06") != tt.expectedDate {
-				t.Errorf("parseWarCSV() date = %v, want %v", warDate.Format("02-01-06"), tt.expectedDate)
+				t.Errorf("ParseWarCSV() date = %v, want %v", warDate.Format("02-01-06"), tt.expectedDate)
 			}
 
 			if len(warLines) != tt.expectedLines {
-				t.Errorf("parseWarCSV() lines = %d, want %d", len(warLines), tt.expectedLines)
+				t.Errorf("ParseWarCSV() lines = %d, want %d", len(warLines), tt.expectedLines)
 			}
 		})
 	}
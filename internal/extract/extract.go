@@ -0,0 +1,114 @@
+// Package extract reads war results out of scoreboard screenshots
+// and gear stats out of character stat window screenshots.
+package extract
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"PanickedBot/internal/db"
+)
+
+// Extractor reads the war date and per-member kills and deaths from a screenshot
+type Extractor interface {
+	Extract(ctx context.Context, imageData []byte, mimeType string) (warDate time.Time, warLines []db.WarLineData, err error)
+}
+
+// Supported backends
+const (
+	BackendOpenAI           = "openai"
+	BackendOpenAICompatible = "openai_compatible" // any endpoint speaking the OpenAI chat completions API
+)
+
+// DefaultOpenAIModel is used when a guild has not chosen a model for the OpenAI backend
+const DefaultOpenAIModel = "gpt-4o"
+
+// Config selects and configures an extraction backend
+type Config struct {
+	Backend  string // BackendOpenAI when empty
+	Model    string // DefaultOpenAIModel when empty on the OpenAI backend
+	BaseURL  string // required for BackendOpenAICompatible
+	Location *time.Location
+}
+
+// Credentials holds the API keys available to extraction backends
+type Credentials struct {
+	OpenAIAPIKey     string
+	CompatibleAPIKey string // optional, many local endpoints need no key
+}
+
+// New creates the extractor described by cfg
+func New(cfg Config, creds Credentials) (Extractor, error) {
+	backend, err := newBackend(cfg, creds)
+	if err != nil {
+		return nil, err
+	}
+	return backend, nil
+}
+
+// NewGearExtractor creates the gear stats extractor described by cfg
+func NewGearExtractor(cfg Config, creds Credentials) (GearExtractor, error) {
+	backend, err := newBackend(cfg, creds)
+	if err != nil {
+		return nil, err
+	}
+	return backend, nil
+}
+
+// newBackend creates the vision model client described by cfg
+func newBackend(cfg Config, creds Credentials) (*OpenAI, error) {
+	if cfg.Location == nil {
+		return nil, fmt.Errorf("extractor location is not set")
+	}
+
+	switch cfg.Backend {
+	case "", BackendOpenAI:
+		if creds.OpenAIAPIKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY environment variable not set")
+		}
+		model := cfg.Model
+		if model == "" {
+			model = DefaultOpenAIModel
+		}
+		return NewOpenAI(creds.OpenAIAPIKey, model, cfg.Location), nil
+
+	case BackendOpenAICompatible:
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("no base URL configured for the OpenAI-compatible backend")
+		}
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("no model configured for the OpenAI-compatible backend")
+		}
+		return NewOpenAICompatible(cfg.BaseURL, creds.CompatibleAPIKey, cfg.Model, cfg.Location), nil
+	}
+
+	return nil, fmt.Errorf("unknown extraction backend '%s'", cfg.Backend)
+}
+
+// ModerationError is returned when a screenshot is flagged by content moderation
+type ModerationError struct {
+	Categories []string
+}
+
+func (e *ModerationError) Error() string {
+	return "image flagged by moderation: " + strings.Join(e.Categories, ", ")
+}
+
+// warScreenshotPrompt asks a vision model to transcribe a scoreboard screenshot as CSV
+const warScreenshotPrompt = "Extract the war statistics from this screenshot and return them in CSV format.\n\n" +
+	"The screenshot contains war data with the following information:\n" +
+	"- The date of the war is at the top of the screenshot in DD-MM-YY format (e.g., 20-03-25 for March 20, 2025)\n" +
+	"- The leftmost column contains family names\n" +
+	"- The last two columns (rightmost) contain kills and deaths\n" +
+	"- All other columns should be ignored\n\n" +
+	"IMPORTANT: The date in the screenshot is in DD-MM-YY format. You MUST return the date in the EXACT SAME DD-MM-YY format as shown in the screenshot. Do NOT convert it to any other format.\n\n" +
+	"Please return the data in this exact CSV format:\n" +
+	"First line: date in DD-MM-YY format (exactly as shown in the screenshot)\n" +
+	"Following lines: family_name,kills,deaths\n\n" +
+	"Example output:\n" +
+	"20-03-25\n" +
+	"FamilyName1,10,5\n" +
+	"FamilyName2,15,8\n\n" +
+	"CRITICAL: Return ONLY the CSV data with NO markdown formatting, NO code blocks (```), NO explanatory text, and NO additional formatting. Just the raw CSV data."
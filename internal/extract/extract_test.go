@@ -0,0 +1,128 @@
+package extract
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"PanickedBot/internal/db"
+)
+
+func TestNew(t *testing.T) {
+	creds := Credentials{OpenAIAPIKey: "sk-test"}
+
+	tests := []struct {
+		name        string
+		cfg         Config
+		creds       Credentials
+		expectError bool
+	}{
+		{
+			name:  "default backend",
+			cfg:   Config{Location: time.UTC},
+			creds: creds,
+		},
+		{
+			name:  "openai with model",
+			cfg:   Config{Backend: BackendOpenAI, Model: "gpt-4o-mini", Location: time.UTC},
+			creds: creds,
+		},
+		{
+			name:        "openai without key",
+			cfg:         Config{Backend: BackendOpenAI, Location: time.UTC},
+			expectError: true,
+		},
+		{
+			name: "compatible without key",
+			cfg:  Config{Backend: BackendOpenAICompatible, Model: "llava", BaseURL: "http://localhost:11434/v1", Location: time.UTC},
+		},
+		{
+			name:        "compatible without base URL",
+			cfg:         Config{Backend: BackendOpenAICompatible, Model: "llava", Location: time.UTC},
+			expectError: true,
+		},
+		{
+			name:        "compatible without model",
+			cfg:         Config{Backend: BackendOpenAICompatible, BaseURL: "http://localhost:11434/v1", Location: time.UTC},
+			expectError: true,
+		},
+		{
+			name:        "unknown backend",
+			cfg:         Config{Backend: "tesseract", Location: time.UTC},
+			creds:       creds,
+			expectError: true,
+		},
+		{
+			name:        "missing location",
+			cfg:         Config{Backend: BackendOpenAI},
+			creds:       creds,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extractor, err := New(tt.cfg, tt.creds)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("New() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+			if extractor == nil {
+				t.Fatal("New() returned nil extractor")
+			}
+		})
+	}
+}
+
+func TestModerationError(t *testing.T) {
+	var err error = &ModerationError{Categories: []string{"hate", "violence"}}
+
+	var moderationErr *ModerationError
+	if !errors.As(err, &moderationErr) {
+		t.Fatal("errors.As should find the ModerationError")
+	}
+
+	expected := "image flagged by moderation: hate, violence"
+	if err.Error() != expected {
+		t.Errorf("Error() = %q, want %q", err.Error(), expected)
+	}
+}
+
+func TestFake(t *testing.T) {
+	date := time.Date(2026, 1, 29, 0, 0, 0, 0, time.UTC)
+	fake := &Fake{
+		Date:  date,
+		Lines: []db.WarLineData{{FamilyName: "Hammity", Kills: 10, Deaths: 5}},
+	}
+
+	var extractor Extractor = fake
+	warDate, warLines, err := extractor.Extract(context.Background(), []byte("image"), "image/png")
+	if err != nil {
+		t.Fatalf("Extract() unexpected error: %v", err)
+	}
+	if !warDate.Equal(date) || len(warLines) != 1 || warLines[0].FamilyName != "Hammity" {
+		t.Errorf("Extract() = %v, %+v", warDate, warLines)
+	}
+
+	// Callers may modify the returned lines without affecting later calls
+	warLines[0].Kills = 99
+	_, warLines, _ = extractor.Extract(context.Background(), nil, "")
+	if warLines[0].Kills != 10 {
+		t.Errorf("Extract() returned shared lines")
+	}
+
+	if fake.Calls != 2 {
+		t.Errorf("Calls = %d, want 2", fake.Calls)
+	}
+
+	fake.Err = errors.New("boom")
+	if _, _, err := extractor.Extract(context.Background(), nil, ""); err == nil {
+		t.Error("Extract() expected configured error")
+	}
+}
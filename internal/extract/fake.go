@@ -0,0 +1,45 @@
+package extract
+
+import (
+	"context"
+	"time"
+
+	"PanickedBot/internal/db"
+)
+
+// Fake is a deterministic extractor for tests.
+// It returns its configured date and lines, or gear stats, (or error) regardless of the image.
+type Fake struct {
+	Date  time.Time
+	Lines []db.WarLineData
+	Gear  GearStats
+	Err   error
+
+	Calls int // number of Extract and ExtractGear calls
+}
+
+// Extract returns a copy of the configured lines
+func (f *Fake) Extract(ctx context.Context, imageData []byte, mimeType string) (time.Time, []db.WarLineData, error) {
+	f.Calls++
+
+	if f.Err != nil {
+		return time.Time{}, nil, f.Err
+	}
+
+	lines := make([]db.WarLineData, len(f.Lines))
+	copy(lines, f.Lines)
+
+	return f.Date, lines, nil
+}
+
+// ExtractGear returns a copy of the configured gear stats
+func (f *Fake) ExtractGear(ctx context.Context, imageData []byte, mimeType string) (*GearStats, error) {
+	f.Calls++
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	stats := f.Gear
+	return &stats, nil
+}
@@ -0,0 +1,100 @@
+package extract
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GearExtractor reads a character's gear stats from a screenshot of the in-game stat window
+type GearExtractor interface {
+	ExtractGear(ctx context.Context, imageData []byte, mimeType string) (*GearStats, error)
+}
+
+// GearStats holds the stats read from a stat window; stats that could not be read are nil
+type GearStats struct {
+	AP       *int
+	AAP      *int
+	DP       *int
+	Evasion  *int
+	DR       *int
+	DRR      *float64 // percentage
+	Accuracy *int
+	HP       *int
+	TotalAP  *int
+	TotalAAP *int
+}
+
+// gearScreenshotPrompt asks a vision model to transcribe the stat window as one "label: value" line per stat
+const gearScreenshotPrompt = "Extract the character stats from this Black Desert Online screenshot of the character stat window.\n\n" +
+	"Return one line per stat in the format \"label: value\", using exactly these labels:\n" +
+	"AP, AAP, DP, Evasion, DR, DRR, Accuracy, HP, Total AP, Total AAP\n\n" +
+	"- AP is the Attack Power and AAP the Awakening Attack Power shown next to it\n" +
+	"- DP is the Defense Power\n" +
+	"- DRR is the Damage Reduction Rate percentage, return the number without the % sign\n" +
+	"- Total AP and Total AAP include the bonus AP against monsters and players when the window shows them\n" +
+	"- Leave out any stat that is not visible in the screenshot. Do NOT guess values.\n\n" +
+	"Example output:\n" +
+	"AP: 310\n" +
+	"AAP: 312\n" +
+	"DP: 420\n" +
+	"DRR: 25.5\n\n" +
+	"CRITICAL: Return ONLY the stat lines with NO markdown formatting, NO code blocks (```), NO explanatory text, and NO additional formatting."
+
+// ParseGearStats parses the "label: value" lines returned for a stat window screenshot.
+// Unknown labels and unreadable values are ignored; AP, AAP and DP are required.
+func ParseGearStats(content string) (*GearStats, error) {
+	var stats GearStats
+
+	for _, line := range strings.Split(CleanCSVContent(content), "\n") {
+		label, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		label = strings.ToLower(strings.Join(strings.Fields(label), " "))
+		value = strings.TrimSpace(strings.NewReplacer("%", "", ",", "").Replace(value))
+
+		if label == "drr" {
+			if drr, err := strconv.ParseFloat(value, 64); err == nil {
+				stats.DRR = &drr
+			}
+			continue
+		}
+
+		var target **int
+		switch label {
+		case "ap":
+			target = &stats.AP
+		case "aap":
+			target = &stats.AAP
+		case "dp":
+			target = &stats.DP
+		case "evasion":
+			target = &stats.Evasion
+		case "dr":
+			target = &stats.DR
+		case "accuracy":
+			target = &stats.Accuracy
+		case "hp":
+			target = &stats.HP
+		case "total ap":
+			target = &stats.TotalAP
+		case "total aap":
+			target = &stats.TotalAAP
+		default:
+			continue
+		}
+
+		if number, err := strconv.Atoi(value); err == nil {
+			*target = &number
+		}
+	}
+
+	if stats.AP == nil || stats.AAP == nil || stats.DP == nil {
+		return nil, fmt.Errorf("could not read AP, AAP and DP from the screenshot")
+	}
+
+	return &stats, nil
+}
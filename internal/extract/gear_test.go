@@ -0,0 +1,89 @@
+package extract
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseGearStats(t *testing.T) {
+	t.Run("all stats", func(t *testing.T) {
+		content := "```\nAP: 310\nAAP: 312\nDP: 420\nEvasion: 1,050\nDR: 900\nDRR: 25.5%\nAccuracy: 1000\nHP: 5600\nTotal AP: 870\nTotal  AAP: 880\n```"
+
+		stats, err := ParseGearStats(content)
+		if err != nil {
+			t.Fatalf("ParseGearStats() unexpected error: %v", err)
+		}
+
+		ints := []struct {
+			name     string
+			value    *int
+			expected int
+		}{
+			{"AP", stats.AP, 310},
+			{"AAP", stats.AAP, 312},
+			{"DP", stats.DP, 420},
+			{"Evasion", stats.Evasion, 1050},
+			{"DR", stats.DR, 900},
+			{"Accuracy", stats.Accuracy, 1000},
+			{"HP", stats.HP, 5600},
+			{"Total AP", stats.TotalAP, 870},
+			{"Total AAP", stats.TotalAAP, 880},
+		}
+		for _, stat := range ints {
+			if stat.value == nil || *stat.value != stat.expected {
+				t.Errorf("%s = %v, want %d", stat.name, stat.value, stat.expected)
+			}
+		}
+		if stats.DRR == nil || *stats.DRR != 25.5 {
+			t.Errorf("DRR = %v, want 25.5", stats.DRR)
+		}
+	})
+
+	t.Run("partial stats", func(t *testing.T) {
+		stats, err := ParseGearStats("ap: 300\nAAP: 305\nDP: 410\nEvasion: unknown\nSpeed: 5")
+		if err != nil {
+			t.Fatalf("ParseGearStats() unexpected error: %v", err)
+		}
+		if stats.Evasion != nil || stats.DRR != nil || stats.HP != nil {
+			t.Errorf("expected unreadable and missing stats to be nil, got %+v", stats)
+		}
+	})
+
+	t.Run("missing DP", func(t *testing.T) {
+		if _, err := ParseGearStats("AP: 300\nAAP: 305"); err == nil {
+			t.Error("ParseGearStats() expected error without DP")
+		}
+	})
+}
+
+func TestNewGearExtractor(t *testing.T) {
+	extractor, err := NewGearExtractor(Config{Location: time.UTC}, Credentials{OpenAIAPIKey: "sk-test"})
+	if err != nil || extractor == nil {
+		t.Fatalf("NewGearExtractor() = %v, %v", extractor, err)
+	}
+
+	if _, err := NewGearExtractor(Config{Backend: "tesseract", Location: time.UTC}, Credentials{}); err == nil {
+		t.Error("NewGearExtractor() expected error for unknown backend")
+	}
+}
+
+func TestFakeGear(t *testing.T) {
+	ap, aap, dp := 310, 312, 420
+	fake := &Fake{Gear: GearStats{AP: &ap, AAP: &aap, DP: &dp}}
+
+	var extractor GearExtractor = fake
+	stats, err := extractor.ExtractGear(context.Background(), []byte("image"), "image/png")
+	if err != nil {
+		t.Fatalf("ExtractGear() unexpected error: %v", err)
+	}
+	if stats.AP == nil || *stats.AP != 310 {
+		t.Errorf("ExtractGear() = %+v", stats)
+	}
+
+	fake.Err = errors.New("boom")
+	if _, err := extractor.ExtractGear(context.Background(), nil, ""); err == nil {
+		t.Error("ExtractGear() expected configured error")
+	}
+}
@@ -0,0 +1,181 @@
+package extract
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+
+	"PanickedBot/internal/db"
+)
+
+// OpenAI extracts war data and gear stats with a vision model behind the OpenAI chat completions API
+type OpenAI struct {
+	client   openai.Client
+	model    string
+	moderate bool // run OpenAI content moderation before extraction
+	loc      *time.Location
+}
+
+// NewOpenAI creates an extractor using the OpenAI API, with content moderation
+func NewOpenAI(apiKey, model string, loc *time.Location) *OpenAI {
+	return &OpenAI{
+		client:   openai.NewClient(option.WithAPIKey(apiKey)),
+		model:    model,
+		moderate: true,
+		loc:      loc,
+	}
+}
+
+// NewOpenAICompatible creates an extractor for an OpenAI-compatible endpoint such as a local vision model.
+// These endpoints generally do not offer moderation, so none is performed.
+func NewOpenAICompatible(baseURL, apiKey, model string, loc *time.Location) *OpenAI {
+	opts := []option.RequestOption{option.WithBaseURL(baseURL)}
+	if apiKey != "" {
+		opts = append(opts, option.WithAPIKey(apiKey))
+	}
+
+	return &OpenAI{
+		client: openai.NewClient(opts...),
+		model:  model,
+		loc:    loc,
+	}
+}
+
+// Extract sends the screenshot to the model and parses the CSV it returns
+func (e *OpenAI) Extract(ctx context.Context, imageData []byte, mimeType string) (time.Time, []db.WarLineData, error) {
+	csvContent, err := e.complete(ctx, warScreenshotPrompt, imageData, mimeType)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+
+	// Validate that we got some content back
+	if strings.TrimSpace(csvContent) == "" {
+		return time.Time{}, nil, fmt.Errorf("vision model returned empty response - unable to extract war data from image")
+	}
+
+	// Clean the CSV content to remove markdown formatting and blank lines
+	cleanedContent := CleanCSVContent(csvContent)
+
+	if strings.TrimSpace(cleanedContent) == "" {
+		return time.Time{}, nil, fmt.Errorf("vision model response contained only formatting - no actual CSV data found")
+	}
+
+	// Parse the cleaned CSV content returned by the model
+	return ParseWarCSV(strings.NewReader(cleanedContent), e.loc)
+}
+
+// ExtractGear sends the stat window screenshot to the model and parses the stats it returns
+func (e *OpenAI) ExtractGear(ctx context.Context, imageData []byte, mimeType string) (*GearStats, error) {
+	content, err := e.complete(ctx, gearScreenshotPrompt, imageData, mimeType)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return nil, fmt.Errorf("vision model returned empty response - unable to extract gear stats from image")
+	}
+
+	return ParseGearStats(content)
+}
+
+// complete checks the image with content moderation, when enabled, and returns the model's answer to prompt
+func (e *OpenAI) complete(ctx context.Context, prompt string, imageData []byte, mimeType string) (string, error) {
+	// Encode image as base64
+	imageBase64 := fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(imageData))
+
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	// First, check if the image passes moderation
+	if e.moderate {
+		if err := e.checkModeration(ctx, imageBase64); err != nil {
+			return "", err
+		}
+	}
+
+	// Now extract the data from the image using vision API
+	chatCompletion, err := e.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			{
+				OfUser: &openai.ChatCompletionUserMessageParam{
+					Content: openai.ChatCompletionUserMessageParamContentUnion{
+						OfArrayOfContentParts: []openai.ChatCompletionContentPartUnionParam{
+							openai.TextContentPart(prompt),
+							openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+								URL:    imageBase64,
+								Detail: "high", // Use high quality for best OCR accuracy
+							}),
+						},
+					},
+				},
+			},
+		},
+		Model:     e.model,
+		MaxTokens: openai.Int(1000),
+	})
+	if err != nil {
+		return "", fmt.Errorf("vision API error: %w", err)
+	}
+
+	if len(chatCompletion.Choices) == 0 {
+		return "", fmt.Errorf("no response from vision API")
+	}
+
+	return chatCompletion.Choices[0].Message.Content, nil
+}
+
+// checkModeration returns a ModerationError if the image is flagged as unsafe
+func (e *OpenAI) checkModeration(ctx context.Context, imageBase64 string) error {
+	moderationResp, err := e.client.Moderations.New(ctx, openai.ModerationNewParams{
+		Model: openai.ModerationModelOmniModerationLatest,
+		Input: openai.ModerationNewParamsInputUnion{
+			OfModerationMultiModalArray: []openai.ModerationMultiModalInputUnionParam{
+				openai.ModerationMultiModalInputParamOfImageURL(openai.ModerationImageURLInputImageURLParam{
+					URL: imageBase64,
+				}),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("moderation API error: %w", err)
+	}
+
+	if len(moderationResp.Results) == 0 || !moderationResp.Results[0].Flagged {
+		return nil
+	}
+
+	// Collect the categories that were flagged
+	categories := moderationResp.Results[0].Categories
+	flags := []struct {
+		flagged bool
+		name    string
+	}{
+		{categories.Harassment, "harassment"},
+		{categories.HarassmentThreatening, "harassment/threatening"},
+		{categories.Hate, "hate"},
+		{categories.HateThreatening, "hate/threatening"},
+		{categories.Illicit, "illicit"},
+		{categories.IllicitViolent, "illicit/violent"},
+		{categories.SelfHarm, "self-harm"},
+		{categories.SelfHarmInstructions, "self-harm/instructions"},
+		{categories.SelfHarmIntent, "self-harm/intent"},
+		{categories.Sexual, "sexual"},
+		{categories.SexualMinors, "sexual/minors"},
+		{categories.Violence, "violence"},
+		{categories.ViolenceGraphic, "violence/graphic"},
+	}
+
+	var flaggedCategories []string
+	for _, flag := range flags {
+		if flag.flagged {
+			flaggedCategories = append(flaggedCategories, flag.name)
+		}
+	}
+
+	return &ModerationError{Categories: flaggedCategories}
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"fmt"
 	"time"
 
 	"PanickedBot/internal/db"
@@ -28,8 +29,12 @@ type Member struct {
 	TotalAP  *int
 	TotalAAP *int
 
+	// When AP/AAP/DP were last confirmed from a stat window screenshot, nil when entered by hand
+	GearVerifiedAt *time.Time
+
 	// Status flags
 	MeetsCap    bool
+	CapOverride *bool // officer override of MeetsCap, nil when computed from the stat caps
 	IsException bool
 	IsMercenary bool
 	IsActive    bool
@@ -76,11 +81,20 @@ func GetMemberByFamilyNameIncludingInactive(database *db.DB, guildID, familyName
 }
 
 // UpdateMember updates member fields
+// Gear changes re-evaluate meets_cap against the guild's stat caps
 func UpdateMember(database *db.DB, memberID int64, fields UpdateFields) error {
-	return db.UpdateMember(database, memberID, fields)
+	if err := db.UpdateMember(database, memberID, fields); err != nil {
+		return err
+	}
+
+	if fields.AP != nil || fields.AAP != nil || fields.DP != nil {
+		return RefreshMemberMeetsCap(database, memberID)
+	}
+	return nil
 }
 
 // CreateMember creates a new roster member
+// Returns db.ErrAliasTaken if the family name is an alias of another member
 func CreateMember(database *db.DB, guildID, discordUserID, familyName string) (int64, error) {
 	return db.CreateMember(database, guildID, discordUserID, familyName)
 }
@@ -95,6 +109,11 @@ func SetMemberMercenary(database *db.DB, memberID int64, mercenary bool) error {
 	return db.SetMemberMercenary(database, memberID, mercenary)
 }
 
+// SetMemberException sets the is_exception flag for a member
+func SetMemberException(database *db.DB, memberID int64, exception bool) error {
+	return db.SetMemberException(database, memberID, exception)
+}
+
 // GetAllRosterMembers retrieves all active roster members for a guild, excluding mercenaries
 func GetAllRosterMembers(database *db.DB, guildID string) ([]Member, error) {
 	members, err := db.GetAllActiveMembers(database, guildID)
@@ -110,11 +129,55 @@ func GetAllRosterMembers(database *db.DB, guildID string) ([]Member, error) {
 	return result, nil
 }
 
+// RosterFilter limits the members returned by GetRosterMembers
+type RosterFilter = db.RosterFilter
+
+// GetRosterMembers retrieves the roster members matching the filter, ordered by family name
+func GetRosterMembers(database *db.DB, guildID string, filter RosterFilter) ([]Member, error) {
+	members, err := db.GetRosterMembers(database, guildID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Member, len(members))
+	for i, m := range members {
+		result[i] = *convertFromDBMember(&m)
+	}
+
+	return result, nil
+}
+
 // AssignMemberToTeams assigns a member to multiple teams (replaces all existing team assignments)
 func AssignMemberToTeams(database *db.DB, memberID int64, teamIDs []int64) error {
 	return db.AssignMemberToTeams(database, memberID, teamIDs)
 }
 
+// RosterImportMember is one member of a roster import
+type RosterImportMember = db.RosterImportMember
+
+// RosterImportResult is the outcome of importing one member
+type RosterImportResult = db.RosterImportResult
+
+// ImportRosterMembers creates or updates roster members in a single transaction
+// Members whose gear was imported have meets_cap re-evaluated against the guild's stat caps
+func ImportRosterMembers(database *db.DB, guildID string, members []RosterImportMember, updatedByUserID string) ([]RosterImportResult, error) {
+	results, err := db.ImportRosterMembers(database, guildID, members, updatedByUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, m := range members {
+		if results[idx].MemberID == 0 || (m.AP == nil && m.AAP == nil && m.DP == nil) {
+			continue
+		}
+		if err := RefreshMemberMeetsCap(database, results[idx].MemberID); err != nil {
+			return results, fmt.Errorf("failed to refresh meets_cap of %s: %w", m.FamilyName, err)
+		}
+	}
+
+	return results, nil
+}
+
 // GetMemberTeamIDs retrieves all team IDs for a member
 func GetMemberTeamIDs(database *db.DB, memberID int64) ([]int64, error) {
 	return db.GetMemberTeamIDs(database, memberID)
@@ -190,6 +253,16 @@ func convertFromDBMember(m *db.Member) *Member {
 		drr = &m.DRR.Float64
 	}
 
+	var gearVerifiedAt *time.Time
+	if m.GearVerifiedAt.Valid {
+		gearVerifiedAt = &m.GearVerifiedAt.Time
+	}
+
+	var capOverride *bool
+	if m.CapOverride.Valid {
+		capOverride = &m.CapOverride.Bool
+	}
+
 	return &Member{
 		ID:             m.ID,
 		DiscordGuildID: m.DiscordGuildID,
@@ -208,7 +281,9 @@ func convertFromDBMember(m *db.Member) *Member {
 		HP:             hp,
 		TotalAP:        totalAP,
 		TotalAAP:       totalAAP,
+		GearVerifiedAt: gearVerifiedAt,
 		MeetsCap:       m.MeetsCap,
+		CapOverride:    capOverride,
 		IsException:    m.IsException,
 		IsMercenary:    m.IsMercenary,
 		IsActive:       m.IsActive,
@@ -0,0 +1,197 @@
+// Package namematch scores OCR'd family names against a guild roster.
+package namematch
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Candidate is a name that resolves to a roster member.
+// Name is the family name itself or a known alias of it.
+type Candidate struct {
+	MemberID   int64
+	FamilyName string
+	Name       string
+}
+
+// FoldedMatchConfidence is the confidence of names that only match once OCR-confusable
+// characters are folded, e.g. "Bo0m" and "Boom". It stays below an exact match so a roster
+// name read exactly wins over a different member whose name folds to the same form.
+const FoldedMatchConfidence = 0.99
+
+// Match is the best roster match found for a name
+type Match struct {
+	MemberID   int64
+	FamilyName string
+	Confidence float64 // 0.0 (no similarity) to 1.0 (exact match)
+}
+
+// Matcher finds the closest roster member for a name
+type Matcher struct {
+	candidates []Candidate
+	exact      []string // lowercased names without whitespace
+	keys       []string // exact names with OCR-confusable characters folded
+}
+
+// New creates a matcher over the given candidates
+func New(candidates []Candidate) *Matcher {
+	m := &Matcher{}
+	for _, c := range candidates {
+		m.Add(c)
+	}
+	return m
+}
+
+// Add registers another candidate, e.g. a member created during an import
+func (m *Matcher) Add(c Candidate) {
+	if c.Name == "" {
+		c.Name = c.FamilyName
+	}
+	m.candidates = append(m.candidates, c)
+	m.exact = append(m.exact, simplify(c.Name))
+	m.keys = append(m.keys, Normalize(c.Name))
+}
+
+// Best returns the closest candidate for name.
+// Returns false if the matcher has no candidates.
+func (m *Matcher) Best(name string) (Match, bool) {
+	matches := m.Rank(name, 1)
+	if len(matches) == 0 {
+		return Match{}, false
+	}
+	return matches[0], true
+}
+
+// Rank returns up to limit distinct members ordered by descending confidence.
+// Each member appears once, scored by its best matching name or alias.
+func (m *Matcher) Rank(name string, limit int) []Match {
+	exact, key := simplify(name), Normalize(name)
+
+	best := make(map[int64]Match)
+	order := []int64{}
+	for idx, c := range m.candidates {
+		confidence := score(exact, key, m.exact[idx], m.keys[idx])
+		existing, seen := best[c.MemberID]
+		if !seen {
+			order = append(order, c.MemberID)
+		}
+		if !seen || confidence > existing.Confidence {
+			best[c.MemberID] = Match{MemberID: c.MemberID, FamilyName: c.FamilyName, Confidence: confidence}
+		}
+	}
+
+	matches := make([]Match, 0, len(order))
+	for _, memberID := range order {
+		matches = append(matches, best[memberID])
+	}
+
+	// Stable insertion sort keeps roster order for ties
+	for i := 1; i < len(matches); i++ {
+		for j := i; j > 0 && matches[j].Confidence > matches[j-1].Confidence; j-- {
+			matches[j], matches[j-1] = matches[j-1], matches[j]
+		}
+	}
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
+
+// Ambiguous reports whether the best two matches of a Rank result score the same,
+// so the name cannot be told apart between two members
+func Ambiguous(matches []Match) bool {
+	return len(matches) > 1 && matches[0].Confidence == matches[1].Confidence
+}
+
+// Similarity returns how alike two names are, from 0.0 to 1.0
+func Similarity(a, b string) float64 {
+	return score(simplify(a), Normalize(a), simplify(b), Normalize(b))
+}
+
+// score rates two names given their exact and folded forms; only exact names score 1.0
+func score(exactA, keyA, exactB, keyB string) float64 {
+	if exactA != "" && exactA == exactB {
+		return 1
+	}
+	return min(similarity(keyA, keyB), FoldedMatchConfidence)
+}
+
+func similarity(a, b string) float64 {
+	ra := []rune(a)
+	rb := []rune(b)
+
+	maxLen := len(ra)
+	if len(rb) > maxLen {
+		maxLen = len(rb)
+	}
+	if maxLen == 0 {
+		return 0
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(maxLen)
+}
+
+// ocrConfusables maps characters OCR commonly mistakes for one another to a single form
+var ocrConfusables = map[rune]rune{
+	'0': 'o',
+	'1': 'l',
+	'i': 'l',
+	'|': 'l',
+	'5': 's',
+	'8': 'b',
+}
+
+// Normalize lowercases a name, drops whitespace and folds OCR-confusable characters
+func Normalize(name string) string {
+	var b strings.Builder
+	for _, r := range simplify(name) {
+		if folded, ok := ocrConfusables[r]; ok {
+			r = folded
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// simplify lowercases a name and drops whitespace
+func simplify(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if !unicode.IsSpace(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// levenshtein returns the edit distance between two rune slices
+func levenshtein(a, b []rune) int {
+	if len(a) == 0 {
+		return len(b)
+	}
+	if len(b) == 0 {
+		return len(a)
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,218 @@
+package namematch
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected float64
+	}{
+		{
+			name:     "identical",
+			a:        "hammity",
+			b:        "hammity",
+			expected: 1.0,
+		},
+		{
+			name:     "case insensitive",
+			a:        "Hammity",
+			b:        "hammity",
+			expected: 1.0,
+		},
+		{
+			name:     "ignores spaces",
+			a:        "ham mity",
+			b:        "hammity",
+			expected: 1.0,
+		},
+		{
+			name:     "ocr confusables",
+			a:        "B0bbl3",
+			b:        "bobbl3",
+			expected: FoldedMatchConfidence,
+		},
+		{
+			name:     "one insertion",
+			a:        "hammiity",
+			b:        "hammity",
+			expected: 0.875,
+		},
+		{
+			name:     "completely different",
+			a:        "abc",
+			b:        "xyz",
+			expected: 0.0,
+		},
+		{
+			name:     "empty",
+			a:        "",
+			b:        "",
+			expected: 0.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Similarity(tt.a, tt.b)
+			if math.Abs(result-tt.expected) > 0.0001 {
+				t.Errorf("Similarity(%q, %q) = %v, expected %v", tt.a, tt.b, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"größe", "grösse", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			result := levenshtein([]rune(tt.a), []rune(tt.b))
+			if result != tt.expected {
+				t.Errorf("levenshtein(%q, %q) = %d, expected %d", tt.a, tt.b, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatcherBest(t *testing.T) {
+	matcher := New([]Candidate{
+		{MemberID: 1, FamilyName: "Hammity", Name: "Hammity"},
+		{MemberID: 2, FamilyName: "Kethrya", Name: "Kethrya"},
+		{MemberID: 2, FamilyName: "Kethrya", Name: "Kethyra"}, // alias from an earlier import
+		{MemberID: 3, FamilyName: "Bob", Name: "Bob"},
+	})
+
+	tests := []struct {
+		name           string
+		input          string
+		expectedMember int64
+		minConfidence  float64
+	}{
+		{
+			name:           "exact match",
+			input:          "Hammity",
+			expectedMember: 1,
+			minConfidence:  1.0,
+		},
+		{
+			name:           "ocr misread",
+			input:          "hammiity",
+			expectedMember: 1,
+			minConfidence:  0.85,
+		},
+		{
+			name:           "known alias",
+			input:          "kethyra",
+			expectedMember: 2,
+			minConfidence:  1.0,
+		},
+		{
+			name:           "reports family name for alias",
+			input:          "Kethyra",
+			expectedMember: 2,
+			minConfidence:  1.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, ok := matcher.Best(tt.input)
+			if !ok {
+				t.Fatalf("Best(%q) found no match", tt.input)
+			}
+			if match.MemberID != tt.expectedMember {
+				t.Errorf("Best(%q) member = %d, expected %d", tt.input, match.MemberID, tt.expectedMember)
+			}
+			if match.Confidence < tt.minConfidence {
+				t.Errorf("Best(%q) confidence = %v, expected at least %v", tt.input, match.Confidence, tt.minConfidence)
+			}
+			if match.MemberID == 2 && match.FamilyName != "Kethrya" {
+				t.Errorf("Best(%q) family name = %q, expected %q", tt.input, match.FamilyName, "Kethrya")
+			}
+		})
+	}
+}
+
+func TestMatcherEmpty(t *testing.T) {
+	matcher := New(nil)
+	if _, ok := matcher.Best("anyone"); ok {
+		t.Error("Best on an empty matcher should find no match")
+	}
+
+	matcher.Add(Candidate{MemberID: 7, FamilyName: "Newcomer"})
+	match, ok := matcher.Best("newcomer")
+	if !ok || match.MemberID != 7 || match.Confidence != 1.0 {
+		t.Errorf("Best after Add = %+v, %v; expected member 7 with confidence 1.0", match, ok)
+	}
+}
+
+func TestMatcherRank(t *testing.T) {
+	matcher := New([]Candidate{
+		{MemberID: 1, FamilyName: "Alpha", Name: "Alpha"},
+		{MemberID: 2, FamilyName: "Alphy", Name: "Alphy"},
+		{MemberID: 2, FamilyName: "Alphy", Name: "Alpho"},
+		{MemberID: 3, FamilyName: "Zulu", Name: "Zulu"},
+	})
+
+	matches := matcher.Rank("Alpha", 2)
+	if len(matches) != 2 {
+		t.Fatalf("Rank returned %d matches, expected 2", len(matches))
+	}
+	if matches[0].MemberID != 1 || matches[1].MemberID != 2 {
+		t.Errorf("Rank order = [%d %d], expected [1 2]", matches[0].MemberID, matches[1].MemberID)
+	}
+
+	all := matcher.Rank("Alpha", 0)
+	if len(all) != 3 {
+		t.Errorf("Rank with no limit returned %d matches, expected one per member (3)", len(all))
+	}
+}
+
+func TestMatcherRankPrefersExactOverFolded(t *testing.T) {
+	matcher := New([]Candidate{
+		{MemberID: 1, FamilyName: "Kaii"},
+		{MemberID: 2, FamilyName: "Kail"},
+		{MemberID: 3, FamilyName: "Bo0m"},
+		{MemberID: 4, FamilyName: "Boom"},
+	})
+
+	tests := []struct {
+		name           string
+		input          string
+		expectedMember int64
+		ambiguous      bool
+	}{
+		{name: "exact read of the later member", input: "Kail", expectedMember: 2},
+		{name: "exact read of the earlier member", input: "Kaii", expectedMember: 1},
+		{name: "exact read with a digit", input: "boom", expectedMember: 4},
+		{name: "folded read matching both", input: "Kai1", expectedMember: 1, ambiguous: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := matcher.Rank(tt.input, 2)
+			if matches[0].MemberID != tt.expectedMember {
+				t.Errorf("Rank(%q) best member = %d, expected %d", tt.input, matches[0].MemberID, tt.expectedMember)
+			}
+			if Ambiguous(matches) != tt.ambiguous {
+				t.Errorf("Ambiguous(Rank(%q)) = %v, expected %v (%+v)", tt.input, !tt.ambiguous, tt.ambiguous, matches)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+
+	"PanickedBot/internal/db"
+)
+
+// StatCap holds the stat limits of a war tier; nil limits are not enforced
+type StatCap struct {
+	Tier   string // "1", "2" or "uncapped"
+	MaxAP  *int
+	MaxAAP *int
+	MaxDP  *int
+	MinGS  *int
+}
+
+// GearScore calculates GS as (AP + AAP) / 2 + DP, counting missing stats as 0
+func GearScore(ap, aap, dp *int) int {
+	value := func(v *int) int {
+		if v == nil {
+			return 0
+		}
+		return *v
+	}
+	return (value(ap)+value(aap))/2 + value(dp)
+}
+
+// Violations lists the stats that are over or under the caps, e.g. "AP 312 > 310".
+// A member without any gear stats never meets the caps.
+func (c StatCap) Violations(ap, aap, dp *int) []string {
+	if ap == nil && aap == nil && dp == nil {
+		return []string{"no gear"}
+	}
+
+	var violations []string
+	over := func(label string, stat, limit *int) {
+		if stat != nil && limit != nil && *stat > *limit {
+			violations = append(violations, fmt.Sprintf("%s %d > %d", label, *stat, *limit))
+		}
+	}
+	over("AP", ap, c.MaxAP)
+	over("AAP", aap, c.MaxAAP)
+	over("DP", dp, c.MaxDP)
+
+	if c.MinGS != nil {
+		if gs := GearScore(ap, aap, dp); gs < *c.MinGS {
+			violations = append(violations, fmt.Sprintf("GS %d < %d", gs, *c.MinGS))
+		}
+	}
+
+	return violations
+}
+
+// Meets returns true if the stats are within the caps
+func (c StatCap) Meets(ap, aap, dp *int) bool {
+	return len(c.Violations(ap, aap, dp)) == 0
+}
+
+// GetStatCaps retrieves the stat caps of every war tier of a guild
+func GetStatCaps(database *db.DB, guildID string) ([]StatCap, error) {
+	rows, err := db.GetStatCaps(database, guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	caps := make([]StatCap, len(rows))
+	for i, row := range rows {
+		caps[i] = StatCap{
+			Tier:   row.Tier,
+			MaxAP:  intPtrFromNull(row.MaxAP),
+			MaxAAP: intPtrFromNull(row.MaxAAP),
+			MaxDP:  intPtrFromNull(row.MaxDP),
+			MinGS:  intPtrFromNull(row.MinGS),
+		}
+	}
+	return caps, nil
+}
+
+// GetCapTierStatCap retrieves the stat caps that decide meets_cap
+// Returns nil when meets_cap is set by hand: no cap tier, or no caps defined for it
+func GetCapTierStatCap(database *db.DB, guildID, capTier string) (*StatCap, error) {
+	if capTier == "" {
+		return nil, nil
+	}
+
+	caps, err := GetStatCaps(database, guildID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range caps {
+		if caps[i].Tier == capTier {
+			return &caps[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// RefreshMeetsCap re-evaluates meets_cap of every roster member without an officer override,
+// including mercenaries and inactive members so they are up to date when they return
+// Returns the number of members whose meets_cap changed
+func RefreshMeetsCap(database *db.DB, guildID, capTier string) (int, error) {
+	statCap, err := GetCapTierStatCap(database, guildID, capTier)
+	if err != nil || statCap == nil {
+		return 0, err
+	}
+
+	members, err := GetRosterMembers(database, guildID, RosterFilter{IncludeMercs: true, IncludeInactive: true})
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, m := range members {
+		if m.CapOverride != nil {
+			continue
+		}
+		meets := statCap.Meets(m.AP, m.AAP, m.DP)
+		if meets == m.MeetsCap {
+			continue
+		}
+		if err := db.UpdateMember(database, m.ID, UpdateFields{MeetsCap: &meets}); err != nil {
+			return changed, fmt.Errorf("failed to update meets_cap of %s: %w", m.FamilyName, err)
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+// RefreshMemberMeetsCap re-evaluates meets_cap of a member unless an officer overrode it
+func RefreshMemberMeetsCap(database *db.DB, memberID int64) error {
+	status, err := db.GetMemberCapStatus(database, memberID)
+	if err != nil {
+		return fmt.Errorf("failed to get member cap status: %w", err)
+	}
+	if status.CapOverride.Valid {
+		return nil
+	}
+
+	cfg, err := LoadGuildConfig(database, status.DiscordGuildID)
+	if err != nil {
+		return fmt.Errorf("failed to load guild config: %w", err)
+	}
+
+	statCap, err := GetCapTierStatCap(database, status.DiscordGuildID, cfg.CapTier)
+	if err != nil || statCap == nil {
+		return err
+	}
+
+	meets := statCap.Meets(intPtrFromNull(status.AP), intPtrFromNull(status.AAP), intPtrFromNull(status.DP))
+	if meets == status.MeetsCap {
+		return nil
+	}
+	return db.UpdateMember(database, memberID, UpdateFields{MeetsCap: &meets})
+}
+
+// SetMeetsCapOverride fixes a member's meets_cap to the given value
+// A nil override computes meets_cap from the stat caps again
+func SetMeetsCapOverride(database *db.DB, memberID int64, override *bool) error {
+	if err := db.SetMeetsCapOverride(database, memberID, override); err != nil {
+		return err
+	}
+	if override == nil {
+		return RefreshMemberMeetsCap(database, memberID)
+	}
+	return nil
+}
+
+// intPtrFromNull converts a nullable stat column to an optional stat
+func intPtrFromNull(v sql.NullInt32) *int {
+	if !v.Valid {
+		return nil
+	}
+	value := int(v.Int32)
+	return &value
+}
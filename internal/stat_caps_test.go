@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func statPtr(v int) *int {
+	return &v
+}
+
+func TestGearScore(t *testing.T) {
+	if gs := GearScore(statPtr(310), statPtr(312), statPtr(420)); gs != 731 {
+		t.Errorf("GearScore() = %d, want 731", gs)
+	}
+	if gs := GearScore(nil, statPtr(300), nil); gs != 150 {
+		t.Errorf("GearScore() with missing stats = %d, want 150", gs)
+	}
+}
+
+func TestStatCapViolations(t *testing.T) {
+	statCap := StatCap{Tier: "1", MaxAP: statPtr(310), MaxDP: statPtr(420), MinGS: statPtr(720)}
+
+	tests := []struct {
+		name        string
+		ap, aap, dp *int
+		expected    []string
+	}{
+		{name: "within caps", ap: statPtr(310), aap: statPtr(312), dp: statPtr(420), expected: nil},
+		{name: "over AP", ap: statPtr(315), aap: statPtr(312), dp: statPtr(420), expected: []string{"AP 315 > 310"}},
+		{name: "under GS", ap: statPtr(290), aap: statPtr(290), dp: statPtr(400), expected: []string{"GS 690 < 720"}},
+		{name: "over DP and under GS", ap: statPtr(250), aap: statPtr(250), dp: statPtr(430), expected: []string{"DP 430 > 420", "GS 680 < 720"}},
+		{name: "no gear", expected: []string{"no gear"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := statCap.Violations(tt.ap, tt.aap, tt.dp)
+			if !reflect.DeepEqual(violations, tt.expected) {
+				t.Errorf("Violations() = %v, want %v", violations, tt.expected)
+			}
+			if meets := statCap.Meets(tt.ap, tt.aap, tt.dp); meets != (len(tt.expected) == 0) {
+				t.Errorf("Meets() = %v, want %v", meets, len(tt.expected) == 0)
+			}
+		})
+	}
+}
+
+func TestStatCapViolationsWithoutLimits(t *testing.T) {
+	statCap := StatCap{Tier: "uncapped"}
+	if violations := statCap.Violations(statPtr(400), nil, nil); len(violations) != 0 {
+		t.Errorf("expected no violations without limits, got %v", violations)
+	}
+}
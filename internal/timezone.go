@@ -1,15 +1,44 @@
 package internal
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
-// GetEasternLocation returns the Eastern timezone location (America/New_York)
-// This handles both EST (Eastern Standard Time) and EDT (Eastern Daylight Time) automatically
-func GetEasternLocation() *time.Location {
-	loc, err := time.LoadLocation("America/New_York")
+// DefaultTimezone is the timezone used by guilds that have not configured one
+const DefaultTimezone = "America/New_York"
+
+// LoadTimezone validates an IANA timezone name (e.g. "Europe/Berlin") and loads its location.
+// The empty name and "Local" are rejected since they depend on the host running the bot.
+func LoadTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, "Local") {
+		return nil, fmt.Errorf("timezone must be an IANA name such as %s", DefaultTimezone)
+	}
+
+	loc, err := time.LoadLocation(name)
 	if err != nil {
-		// Fallback to UTC if Eastern timezone is not available
-		// This should not happen in production but provides a safe fallback
-		return time.UTC
+		return nil, fmt.Errorf("unknown timezone '%s'", name)
 	}
-	return loc
+	return loc, nil
+}
+
+// GetLocation returns the location of a guild's timezone.
+// This handles daylight saving time automatically; an invalid name falls back to
+// DefaultTimezone, and to UTC if the timezone database is not available.
+func GetLocation(name string) *time.Location {
+	if loc, err := LoadTimezone(name); err == nil {
+		return loc
+	}
+	if loc, err := time.LoadLocation(DefaultTimezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// CalendarDate returns midnight in loc of the calendar day of t.
+// DATE columns are read back as midnight UTC; this places them in the guild's timezone.
+func CalendarDate(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
 }
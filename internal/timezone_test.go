@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadTimezone(t *testing.T) {
+	mustLoadLocation(t, DefaultTimezone)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		valid    bool
+	}{
+		{"default", "America/New_York", "America/New_York", true},
+		{"europe", "Europe/Berlin", "Europe/Berlin", true},
+		{"surrounding spaces", " Europe/Paris ", "Europe/Paris", true},
+		{"utc", "UTC", "UTC", true},
+		{"empty", "", "", false},
+		{"local", "Local", "", false},
+		{"unknown", "Mars/Olympus_Mons", "", false},
+		{"abbreviation", "CEST", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := LoadTimezone(tt.input)
+			if (err == nil) != tt.valid {
+				t.Fatalf("LoadTimezone(%q) error = %v, valid %v", tt.input, err, tt.valid)
+			}
+			if tt.valid && loc.String() != tt.expected {
+				t.Errorf("LoadTimezone(%q) = %s, want %s", tt.input, loc, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetLocation(t *testing.T) {
+	mustLoadLocation(t, DefaultTimezone)
+
+	if loc := GetLocation("Europe/Berlin"); loc.String() != "Europe/Berlin" {
+		t.Errorf("GetLocation(Europe/Berlin) = %s", loc)
+	}
+	if loc := GetLocation(""); loc.String() != DefaultTimezone {
+		t.Errorf("GetLocation(\"\") = %s, want %s", loc, DefaultTimezone)
+	}
+	if loc := GetLocation("Not/A_Zone"); loc.String() != DefaultTimezone {
+		t.Errorf("GetLocation(Not/A_Zone) = %s, want %s", loc, DefaultTimezone)
+	}
+}
+
+func TestCalendarDate(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+
+	// A war date read from a DATE column is midnight UTC
+	stored := time.Date(2026, 3, 29, 0, 0, 0, 0, time.UTC)
+	got := CalendarDate(stored, berlin)
+	want := time.Date(2026, 3, 29, 0, 0, 0, 0, berlin)
+	if !got.Equal(want) {
+		t.Errorf("CalendarDate(%v) = %v, want %v", stored, got, want)
+	}
+	if got.Format("02-01-06") != "29-03-26" {
+		t.Errorf("CalendarDate(%v) formats as %s, want 29-03-26", stored, got.Format("02-01-06"))
+	}
+}
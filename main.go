@@ -10,12 +10,14 @@ import (
 	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // embedded timezone database for hosts without one
 
 	"github.com/bwmarrin/discordgo"
 
 	"PanickedBot/internal"
 	"PanickedBot/internal/commands"
 	"PanickedBot/internal/db"
+	"PanickedBot/internal/extract"
 )
 
 // deregisterAllCommands removes all globally registered application commands
@@ -106,7 +108,17 @@ func main() {
 
 	cmds := commands.GetCommands()
 
-	dg.AddHandler(commands.CreateInteractionHandler(database))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Start the background war import worker; it also resumes jobs interrupted by a restart
+	warJobs := commands.NewWarJobQueue(dg, database, extract.Credentials{
+		OpenAIAPIKey:     cfg.OpenAIAPIKey,
+		CompatibleAPIKey: cfg.OCRAPIKey,
+	})
+	warJobs.Start(ctx)
+
+	dg.AddHandler(commands.CreateInteractionHandler(database, warJobs))
 
 	registered := make([]*discordgo.ApplicationCommand, 0, len(cmds))
 	for _, cmd := range cmds {
@@ -124,8 +136,6 @@ func main() {
 
 	log.Printf("bot ready (app=%s)", appID)
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
 	<-ctx.Done()
 
 	_ = registered
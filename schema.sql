@@ -24,6 +24,16 @@ CREATE TABLE IF NOT EXISTS config (
   mercenary_role_id     VARCHAR(32) NULL COMMENT 'Role for mercenary members',
   command_channel_id    VARCHAR(32) NULL COMMENT 'Channel where commands and results are posted',
   timezone              VARCHAR(64) NOT NULL DEFAULT 'America/New_York',
+  match_threshold       DECIMAL(3,2) NOT NULL DEFAULT 0.80 COMMENT 'Minimum name match confidence for linking imported war lines to roster members',
+  ocr_backend           ENUM('openai','openai_compatible') NOT NULL DEFAULT 'openai' COMMENT 'Backend used to extract war data from screenshots',
+  ocr_model             VARCHAR(128) NULL COMMENT 'Vision model name, backend default when NULL',
+  ocr_base_url          VARCHAR(255) NULL COMMENT 'API base URL for the openai_compatible backend',
+  attendance_week_start SMALLINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'First day of attendance weeks, 0 = Sunday',
+  attendance_war_days   SMALLINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Bitmask of scheduled war days, bit 0 = Sunday; 0 counts every day a war was fought',
+  attendance_min_wars   SMALLINT UNSIGNED NOT NULL DEFAULT 1 COMMENT 'War nights required per week',
+  attendance_min_percent SMALLINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Percentage of war nights required per week, overrides attendance_min_wars when set',
+  vacation_approval     TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Whether vacations requested by members need officer approval',
+  cap_tier              ENUM('1','2','uncapped') NULL COMMENT 'War tier whose stat caps decide meets_cap, NULL to set meets_cap by hand',
   updated_at            DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
   PRIMARY KEY (discord_guild_id),
   CONSTRAINT fk_config_guild
@@ -31,6 +41,21 @@ CREATE TABLE IF NOT EXISTS config (
     ON DELETE CASCADE ON UPDATE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
+CREATE TABLE IF NOT EXISTS stat_caps (
+  discord_guild_id   VARCHAR(32) NOT NULL,
+  tier               ENUM('1','2','uncapped') NOT NULL COMMENT 'War tier the caps apply to',
+  max_ap             INT UNSIGNED NULL COMMENT 'Highest AP allowed, NULL for no limit',
+  max_aap            INT UNSIGNED NULL COMMENT 'Highest AAP allowed, NULL for no limit',
+  max_dp             INT UNSIGNED NULL COMMENT 'Highest DP allowed, NULL for no limit',
+  min_gs             INT UNSIGNED NULL COMMENT 'Lowest gear score required, NULL for no minimum',
+  updated_by_user_id VARCHAR(32) NOT NULL,
+  updated_at         DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (discord_guild_id, tier),
+  CONSTRAINT fk_stat_caps_guild
+    FOREIGN KEY (discord_guild_id) REFERENCES guilds(discord_guild_id)
+    ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
 -- ============================================================================
 -- Teams
 -- ============================================================================
@@ -75,9 +100,11 @@ CREATE TABLE IF NOT EXISTS roster_members (
   hp                INT UNSIGNED NULL COMMENT 'HP stat',
   total_ap          INT UNSIGNED NULL COMMENT 'Total Attack Power',
   total_aap         INT UNSIGNED NULL COMMENT 'Total Awakening Attack Power',
+  gear_verified_at  DATETIME(6) NULL COMMENT 'When AP/AAP/DP were last confirmed from a stat window screenshot, NULL when entered by hand',
   
   -- Status flags
-  meets_cap         TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Officer verified member meets required stat caps',
+  meets_cap         TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Member meets the stat caps of the guild cap tier, computed from stat_caps unless overridden',
+  meets_cap_override TINYINT(1) NULL COMMENT 'Officer override of meets_cap, NULL when computed from stat caps',
   is_exception      TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Member K/D stats excluded from guild overall K/D calculations',
   is_mercenary      TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Member is a mercenary and excluded from roster',
   is_active         TINYINT(1) NOT NULL DEFAULT 1,
@@ -93,6 +120,73 @@ CREATE TABLE IF NOT EXISTS roster_members (
     ON DELETE CASCADE ON UPDATE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
+CREATE TABLE IF NOT EXISTS member_aliases (
+  id                 BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  discord_guild_id   VARCHAR(32) NOT NULL,
+  roster_member_id   BIGINT UNSIGNED NOT NULL,
+  alias              VARCHAR(128) NOT NULL COMMENT 'Old family name, common OCR misread or nickname',
+  created_by_user_id VARCHAR(32) NULL COMMENT 'Officer who added the alias, NULL when kept automatically on rename',
+  created_at         DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_alias_guild_alias (discord_guild_id, alias),
+  KEY idx_alias_member (roster_member_id),
+  CONSTRAINT fk_alias_guild
+    FOREIGN KEY (discord_guild_id) REFERENCES guilds(discord_guild_id)
+    ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT fk_alias_member
+    FOREIGN KEY (roster_member_id) REFERENCES roster_members(id)
+    ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS gear_history (
+  id                 BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  discord_guild_id   VARCHAR(32) NOT NULL,
+  roster_member_id   BIGINT UNSIGNED NOT NULL,
+  ap                 INT UNSIGNED NULL COMMENT 'Attack Power after the update',
+  aap                INT UNSIGNED NULL COMMENT 'Awakening Attack Power after the update',
+  dp                 INT UNSIGNED NULL COMMENT 'Defense Power after the update',
+  updated_by_user_id VARCHAR(32) NOT NULL COMMENT 'Discord user who made the change',
+  created_at         DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  KEY idx_gear_history_member (roster_member_id, created_at),
+  KEY idx_gear_history_guild (discord_guild_id, created_at),
+  CONSTRAINT fk_gear_history_guild
+    FOREIGN KEY (discord_guild_id) REFERENCES guilds(discord_guild_id)
+    ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT fk_gear_history_member
+    FOREIGN KEY (roster_member_id) REFERENCES roster_members(id)
+    ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS gear_screenshots (
+  id                   BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  discord_guild_id     VARCHAR(32) NOT NULL,
+  roster_member_id     BIGINT UNSIGNED NOT NULL,
+  submitted_by_user_id VARCHAR(32) NOT NULL COMMENT 'Discord user who uploaded the screenshot',
+  image_path           VARCHAR(255) NOT NULL COMMENT 'Saved copy of the stat window screenshot',
+  ap                   INT UNSIGNED NOT NULL COMMENT 'Stats read from the screenshot',
+  aap                  INT UNSIGNED NOT NULL,
+  dp                   INT UNSIGNED NOT NULL,
+  evasion              INT UNSIGNED NULL,
+  dr                   INT UNSIGNED NULL,
+  drr                  DECIMAL(5,2) NULL,
+  accuracy             INT UNSIGNED NULL,
+  hp                   INT UNSIGNED NULL,
+  total_ap             INT UNSIGNED NULL,
+  total_aap            INT UNSIGNED NULL,
+  status               ENUM('pending','confirmed','canceled') NOT NULL DEFAULT 'pending',
+  created_at           DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  resolved_at          DATETIME(6) NULL,
+  PRIMARY KEY (id),
+  KEY idx_gear_screenshots_member (roster_member_id, created_at),
+  CONSTRAINT fk_gear_screenshots_guild
+    FOREIGN KEY (discord_guild_id) REFERENCES guilds(discord_guild_id)
+    ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT fk_gear_screenshots_member
+    FOREIGN KEY (roster_member_id) REFERENCES roster_members(id)
+    ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
 -- ============================================================================
 -- War Processing
 -- ============================================================================
@@ -103,8 +197,14 @@ CREATE TABLE IF NOT EXISTS war_jobs (
   request_channel_id   VARCHAR(32) NOT NULL,
   request_message_id   VARCHAR(32) NOT NULL,
   requested_by_user_id VARCHAR(32) NOT NULL,
-  status               ENUM('queued','processing','done','canceled','error') NOT NULL DEFAULT 'queued',
+  result               ENUM('win','lose') NULL COMMENT 'Requested war result, copied to the war on import',
+  war_type             ENUM('node','siege') NULL COMMENT 'Requested war type, copied to the war on import',
+  tier                 ENUM('1','2','uncapped') NULL COMMENT 'Requested war tier, copied to the war on import',
+  label                VARCHAR(255) NULL COMMENT 'Requested war label, copied to the war on import',
+  status               ENUM('queued','processing','review','done','canceled','error') NOT NULL DEFAULT 'queued',
   error                TEXT NULL,
+  war_date             DATE NULL COMMENT 'War date parsed from the attachments, set when the import is held for review',
+  review_message_id    VARCHAR(32) NULL COMMENT 'Message holding the review controls of a held import',
   created_at           DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
   started_at           DATETIME(6) NULL,
   finished_at          DATETIME(6) NULL,
@@ -133,12 +233,34 @@ CREATE TABLE IF NOT EXISTS war_job_attachments (
     ON DELETE CASCADE ON UPDATE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
+CREATE TABLE IF NOT EXISTS war_job_lines (
+  id               BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  job_id           BIGINT UNSIGNED NOT NULL,
+  idx              INT NOT NULL,
+  ocr_name         VARCHAR(255) NOT NULL,
+  kills            INT NOT NULL,
+  deaths           INT NOT NULL,
+  action           ENUM('pending','link','create','drop') NOT NULL DEFAULT 'pending' COMMENT 'How the line is imported once the review is confirmed',
+  roster_member_id BIGINT UNSIGNED NULL COMMENT 'Member to link, or the suggested member while pending',
+  matched_name     VARCHAR(128) NULL,
+  match_confidence DECIMAL(5,4) NULL,
+  conflict         TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Overlapping screenshots disagree on kills or deaths for this member',
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_job_line_order (job_id, idx),
+  CONSTRAINT fk_job_lines_job
+    FOREIGN KEY (job_id) REFERENCES war_jobs(id)
+    ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT fk_job_lines_member
+    FOREIGN KEY (roster_member_id) REFERENCES roster_members(id)
+    ON DELETE SET NULL ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
 CREATE TABLE IF NOT EXISTS wars (
   id               BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
   discord_guild_id VARCHAR(32) NOT NULL,
   job_id           BIGINT UNSIGNED NOT NULL,
   war_date         DATE NOT NULL,
-  label            VARCHAR(255) NULL,
+  label            VARCHAR(255) NULL COMMENT 'Officer-facing name, e.g. the node, telling apart wars on the same date',
   result           ENUM('win','lose') NULL COMMENT 'War result: win or lose',
   war_type         ENUM('node','siege') NULL COMMENT 'Type of war: node war or siege',
   tier             ENUM('1','2','uncapped') NULL COMMENT 'War tier: 1, 2, or uncapped',
@@ -165,8 +287,8 @@ CREATE TABLE IF NOT EXISTS war_lines (
   deaths           INT NOT NULL,
   matched_name     VARCHAR(128) NULL,
   match_confidence DECIMAL(5,4) NULL,
-  class            VARCHAR(64) NULL COMMENT 'BDO class name',
-  spec             VARCHAR(32) NULL COMMENT 'Class specialization',
+  class            VARCHAR(64) NULL COMMENT 'Class of the linked member at the time of the war',
+  spec             VARCHAR(32) NULL COMMENT 'Specialization of the linked member at the time of the war',
   created_at       DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
   PRIMARY KEY (id),
   KEY idx_lines_war (war_id),
@@ -187,7 +309,10 @@ CREATE TABLE IF NOT EXISTS member_exceptions (
   start_date         DATE NOT NULL,
   end_date           DATE NOT NULL,
   reason             VARCHAR(255) NULL,
+  scope              ENUM('all','attendance','stats') NOT NULL DEFAULT 'all' COMMENT 'What an exclude exception applies to, ignored for vacations',
+  status             ENUM('approved','pending') NOT NULL DEFAULT 'approved' COMMENT 'Pending vacations do not count toward attendance until an officer approves them',
   created_by_user_id VARCHAR(32) NOT NULL,
+  approved_by_user_id VARCHAR(32) NULL,
   created_at         DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
   PRIMARY KEY (id),
   KEY idx_exceptions_member_dates (roster_member_id, start_date, end_date),
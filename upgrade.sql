@@ -0,0 +1,59 @@
+-- PanickedBot Database Upgrade
+-- Brings a database created from an older schema.sql up to date.
+-- Run schema.sql again first to create the tables added since (every CREATE TABLE is IF NOT EXISTS),
+-- then run this file once. Each change is a separate statement: statements for columns that already exist
+-- fail with a duplicate column error, so run with `mysql --force` to skip them when some were applied before.
+-- Changes are listed oldest first.
+
+SET NAMES utf8mb4;
+
+-- War import options requested with /addwar
+ALTER TABLE war_jobs ADD COLUMN result ENUM('win','lose') NULL COMMENT 'Requested war result, copied to the war on import' AFTER requested_by_user_id;
+ALTER TABLE war_jobs ADD COLUMN war_type ENUM('node','siege') NULL COMMENT 'Requested war type, copied to the war on import' AFTER result;
+ALTER TABLE war_jobs ADD COLUMN tier ENUM('1','2','uncapped') NULL COMMENT 'Requested war tier, copied to the war on import' AFTER war_type;
+
+-- Name match threshold
+ALTER TABLE config ADD COLUMN match_threshold DECIMAL(3,2) NOT NULL DEFAULT 0.80 COMMENT 'Minimum name match confidence for linking imported war lines to roster members' AFTER timezone;
+
+-- War imports held for review
+ALTER TABLE war_jobs MODIFY COLUMN status ENUM('queued','processing','review','done','canceled','error') NOT NULL DEFAULT 'queued';
+ALTER TABLE war_jobs ADD COLUMN war_date DATE NULL COMMENT 'War date parsed from the attachments, set when the import is held for review' AFTER error;
+ALTER TABLE war_jobs ADD COLUMN review_message_id VARCHAR(32) NULL COMMENT 'Message holding the review controls of a held import' AFTER war_date;
+
+-- Screenshot extraction backend
+ALTER TABLE config ADD COLUMN ocr_backend ENUM('openai','openai_compatible') NOT NULL DEFAULT 'openai' COMMENT 'Backend used to extract war data from screenshots' AFTER match_threshold;
+ALTER TABLE config ADD COLUMN ocr_model VARCHAR(128) NULL COMMENT 'Vision model name, backend default when NULL' AFTER ocr_backend;
+ALTER TABLE config ADD COLUMN ocr_base_url VARCHAR(255) NULL COMMENT 'API base URL for the openai_compatible backend' AFTER ocr_model;
+
+-- Conflicting lines from overlapping screenshots
+ALTER TABLE war_job_lines ADD COLUMN conflict TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Overlapping screenshots disagree on kills or deaths for this member' AFTER match_confidence;
+
+-- War labels
+ALTER TABLE war_jobs ADD COLUMN label VARCHAR(255) NULL COMMENT 'Requested war label, copied to the war on import' AFTER tier;
+ALTER TABLE wars MODIFY COLUMN label VARCHAR(255) NULL COMMENT 'Officer-facing name, e.g. the node, telling apart wars on the same date';
+
+-- Attendance policy
+ALTER TABLE config ADD COLUMN attendance_week_start SMALLINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'First day of attendance weeks, 0 = Sunday' AFTER ocr_base_url;
+ALTER TABLE config ADD COLUMN attendance_war_days SMALLINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Bitmask of scheduled war days, bit 0 = Sunday; 0 counts every day a war was fought' AFTER attendance_week_start;
+ALTER TABLE config ADD COLUMN attendance_min_wars SMALLINT UNSIGNED NOT NULL DEFAULT 1 COMMENT 'War nights required per week' AFTER attendance_war_days;
+ALTER TABLE config ADD COLUMN attendance_min_percent SMALLINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Percentage of war nights required per week, overrides attendance_min_wars when set' AFTER attendance_min_wars;
+
+-- Vacation approval
+ALTER TABLE config ADD COLUMN vacation_approval TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Whether vacations requested by members need officer approval' AFTER attendance_min_percent;
+ALTER TABLE member_exceptions ADD COLUMN status ENUM('approved','pending') NOT NULL DEFAULT 'approved' COMMENT 'Pending vacations do not count toward attendance until an officer approves them' AFTER reason;
+ALTER TABLE member_exceptions ADD COLUMN approved_by_user_id VARCHAR(32) NULL AFTER created_by_user_id;
+
+-- Exclusion scope
+ALTER TABLE member_exceptions ADD COLUMN scope ENUM('all','attendance','stats') NOT NULL DEFAULT 'all' COMMENT 'What an exclude exception applies to, ignored for vacations' AFTER reason;
+
+-- Stat caps
+ALTER TABLE config ADD COLUMN cap_tier ENUM('1','2','uncapped') NULL COMMENT 'War tier whose stat caps decide meets_cap, NULL to set meets_cap by hand' AFTER vacation_approval;
+ALTER TABLE roster_members MODIFY COLUMN meets_cap TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Member meets the stat caps of the guild cap tier, computed from stat_caps unless overridden';
+ALTER TABLE roster_members ADD COLUMN meets_cap_override TINYINT(1) NULL COMMENT 'Officer override of meets_cap, NULL when computed from stat caps' AFTER meets_cap;
+
+-- Gear verified from stat window screenshots
+ALTER TABLE roster_members ADD COLUMN gear_verified_at DATETIME(6) NULL COMMENT 'When AP/AAP/DP were last confirmed from a stat window screenshot, NULL when entered by hand' AFTER total_aap;
+
+-- Class and spec snapshots on war lines
+ALTER TABLE war_lines MODIFY COLUMN class VARCHAR(64) NULL COMMENT 'Class of the linked member at the time of the war';
+ALTER TABLE war_lines MODIFY COLUMN spec VARCHAR(32) NULL COMMENT 'Specialization of the linked member at the time of the war';